@@ -115,6 +115,7 @@ func (m *Therefore) testSource(ctx context.Context, source *dagger.Directory) (s
 	templSource := m.TemplGenerate(source)
 	return dag.Container().
 		From("golang:1.26-alpine@sha256:c2a1f7b2095d046ae14b286b18413a05bb82c9bca9b25fe7ff5efef0f0826166").
+		WithExec([]string{"apk", "add", "--no-cache", "git"}). // For the git content store tests
 		WithEnvVariable("GOCACHE", "/go-build-cache").
 		WithEnvVariable("GOMODCACHE", "/go-mod-cache").
 		WithMountedCache("/go-build-cache", dag.CacheVolume("go-build-cache")).
@@ -279,7 +280,7 @@ func (m *Therefore) Release(
 
 	return dag.Container().
 		From("alpine:3.23@sha256:25109184c71bdad752c8312a8623239686a9a2071e8825f20acb8f2198c3f659").
-		WithExec([]string{"apk", "add", "--no-cache", "tzdata", "ca-certificates"}).
+		WithExec([]string{"apk", "add", "--no-cache", "tzdata", "ca-certificates", "git"}).
 		WithFile("/usr/local/bin/therefore", binary).
 		WithExec([]string{"sh", "-c", "echo 'nonroot:x:10001:10001:NonRoot User:/:/sbin/nologin' >> /etc/passwd"}).
 		WithEnvVariable("TZ", "America/New_York").
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"therefore/internal/lint"
+	"therefore/internal/views"
+
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check posts for content problems",
+	Long: `Check every post for problems that would otherwise only show up in the
+rendered site: unknown shortcodes, unclosed block shortcodes, missing
+required attributes, unresolved citation aliases, missing bundle assets,
+duplicate sidenote ids and empty summaries.
+
+Problems are printed as file:line:col and the command exits non-zero if
+any are found, so CI can run it before building.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, _ []string) error {
+	afs, err := contentFS()
+	if err != nil {
+		return err
+	}
+
+	issues, err := lint.New(afs, views.ShortcodeRenderers(), views.ShortcodeSpecs()).Run()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, issue := range issues {
+		_, _ = fmt.Fprintln(out, issue)
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(issues))
+	}
+	_, _ = fmt.Fprintln(out, "No problems found.")
+	return nil
+}
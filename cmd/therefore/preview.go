@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"therefore/internal/preview"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var previewLinkCmd = &cobra.Command{
+	Use:   "preview-link <slug>",
+	Short: "Print a signed link for reviewing an unpublished post",
+	Long: `Print a link that lets a reviewer read a draft or scheduled post
+before it is published.
+
+The link carries a token signed with THEREFORE_PREVIEW_SECRET and expires
+after --ttl. The server must run with the same secret for it to work.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPreviewLink,
+}
+
+func init() {
+	previewLinkCmd.Flags().Duration("ttl", preview.DefaultTTL, "How long the link stays valid")
+	rootCmd.AddCommand(previewLinkCmd)
+}
+
+func runPreviewLink(cmd *cobra.Command, args []string) error {
+	signer := preview.NewSigner(viper.GetString("preview_secret"))
+	if signer == nil {
+		return errors.New("preview links need a secret: set THEREFORE_PREVIEW_SECRET")
+	}
+
+	ttl, err := cmd.Flags().GetDuration("ttl")
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("--ttl must be positive, got %s", ttl)
+	}
+
+	store, err := newContentStore()
+	if err != nil {
+		return fmt.Errorf("initializing content store: %w", err)
+	}
+
+	slug := args[0]
+	if _, err := store.GetPreview(cmd.Context(), slug); err != nil {
+		if _, published := store.GetPost(cmd.Context(), slug); published == nil {
+			return fmt.Errorf("post %q is already published", slug)
+		}
+		return fmt.Errorf("no draft or scheduled post %q", slug)
+	}
+
+	link := strings.TrimSuffix(viper.GetString("base_url"), "/") +
+		"/posts/" + url.PathEscape(slug) +
+		"?preview=" + url.QueryEscape(signer.Sign(slug, ttl))
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), link)
+	return nil
+}
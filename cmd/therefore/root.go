@@ -3,7 +3,11 @@ package main
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"time"
+
+	"therefore/internal/logging"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -15,7 +19,10 @@ var rootCmd = &cobra.Command{
 	Use:   "therefore",
 	Short: "A philosophy/theology blog platform",
 	Long:  `Therefore is a blog platform for philosophy and theology content, built with Go + templ backend and React + HeroUI frontend.`,
-	RunE:  runServer,
+	PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		return setupLogging()
+	},
+	RunE: runServer,
 }
 
 func Execute() {
@@ -31,13 +38,41 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./therefore.yaml)")
 	rootCmd.PersistentFlags().String("port", ":8080", "server port")
 	rootCmd.PersistentFlags().String("log-level", "info", "log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().String("log-format", "text", "log format (text, json)")
 	rootCmd.PersistentFlags().Bool("dev", false, "enable development mode (use Vite dev server for assets)")
 	rootCmd.PersistentFlags().String("base-url", "http://localhost:8080", "public base URL for sitemap and SEO")
+	rootCmd.PersistentFlags().Bool("strict-shortcodes", false, "fail to load posts with malformed, unclosed or unknown shortcodes")
+	rootCmd.PersistentFlags().String("content-dir", "", "load posts from this directory instead of the embedded content (watched for changes by the server)")
+	rootCmd.PersistentFlags().String("content-store", "embedded", "where the server keeps loaded posts: embedded (in memory) or sqlite")
+	rootCmd.PersistentFlags().String("content-db", "", "SQLite database file for --content-store sqlite, rebuilt from the posts at startup (default in memory)")
+	rootCmd.PersistentFlags().String("content-git", "", "load posts from this git repository, bare or working, instead of the embedded content")
+	rootCmd.PersistentFlags().String("content-git-dir", "", "directory within the git repository holding the posts (default is the repository root)")
+	rootCmd.PersistentFlags().String("content-ref", "HEAD", "git ref to load posts from (switched live when changed in the config file)")
+	rootCmd.PersistentFlags().Duration("content-poll", time.Minute, "how often the server checks the git ref for new commits (0 disables)")
+	rootCmd.PersistentFlags().Duration("read-timeout", 15*time.Second, "maximum time to read a request, including its body")
+	rootCmd.PersistentFlags().Duration("write-timeout", 30*time.Second, "maximum time to write a response")
+	rootCmd.PersistentFlags().Duration("idle-timeout", 2*time.Minute, "how long to keep idle keep-alive connections open")
+	rootCmd.PersistentFlags().Duration("drain-delay", 5*time.Second, "how long to fail health checks before closing the listener on shutdown")
+	rootCmd.PersistentFlags().Duration("shutdown-timeout", 20*time.Second, "how long to wait for in-flight requests on shutdown")
 
 	_ = viper.BindPFlag("port", rootCmd.PersistentFlags().Lookup("port"))
 	_ = viper.BindPFlag("log_level", rootCmd.PersistentFlags().Lookup("log-level"))
+	_ = viper.BindPFlag("log_format", rootCmd.PersistentFlags().Lookup("log-format"))
 	_ = viper.BindPFlag("dev", rootCmd.PersistentFlags().Lookup("dev"))
 	_ = viper.BindPFlag("base_url", rootCmd.PersistentFlags().Lookup("base-url"))
+	_ = viper.BindPFlag("content_dir", rootCmd.PersistentFlags().Lookup("content-dir"))
+	_ = viper.BindPFlag("content_store", rootCmd.PersistentFlags().Lookup("content-store"))
+	_ = viper.BindPFlag("content_db", rootCmd.PersistentFlags().Lookup("content-db"))
+	_ = viper.BindPFlag("content_git", rootCmd.PersistentFlags().Lookup("content-git"))
+	_ = viper.BindPFlag("content_git_dir", rootCmd.PersistentFlags().Lookup("content-git-dir"))
+	_ = viper.BindPFlag("content_ref", rootCmd.PersistentFlags().Lookup("content-ref"))
+	_ = viper.BindPFlag("content_poll", rootCmd.PersistentFlags().Lookup("content-poll"))
+	_ = viper.BindPFlag("strict_shortcodes", rootCmd.PersistentFlags().Lookup("strict-shortcodes"))
+	_ = viper.BindPFlag("read_timeout", rootCmd.PersistentFlags().Lookup("read-timeout"))
+	_ = viper.BindPFlag("write_timeout", rootCmd.PersistentFlags().Lookup("write-timeout"))
+	_ = viper.BindPFlag("idle_timeout", rootCmd.PersistentFlags().Lookup("idle-timeout"))
+	_ = viper.BindPFlag("drain_delay", rootCmd.PersistentFlags().Lookup("drain-delay"))
+	_ = viper.BindPFlag("shutdown_timeout", rootCmd.PersistentFlags().Lookup("shutdown-timeout"))
 }
 
 func initConfig() {
@@ -55,8 +90,23 @@ func initConfig() {
 	// Set defaults
 	viper.SetDefault("port", ":8080")
 	viper.SetDefault("log_level", "info")
+	viper.SetDefault("log_format", "text")
 	viper.SetDefault("dev", false)
 	viper.SetDefault("base_url", "http://localhost:8080")
+	viper.SetDefault("content_dir", "")
+	viper.SetDefault("content_store", "embedded")
+	viper.SetDefault("content_db", "")
+	viper.SetDefault("content_git", "")
+	viper.SetDefault("content_git_dir", "")
+	viper.SetDefault("content_ref", "HEAD")
+	viper.SetDefault("content_poll", time.Minute)
+	viper.SetDefault("preview_secret", "")
+	viper.SetDefault("strict_shortcodes", false)
+	viper.SetDefault("read_timeout", 15*time.Second)
+	viper.SetDefault("write_timeout", 30*time.Second)
+	viper.SetDefault("idle_timeout", 2*time.Minute)
+	viper.SetDefault("drain_delay", 5*time.Second)
+	viper.SetDefault("shutdown_timeout", 20*time.Second)
 
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
@@ -65,3 +115,14 @@ func initConfig() {
 		}
 	}
 }
+
+// setupLogging makes the logger configured by log_level and log_format the
+// default for every command.
+func setupLogging() error {
+	logger, err := logging.New(os.Stderr, viper.GetString("log_level"), viper.GetString("log_format"))
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "List posts scheduled for future publication",
+	Long: `List posts whose publishDate is in the future, soonest first.
+
+A running server publishes these posts automatically when their publish
+date arrives, without a restart or redeploy.`,
+	RunE: runSchedule,
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+}
+
+func runSchedule(cmd *cobra.Command, _ []string) error {
+	store, err := newContentStore()
+	if err != nil {
+		return fmt.Errorf("initializing content store: %w", err)
+	}
+
+	upcoming := store.Upcoming()
+	out := cmd.OutOrStdout()
+	if len(upcoming) == 0 {
+		_, _ = fmt.Fprintln(out, "No scheduled posts.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PUBLISHES\tIN\tSLUG\tTITLE")
+	now := time.Now()
+	for _, post := range upcoming {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			post.Meta.PublishDate.Local().Format("2006-01-02 15:04 MST"),
+			formatUntil(post.Meta.PublishDate.Sub(now)),
+			post.Meta.Slug,
+			post.Meta.Title,
+		)
+	}
+	return w.Flush()
+}
+
+// formatUntil renders a duration coarsely, e.g. "3d 4h" or "25m".
+func formatUntil(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -1,18 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	embeddedcontent "therefore/content"
+	"therefore/internal/compress"
 	"therefore/internal/content"
+	"therefore/internal/feed"
 	"therefore/internal/handlers"
+	"therefore/internal/health"
+	"therefore/internal/logging"
+	"therefore/internal/metrics"
+	"therefore/internal/preview"
 	"therefore/internal/renderer"
 	"therefore/internal/static"
 	"therefore/internal/views"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/labstack/echo/v5"
 	"github.com/labstack/echo/v5/middleware"
 	"github.com/spf13/afero"
@@ -30,52 +44,146 @@ func runServer(_ *cobra.Command, _ []string) error {
 		slog.Info("Development mode enabled - using Vite dev server for assets")
 	}
 
+	// Stop on SIGINT or SIGTERM; the store's background work stops with it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Listen straight away so probes can watch the content load
+	probe := &health.Probe{}
+	srv := &http.Server{
+		Addr:              port,
+		Handler:           probe,
+		ReadHeaderTimeout: viper.GetDuration("read_timeout"),
+		ReadTimeout:       viper.GetDuration("read_timeout"),
+		WriteTimeout:      viper.GetDuration("write_timeout"),
+		IdleTimeout:       viper.GetDuration("idle_timeout"),
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	slog.Info("Starting server", "port", port)
+
+	loaded := make(chan error, 1)
+	go func() {
+		app, err := newApp(ctx)
+		if err == nil {
+			probe.Ready(app)
+			slog.Info("Server ready")
+		}
+		loaded <- err
+	}()
+
+	for {
+		select {
+		case err := <-serveErr:
+			return err
+		case err := <-loaded:
+			if err != nil {
+				_ = srv.Close()
+				return err
+			}
+			loaded = nil
+		case <-ctx.Done():
+			// A second signal kills the process without waiting
+			stop()
+			return shutdown(srv, probe)
+		}
+	}
+}
+
+// shutdown drains the server: it fails health checks for the drain delay so
+// load balancers stop routing to it, then stops accepting connections and
+// waits up to the shutdown timeout for in-flight requests to finish.
+func shutdown(srv *http.Server, probe *health.Probe) error {
+	delay := viper.GetDuration("drain_delay")
+	timeout := viper.GetDuration("shutdown_timeout")
+	slog.Info("Shutting down", "drainDelay", delay, "timeout", timeout)
+
+	probe.Drain()
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutting down server: %w", err)
+	}
+	slog.Info("Server stopped")
+	return nil
+}
+
+// newApp loads the content store and builds the Echo app serving it. The
+// store's scheduler and watcher run until ctx is cancelled.
+func newApp(ctx context.Context) (*echo.Echo, error) {
 	// Initialize content store
-	store, err := initContentStore()
+	store, err := initContentStore(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	e := echo.New()
+	e.Logger = slog.Default()
+	compressor := compress.NewCompressor(compress.DefaultCompression)
 
 	// Middleware
 	e.Use(middleware.Recover())
 	e.Use(middleware.RequestID())
-	e.Use(middleware.RequestLogger())
-	e.Use(middleware.Gzip())
+	e.Use(metrics.Middleware())
+	e.Use(logging.RequestLogger(e.Logger, compress.WarmUserAgent))
+	e.Use(compress.Middleware(compressor))
 	e.Use(middleware.Secure())
 
 	// Initialize API handler
 	apiHandler := handlers.NewAPIHandler(store)
-
-	// Health check
-	e.GET("/healthz", healthHandler)
+	if signer := preview.NewSigner(viper.GetString("preview_secret")); signer != nil {
+		apiHandler.EnablePreviews(signer)
+	}
 
 	// API routes
 	api := e.Group("/api")
 	api.GET("/posts", apiHandler.ListPosts)
 	api.GET("/posts/:slug", apiHandler.GetPost)
+	api.GET("/posts/:slug/history", apiHandler.GetPostHistory)
 	api.GET("/tags", apiHandler.ListTags)
 	api.GET("/series", apiHandler.ListSeries)
+	api.GET("/series/:name", apiHandler.GetSeries)
+	api.GET("/authors", apiHandler.ListAuthors)
+	api.GET("/authors/:id", apiHandler.GetAuthor)
+	api.GET("/archive", apiHandler.ListArchive)
+	api.GET("/archive/:year", apiHandler.GetArchivePeriod)
+	api.GET("/archive/:year/:month", apiHandler.GetArchivePeriod)
+	api.GET("/search", apiHandler.Search)
 
 	// Post bundle assets (images, etc.)
 	e.GET("/posts/:slug/:filename", apiHandler.GetPostAsset)
+	e.GET("/posts/:slug/_img/:width/:filename", apiHandler.GetPostImage)
+
+	// Prometheus metrics
+	e.GET("/metrics", echo.WrapHandler(metrics.Default.Handler()))
 
 	// SEO
 	baseURL := viper.GetString("base_url")
 	e.GET("/robots.txt", handlers.RobotsTxtHandler(baseURL))
 	e.GET("/sitemap.xml", handlers.SitemapHandler(store, baseURL))
 
+	// Feeds
+	for _, format := range []feed.Format{feed.FormatRSS, feed.FormatAtom} {
+		e.GET("/"+format.Filename(), handlers.FeedHandler(store, baseURL, format))
+		e.GET("/tags/:tag/"+format.Filename(), handlers.FeedHandler(store, baseURL, format))
+		e.GET("/series/:series/"+format.Filename(), handlers.FeedHandler(store, baseURL, format))
+	}
+
 	// Serve embedded frontend SPA
 	distFS, err := fs.Sub(static.DistFS, "dist")
 	if err != nil {
-		return fmt.Errorf("loading static assets: %w", err)
+		return nil, fmt.Errorf("loading static assets: %w", err)
 	}
 
 	spaHandler, err := handlers.NewSPAHandler(distFS)
 	if err != nil {
-		return fmt.Errorf("initializing SPA handler: %w", err)
+		return nil, fmt.Errorf("initializing SPA handler: %w", err)
 	}
+	spaHandler.RenderPages(store, baseURL)
 
 	// Static assets route
 	e.GET("/assets/*", spaHandler.ServeAssets())
@@ -83,27 +191,221 @@ func runServer(_ *cobra.Command, _ []string) error {
 	// SPA fallback - all other routes serve index.html for client-side routing
 	e.GET("/*", spaHandler.Handler())
 
-	slog.Info("Starting server", "port", port)
-	return e.Start(port)
+	// Precompress the frontend, SSG pages and API responses so no request
+	// waits on Brotli
+	count, err := compressor.Precompress(distFS)
+	if err != nil {
+		return nil, fmt.Errorf("precompressing static assets: %w", err)
+	}
+	slog.Info("Precompressed static assets", "files", count)
+	compress.Warm(e, warmPaths(ctx, store))
+
+	return e, nil
 }
 
-func initContentStore() (content.ContentStore, error) {
-	// Create afero filesystem from embedded posts
-	postsSubFS, err := fs.Sub(embeddedcontent.PostsFS, "posts")
+// warmPaths returns the paths whose responses are rendered and compressed
+// at startup: the lists the frontend fetches, and the API response and
+// page for every post, series, author and archive period, plus every tag
+// page.
+func warmPaths(ctx context.Context, store content.ContentStore) []string {
+	paths := []string{"/api/posts", "/api/posts?limit=10", "/api/tags", "/api/series", "/api/authors",
+		"/api/archive", "/", "/posts", "/tags", "/series", "/authors", "/archive", "/about"}
+
+	if posts, _, err := store.ListPosts(ctx, content.ListOptions{}); err == nil {
+		for _, post := range posts {
+			slug := url.PathEscape(post.Meta.Slug)
+			paths = append(paths, "/api/posts/"+slug, "/posts/"+slug)
+		}
+	}
+	if tags, err := store.GetTags(ctx); err == nil {
+		for _, t := range tags {
+			paths = append(paths, "/tags/"+url.PathEscape(t.Tag))
+		}
+	}
+	if series, err := store.GetSeries(ctx); err == nil {
+		for _, s := range series {
+			name := url.PathEscape(s.Series)
+			paths = append(paths, "/api/series/"+name, "/series/"+name)
+		}
+	}
+	if authors, err := store.GetAuthors(ctx); err == nil {
+		for _, a := range authors {
+			id := url.PathEscape(a.Author.ID)
+			paths = append(paths, "/api/authors/"+id, "/authors/"+id)
+		}
+	}
+	if archive, err := store.GetArchive(ctx); err == nil {
+		for _, y := range archive {
+			period := views.ArchiveURL(y.Year, 0)
+			paths = append(paths, "/api"+period, period)
+			for _, m := range y.Months {
+				period := views.ArchiveURL(y.Year, m.Month)
+				paths = append(paths, "/api"+period, period)
+			}
+		}
+	}
+
+	return paths
+}
+
+func initContentStore(ctx context.Context) (content.ContentStore, error) {
+	kind := viper.GetString("content_store")
+	if repo := viper.GetString("content_git"); repo != "" {
+		if kind == "sqlite" {
+			return nil, errors.New("the sqlite content store can't load posts from git: unset --content-git or use --content-store embedded")
+		}
+		return initGitStore(ctx, repo)
+	}
+
+	var store liveStore
+	switch kind {
+	case "", "embedded":
+		embedded, err := newContentStore()
+		if err != nil {
+			return nil, err
+		}
+		store = embedded
+	case "sqlite":
+		afs, err := contentFS()
+		if err != nil {
+			return nil, err
+		}
+		sqlite, err := content.NewSQLiteStore(viper.GetString("content_db"), afs, newRenderer())
+		if err != nil {
+			return nil, err
+		}
+		store = sqlite
+	default:
+		return nil, fmt.Errorf("unknown content store %q (want embedded or sqlite)", kind)
+	}
+
+	registerContentMetrics(store)
+
+	// Publish scheduled posts when their time arrives
+	go store.Schedule(ctx)
+
+	// Reload posts as they're edited on disk
+	if dir := viper.GetString("content_dir"); dir != "" {
+		go func() {
+			if err := store.Watch(ctx, dir); err != nil {
+				slog.Error("Content watcher stopped", "error", err)
+			}
+		}()
+	}
+
+	return store, nil
+}
+
+// liveStore is a content store loaded from a content filesystem, which the
+// server keeps up to date.
+type liveStore interface {
+	content.ContentStore
+	Stats() content.Stats
+	Schedule(ctx context.Context)
+	Watch(ctx context.Context, dir string) error
+}
+
+// initGitStore loads posts from a git repository, follows new commits on
+// the configured ref, and switches refs when content_ref changes in the
+// config file.
+func initGitStore(ctx context.Context, repo string) (*content.GitStore, error) {
+	store, err := newGitStore(repo)
 	if err != nil {
-		return nil, fmt.Errorf("loading embedded posts: %w", err)
+		return nil, err
 	}
 
-	afs := afero.FromIOFS{FS: postsSubFS}
+	registerContentMetrics(store.EmbeddedStore)
+	go store.Schedule(ctx)
+	if interval := viper.GetDuration("content_poll"); interval > 0 {
+		go store.Poll(ctx, interval)
+	}
 
-	// Create renderer with shortcode support
-	r := renderer.New(views.ShortcodeRenderers())
+	if viper.ConfigFileUsed() != "" {
+		viper.OnConfigChange(func(_ fsnotify.Event) {
+			ref := viper.GetString("content_ref")
+			if current, _ := store.Ref(); ref == current {
+				return
+			}
+			if err := store.SetRef(ref); err != nil {
+				slog.Error("Failed to switch content ref", "ref", ref, "error", err)
+				return
+			}
+			slog.Info("Switched content ref", "ref", ref)
+		})
+		viper.WatchConfig()
+	}
+
+	return store, nil
+}
+
+// registerContentMetrics reports the store's counts as gauges, read from
+// the store on every scrape so reloads and scheduled posts show up.
+func registerContentMetrics(store interface{ Stats() content.Stats }) {
+	gauges := []struct {
+		name, help string
+		value      func(content.Stats) int
+	}{
+		{"therefore_content_posts", "Published posts.", func(s content.Stats) int { return s.Posts }},
+		{"therefore_content_drafts", "Draft posts, served only as previews.", func(s content.Stats) int { return s.Drafts }},
+		{"therefore_content_scheduled", "Posts waiting for a future publish date.", func(s content.Stats) int { return s.Scheduled }},
+		{"therefore_content_tags", "Tags on published posts.", func(s content.Stats) int { return s.Tags }},
+		{"therefore_content_series", "Series with published posts.", func(s content.Stats) int { return s.Series }},
+		{"therefore_content_authors", "Authors with published posts.", func(s content.Stats) int { return s.Authors }},
+	}
+	for _, g := range gauges {
+		metrics.NewGaugeFunc(g.name, g.help, func() float64 { return float64(g.value(store.Stats())) })
+	}
+}
+
+// newContentStore loads and renders every post from the configured content.
+func newContentStore() (*content.EmbeddedStore, error) {
+	if repo := viper.GetString("content_git"); repo != "" {
+		store, err := newGitStore(repo)
+		if err != nil {
+			return nil, err
+		}
+		return store.EmbeddedStore, nil
+	}
+
+	afs, err := contentFS()
+	if err != nil {
+		return nil, err
+	}
+	return content.NewEmbeddedStore(afs, newRenderer())
+}
+
+// newGitStore loads posts from the configured ref of a git repository.
+func newGitStore(repo string) (*content.GitStore, error) {
+	return content.NewGitStore(repo, viper.GetString("content_git_dir"), viper.GetString("content_ref"), newRenderer())
+}
 
-	return content.NewEmbeddedStore(afs, r)
+// newRenderer creates the markdown renderer with shortcode support.
+func newRenderer() *renderer.Renderer {
+	r := renderer.New(views.ShortcodeRenderers())
+	r.SetSpecs(views.ShortcodeSpecs())
+	r.SetStrict(viper.GetBool("strict_shortcodes"))
+	return r
 }
 
-func healthHandler(c *echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{
-		"status": "ok",
-	})
+// contentFS returns the filesystem posts are loaded from: the directory set
+// by --content-dir if any, otherwise the posts embedded in the binary.
+func contentFS() (afero.Fs, error) {
+	if dir := viper.GetString("content_dir"); dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("opening content directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("content directory %s is not a directory", dir)
+		}
+		return afero.NewBasePathFs(afero.NewOsFs(), dir), nil
+	}
+
+	// Create afero filesystem from embedded posts
+	postsSubFS, err := fs.Sub(embeddedcontent.PostsFS, "posts")
+	if err != nil {
+		return nil, fmt.Errorf("loading embedded posts: %w", err)
+	}
+
+	return afero.FromIOFS{FS: postsSubFS}, nil
 }
@@ -3,17 +3,11 @@ package main
 import (
 	"context"
 	"fmt"
-	"io/fs"
-	"log/slog"
 	"os"
 
-	embeddedcontent "therefore/content"
 	"therefore/internal/content"
-	"therefore/internal/renderer"
 	"therefore/internal/ssg"
-	"therefore/internal/views"
 
-	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -29,7 +23,9 @@ search engine crawlers while maintaining the React SPA experience for users
 with JavaScript enabled.
 
 The generated pages are written to the same directory as Vite's output
-(internal/static/dist by default), so they get embedded into the binary.`,
+(internal/static/dist by default), so they get embedded into the binary.
+The server renders the same pages on demand from the current content, so
+this is only needed to host the pages statically or as a fallback.`,
 	RunE: runSSG,
 }
 
@@ -44,21 +40,6 @@ func runSSG(_ *cobra.Command, _ []string) error {
 	baseURL := viper.GetString("base_url")
 	outDir := viper.GetString("ssg_output")
 
-	// Configure logging
-	logLevel := viper.GetString("log_level")
-	var level slog.Level
-	switch logLevel {
-	case "debug":
-		level = slog.LevelDebug
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
-	}
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
-
 	// Verify output directory exists (should have Vite's output)
 	if _, err := os.Stat(outDir); os.IsNotExist(err) {
 		return fmt.Errorf("output directory %s does not exist; run Vite build first", outDir)
@@ -82,16 +63,5 @@ func runSSG(_ *cobra.Command, _ []string) error {
 }
 
 func initSSGContentStore() (content.ContentStore, error) {
-	// Create afero filesystem from embedded posts
-	postsSubFS, err := fs.Sub(embeddedcontent.PostsFS, "posts")
-	if err != nil {
-		return nil, fmt.Errorf("loading embedded posts: %w", err)
-	}
-
-	afs := afero.FromIOFS{FS: postsSubFS}
-
-	// Create renderer with shortcode support
-	r := renderer.New(views.ShortcodeRenderers())
-
-	return content.NewEmbeddedStore(afs, r)
+	return newContentStore()
 }
@@ -80,6 +80,7 @@ export function Layout() {
             <NavLink to="/posts">Posts</NavLink>
             <NavLink to="/series">Series</NavLink>
             <NavLink to="/tags">Tags</NavLink>
+            <NavLink to="/archive">Archive</NavLink>
             <NavLink to="/about">About</NavLink>
             <Button
               variant="ghost"
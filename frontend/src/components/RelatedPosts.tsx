@@ -0,0 +1,50 @@
+import {TransitionLink} from './TransitionLink';
+import type {PostListItem} from '../hooks/api';
+
+interface RelatedPostsProps {
+  posts?: PostListItem[];
+}
+
+/**
+ * "Read next" list of the posts the server ranked as most similar to the
+ * current one. Renders nothing when there are none.
+ */
+export function RelatedPosts({posts}: RelatedPostsProps) {
+  if (!posts?.length) {
+    return null;
+  }
+
+  return (
+    <section className="related-posts mt-16 pt-8 border-t border-border">
+      <h2 className="text-sm font-medium text-default-500 mb-4 uppercase tracking-wider">
+        Read next
+      </h2>
+      <ul className="space-y-6">
+        {posts.map(post => (
+          <li key={post.slug}>
+            <TransitionLink
+              to={`/posts/${post.slug}`}
+              className="text-xl font-display font-semibold hover:text-accent transition-colors"
+            >
+              {post.title}
+            </TransitionLink>
+            <div className="text-sm text-muted mt-1">
+              <time dateTime={post.publishDate}>
+                {new Date(post.publishDate).toLocaleDateString('en-US', {
+                  year: 'numeric',
+                  month: 'long',
+                  day: 'numeric',
+                })}
+              </time>
+            </div>
+            {post.summary && (
+              <p className="text-foreground/80 leading-relaxed mt-2">
+                {post.summary}
+              </p>
+            )}
+          </li>
+        ))}
+      </ul>
+    </section>
+  );
+}
@@ -1,26 +1,10 @@
-import {useState, useMemo, useCallback, useRef} from 'react';
+import {useState, useEffect, useMemo, useCallback, useRef} from 'react';
 import {Modal, Input, Skeleton} from '@heroui/react';
-import Fuse, {type IFuseOptions, type FuseResult} from 'fuse.js';
-import {usePosts, type PostListItem} from '../hooks/api';
+import {useSearch, type SearchResult} from '../hooks/api';
 import {useViewTransitionNavigate} from '../hooks/useViewTransition';
 
-const FUSE_OPTIONS: IFuseOptions<PostListItem> = {
-  keys: [
-    {name: 'title', weight: 0.35},
-    {name: 'summary', weight: 0.25},
-    {name: 'searchContent', weight: 0.2},
-    {name: 'tags', weight: 0.15},
-    {name: 'series', weight: 0.05},
-  ],
-  threshold: 0.35,
-  distance: 100,
-  ignoreLocation: false,
-  includeMatches: true,
-  includeScore: true,
-  minMatchCharLength: 2,
-  shouldSort: true,
-  findAllMatches: false,
-};
+// How long typing must pause before the query is sent
+const SEARCH_DELAY_MS = 150;
 
 interface SearchModalProps {
   isOpen: boolean;
@@ -30,7 +14,7 @@ interface SearchModalProps {
 interface SeriesGroup {
   series: string;
   count: number;
-  posts: Array<FuseResult<PostListItem>>;
+  posts: SearchResult[];
 }
 
 function SearchResultSkeleton() {
@@ -75,6 +59,35 @@ function HighlightedText({
   return <>{parts}</>;
 }
 
+/**
+ * Find where the words of a query occur in text, as inclusive ranges
+ */
+function termIndices(text: string, query: string): Array<[number, number]> {
+  const lower = text.toLowerCase();
+  const ranges: Array<[number, number]> = [];
+  for (const term of query.toLowerCase().split(/\s+/)) {
+    if (term.length < 2) continue;
+    let i = lower.indexOf(term);
+    while (i !== -1) {
+      ranges.push([i, i + term.length - 1]);
+      i = lower.indexOf(term, i + 1);
+    }
+  }
+
+  // Merge overlapping ranges so no text is repeated
+  ranges.sort((a, b) => a[0] - b[0]);
+  const merged: Array<[number, number]> = [];
+  for (const [start, end] of ranges) {
+    const last = merged[merged.length - 1];
+    if (last && start <= last[1] + 1) {
+      last[1] = Math.max(last[1], end);
+    } else {
+      merged.push([start, end]);
+    }
+  }
+  return merged;
+}
+
 function SeriesCard({
   series,
   count,
@@ -112,7 +125,7 @@ function SeriesCard({
 
 export function SearchModal({isOpen, onOpenChange}: SearchModalProps) {
   const [query, setQuery] = useState('');
-  const {data, isLoading} = usePosts();
+  const [searchQuery, setSearchQuery] = useState('');
   const navigate = useViewTransitionNavigate();
 
   // Reset query when closing modal
@@ -133,16 +146,21 @@ export function SearchModal({isOpen, onOpenChange}: SearchModalProps) {
     [onOpenChange],
   );
 
-  const posts = data?.posts;
-  const fuse = useMemo(() => {
-    if (!posts) return null;
-    return new Fuse(posts, FUSE_OPTIONS);
-  }, [posts]);
+  // Search once typing pauses rather than on every keystroke
+  useEffect(() => {
+    const timeout = setTimeout(
+      () => setSearchQuery(query.trim()),
+      SEARCH_DELAY_MS,
+    );
+    return () => clearTimeout(timeout);
+  }, [query]);
 
-  const results = useMemo(() => {
-    if (!fuse || query.length < 2) return [];
-    return fuse.search(query, {limit: 12});
-  }, [fuse, query]);
+  const {data, error, isFetching} = useSearch(searchQuery);
+  const results = useMemo(
+    () => (query.trim().length < 2 ? [] : (data?.results ?? [])),
+    [data, query],
+  );
+  const isSettled = !isFetching && searchQuery === query.trim();
 
   // Group results by series and find the top series
   const {topSeries, showSeriesCard} = useMemo(() => {
@@ -153,7 +171,7 @@ export function SearchModal({isOpen, onOpenChange}: SearchModalProps) {
     // Group by series
     const seriesGroups = new Map<string, SeriesGroup>();
     for (const result of results) {
-      const series = result.item.series;
+      const series = result.series;
       if (series) {
         const existing = seriesGroups.get(series);
         if (existing) {
@@ -177,7 +195,7 @@ export function SearchModal({isOpen, onOpenChange}: SearchModalProps) {
     // - There's a series with 2+ matches
     // - There's more than one unique series (or some posts without series)
     const uniqueSeriesCount = seriesGroups.size;
-    const postsWithoutSeries = results.filter(r => !r.item.series).length;
+    const postsWithoutSeries = results.filter(r => !r.series).length;
     const hasVariety = uniqueSeriesCount > 1 || postsWithoutSeries > 0;
 
     const shouldShow = top !== null && top.count >= 2 && hasVariety;
@@ -232,20 +250,24 @@ export function SearchModal({isOpen, onOpenChange}: SearchModalProps) {
             </div>
           </Modal.Header>
           <Modal.Body className="px-2 py-3 min-h-[200px] max-h-[60vh] overflow-y-auto">
-            {isLoading ? (
-              <div className="space-y-2">
-                <SearchResultSkeleton />
-                <SearchResultSkeleton />
-                <SearchResultSkeleton />
-              </div>
-            ) : query.length < 2 ? (
+            {query.trim().length < 2 ? (
               <p className="text-center text-muted py-8">
                 Type at least 2 characters to search
               </p>
-            ) : results.length === 0 ? (
+            ) : error && isSettled ? (
+              <p className="text-center text-danger py-8">
+                Search failed. Please try again.
+              </p>
+            ) : results.length === 0 && isSettled ? (
               <p className="text-center text-muted py-8">
                 No posts found for "{query}"
               </p>
+            ) : results.length === 0 ? (
+              <div className="space-y-2">
+                <SearchResultSkeleton />
+                <SearchResultSkeleton />
+                <SearchResultSkeleton />
+              </div>
             ) : (
               <ul
                 className="space-y-1"
@@ -262,12 +284,10 @@ export function SearchModal({isOpen, onOpenChange}: SearchModalProps) {
                 )}
 
                 {/* Individual post results */}
-                {results.map(({item, matches}) => {
-                  const titleMatch = matches?.find(m => m.key === 'title');
-                  const summaryMatch = matches?.find(m => m.key === 'summary');
-                  const contentMatch = matches?.find(
-                    m => m.key === 'searchContent',
-                  );
+                {results.map(item => {
+                  const summaryIndices = item.summary
+                    ? termIndices(item.summary, searchQuery)
+                    : [];
 
                   return (
                     <li
@@ -281,27 +301,24 @@ export function SearchModal({isOpen, onOpenChange}: SearchModalProps) {
                       <h3 className="font-display font-semibold text-foreground">
                         <HighlightedText
                           text={item.title}
-                          indices={titleMatch?.indices}
+                          indices={termIndices(item.title, searchQuery)}
                         />
                       </h3>
                       {item.summary && (
                         <p className="text-sm text-muted line-clamp-2 mt-1">
                           <HighlightedText
                             text={item.summary}
-                            indices={summaryMatch?.indices}
+                            indices={summaryIndices}
                           />
                         </p>
                       )}
-                      {/* Show content match snippet if no summary match */}
-                      {!summaryMatch && contentMatch && item.searchContent && (
-                        <p className="text-sm text-muted line-clamp-2 mt-1 italic">
-                          ...
-                          {getMatchSnippet(
-                            item.searchContent,
-                            contentMatch.indices,
-                          )}
-                          ...
-                        </p>
+                      {/* Show where the body matched if the summary didn't */}
+                      {summaryIndices.length === 0 && item.snippet && (
+                        <p
+                          className="text-sm text-muted line-clamp-2 mt-1 italic [&_mark]:bg-accent/30 [&_mark]:text-foreground [&_mark]:rounded [&_mark]:px-0.5"
+                          // The server escapes the text around each <mark>
+                          dangerouslySetInnerHTML={{__html: item.snippet}}
+                        />
                       )}
                       <div className="flex items-center gap-2 mt-2 text-xs text-muted">
                         <time dateTime={item.publishDate}>
@@ -363,22 +380,3 @@ export function SearchModal({isOpen, onOpenChange}: SearchModalProps) {
     </Modal.Backdrop>
   );
 }
-
-/**
- * Extract a snippet around the first match in the content
- */
-function getMatchSnippet(
-  text: string,
-  indices: ReadonlyArray<[number, number]> | undefined,
-  contextChars: number = 60,
-): string {
-  if (!indices || indices.length === 0) {
-    return text.slice(0, contextChars * 2);
-  }
-
-  const [start, end] = indices[0];
-  const snippetStart = Math.max(0, start - contextChars);
-  const snippetEnd = Math.min(text.length, end + contextChars);
-
-  return text.slice(snippetStart, snippetEnd);
-}
@@ -3,9 +3,11 @@ import {motion, AnimatePresence} from 'motion/react';
 import {Chip} from '@heroui/react';
 import {SeriesTimeline} from './SeriesTimeline';
 import {TagLink} from './TagLink';
+import {TransitionLink} from './TransitionLink';
 
 interface SeriesAccordionProps {
   series: string;
+  title?: string;
   count: number;
   topTags?: string[];
   hasRecentPosts?: boolean;
@@ -15,6 +17,7 @@ interface SeriesAccordionProps {
 
 export function SeriesAccordion({
   series,
+  title,
   count,
   topTags,
   hasRecentPosts,
@@ -82,7 +85,7 @@ export function SeriesAccordion({
         <div className="flex flex-col gap-1">
           <div className="flex items-center gap-3">
             <span className="text-2xl font-display font-semibold">
-              {series}
+              {title ?? series}
             </span>
             {hasRecentPosts && (
               <Chip size="sm" color="accent">
@@ -118,6 +121,14 @@ export function SeriesAccordion({
             className="overflow-hidden bg-surface/30"
           >
             <SeriesTimeline series={series} />
+            <div className="px-6 pb-6">
+              <TransitionLink
+                to={`/series/${encodeURIComponent(series)}`}
+                className="text-sm text-primary hover:underline"
+              >
+                View series &rarr;
+              </TransitionLink>
+            </div>
           </motion.div>
         )}
       </AnimatePresence>
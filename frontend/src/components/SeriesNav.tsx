@@ -0,0 +1,54 @@
+import {TransitionLink} from './TransitionLink';
+import type {SeriesNav as SeriesNavData} from '../hooks/api';
+
+interface SeriesNavProps {
+  series?: string;
+  nav?: SeriesNavData;
+}
+
+/**
+ * Shows the post's place in its series with links to the previous and next
+ * parts. Renders nothing for posts outside a series.
+ */
+export function SeriesNav({series, nav}: SeriesNavProps) {
+  if (!series || !nav) {
+    return null;
+  }
+
+  return (
+    <nav
+      className="series-nav mt-12 p-6 rounded-lg bg-surface border border-border"
+      aria-label="Series navigation"
+    >
+      <div className="text-sm text-muted mb-3">
+        Part {nav.part} of {nav.total} in{' '}
+        <TransitionLink
+          to={`/series/${encodeURIComponent(series)}`}
+          className="text-foreground hover:text-accent transition-colors"
+        >
+          {nav.title}
+        </TransitionLink>
+      </div>
+      <div className="flex justify-between gap-4">
+        {nav.prev ? (
+          <TransitionLink
+            to={`/posts/${nav.prev.slug}`}
+            className="font-display font-semibold hover:text-accent transition-colors"
+          >
+            &larr; {nav.prev.title}
+          </TransitionLink>
+        ) : (
+          <span />
+        )}
+        {nav.next && (
+          <TransitionLink
+            to={`/posts/${nav.next.slug}`}
+            className="font-display font-semibold text-right hover:text-accent transition-colors"
+          >
+            {nav.next.title} &rarr;
+          </TransitionLink>
+        )}
+      </div>
+    </nav>
+  );
+}
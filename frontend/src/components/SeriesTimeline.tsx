@@ -1,4 +1,4 @@
-import {useSeriesPosts} from '../hooks/api';
+import {useSeriesDetail} from '../hooks/api';
 import {TransitionLink} from './TransitionLink';
 import {Card, Chip} from '@heroui/react';
 import {motion} from 'motion/react';
@@ -13,18 +13,16 @@ function isNewPost(publishDate: string): boolean {
 }
 
 export function SeriesTimeline({series}: SeriesTimelineProps) {
-  const {data, isLoading} = useSeriesPosts(series);
+  const {data, isLoading} = useSeriesDetail(series);
 
   if (isLoading) return <div className="p-6 text-muted">Loading...</div>;
   if (!data?.posts.length)
     return <div className="p-6 text-muted">No posts found.</div>;
 
-  // Sort posts by date ascending (oldest first) for sequential reading
-  const sortedPosts = [...data.posts].reverse();
-
+  // Posts arrive in reading order
   return (
     <div className="relative pl-6 py-6 space-y-4 border-l-2 border-accent/20 ml-6 mb-2">
-      {sortedPosts.map((post, index) => (
+      {data.posts.map((post, index) => (
         <motion.div
           key={post.slug}
           initial={{opacity: 0, x: -20}}
@@ -1,15 +1,32 @@
-import {useRef} from 'react';
+import {useMemo, useRef} from 'react';
 import {useScrollspy, type Heading} from '../hooks/useScrollspy';
+import type {TocEntry} from '../hooks/api';
 
 interface TableOfContentsProps {
   containerRef: React.RefObject<HTMLElement | null>;
+  /** Server-computed outline; headings are scraped from the DOM if omitted */
+  toc?: TocEntry[];
 }
 
-export function TableOfContents({containerRef}: TableOfContentsProps) {
+/**
+ * Flatten the outline to the h2 and h3 entries shown in the sidebar
+ */
+function flattenToc(entries: TocEntry[]): Heading[] {
+  return entries.flatMap(entry => [
+    ...(entry.level === 2 || entry.level === 3
+      ? [{id: entry.id, text: entry.text, level: entry.level}]
+      : []),
+    ...flattenToc(entry.children ?? []),
+  ]);
+}
+
+export function TableOfContents({containerRef, toc}: TableOfContentsProps) {
   const tocRef = useRef<HTMLElement>(null);
+  const headingList = useMemo(() => toc && flattenToc(toc), [toc]);
   const {headings, activeId, scrollToHeading} = useScrollspy({
     containerRef,
     tocRef,
+    toc: headingList,
   });
 
   if (headings.length === 0) {
@@ -2,9 +2,42 @@ import {useQuery, keepPreviousData} from '@tanstack/react-query';
 
 // API response types
 export interface Author {
+  id?: string; // Absent for inline authors, which have no page
   name: string;
   avatar?: string;
   bio?: string;
+  url?: string;
+}
+
+export interface AuthorCount extends Author {
+  id: string;
+  count: number;
+}
+
+export interface AuthorDetail extends Author {
+  id: string;
+  posts: PostListItem[];
+  total: number;
+  nextCursor?: string;
+}
+
+export interface ArchiveMonth {
+  month: number; // 1 for January
+  count: number;
+}
+
+export interface ArchiveYear {
+  year: number;
+  count: number;
+  months: ArchiveMonth[]; // Latest first
+}
+
+export interface ArchivePeriod {
+  year: number;
+  month?: number; // Absent for a whole year
+  posts: PostListItem[];
+  total: number;
+  nextCursor?: string;
 }
 
 export interface PostListItem {
@@ -12,20 +45,65 @@ export interface PostListItem {
   title: string;
   summary?: string;
   publishDate: string;
+  updatedDate?: string; // Present only for revised posts
   tags?: string[];
   series?: string;
   readingTime: number;
   searchContent?: string;
 }
 
+export interface ChangelogEntry {
+  date: string;
+  note: string;
+}
+
+export interface TocEntry {
+  level: number;
+  id: string;
+  text: string;
+  children?: TocEntry[];
+}
+
+export interface PostLink {
+  slug: string;
+  title: string;
+}
+
+export interface SeriesNav {
+  title: string;
+  part: number;
+  total: number;
+  prev?: PostLink;
+  next?: PostLink;
+}
+
 export interface PostDetail extends PostListItem {
   htmlContent: string;
   author?: Author;
+  authors?: Author[];
+  toc?: TocEntry[];
+  changelog?: ChangelogEntry[];
+  related?: PostListItem[];
+  seriesNav?: SeriesNav;
+  draft?: boolean;
 }
 
 export interface PostsResponse {
   posts: PostListItem[];
   total: number;
+  nextCursor?: string;
+  prevCursor?: string;
+}
+
+export interface SearchResult extends PostListItem {
+  score: number;
+  snippet?: string; // Escaped HTML with matches wrapped in <mark>
+}
+
+export interface SearchResponse {
+  query: string;
+  results: SearchResult[];
+  total: number;
 }
 
 export interface TagResponse {
@@ -38,6 +116,21 @@ export interface SeriesResponse {
   count: number;
   topTags?: string[];
   hasRecentPosts: boolean;
+  title: string;
+  description?: string;
+  cover?: string;
+}
+
+export interface SeriesPost extends PostListItem {
+  part: number;
+}
+
+export interface SeriesDetail {
+  series: string;
+  title: string;
+  description?: string;
+  cover?: string;
+  posts: SeriesPost[];
 }
 
 // Pagination and sorting options
@@ -45,6 +138,7 @@ export interface PostsQueryOptions {
   tag?: string;
   limit?: number;
   offset?: number;
+  cursor?: string;
   sortBy?: 'date' | 'title' | 'readingTime';
   sortOrder?: 'asc' | 'desc';
 }
@@ -58,6 +152,7 @@ async function fetchPosts(
   if (options.tag) params.set('tag', options.tag);
   if (options.limit) params.set('limit', options.limit.toString());
   if (options.offset) params.set('offset', options.offset.toString());
+  if (options.cursor) params.set('cursor', options.cursor);
   if (options.sortBy) params.set('sortBy', options.sortBy);
   if (options.sortOrder) params.set('sortOrder', options.sortOrder);
 
@@ -71,12 +166,54 @@ async function fetchPosts(
   return res.json();
 }
 
-async function fetchPost(slug: string): Promise<PostDetail> {
-  const res = await fetch(`/api/posts/${encodeURIComponent(slug)}`);
+// Fetches every page of a listing, following nextCursor past the server's
+// page size limit, and joins their posts into the first
+async function fetchAllPages<
+  T extends {posts: PostListItem[]; nextCursor?: string},
+>(fetchPage: (cursor?: string) => Promise<T>): Promise<T> {
+  const first = await fetchPage();
+  const posts = [...first.posts];
+  let cursor = first.nextCursor;
+  while (cursor) {
+    const page = await fetchPage(cursor);
+    posts.push(...page.posts);
+    cursor = page.nextCursor;
+  }
+  return {...first, posts, nextCursor: undefined};
+}
+
+// Fetches every post, with the tag if one is given
+async function fetchAllPosts(tag?: string): Promise<PostsResponse> {
+  const {posts, total} = await fetchAllPages(cursor =>
+    fetchPosts({tag, cursor}),
+  );
+  return {posts, total};
+}
+
+async function fetchSearch(
+  query: string,
+  limit: number,
+): Promise<SearchResponse> {
+  const params = new URLSearchParams({q: query, limit: limit.toString()});
+  const res = await fetch(`/api/search?${params}`);
+  if (!res.ok) {
+    throw new Error('Failed to search posts');
+  }
+  return res.json();
+}
+
+async function fetchPost(slug: string, preview?: string): Promise<PostDetail> {
+  let url = `/api/posts/${encodeURIComponent(slug)}`;
+  if (preview) url += `?preview=${encodeURIComponent(preview)}`;
+
+  const res = await fetch(url);
   if (!res.ok) {
     if (res.status === 404) {
       throw new Error('Post not found');
     }
+    if (res.status === 403) {
+      throw new Error('Preview link is invalid or has expired');
+    }
     throw new Error('Failed to fetch post');
   }
   return res.json();
@@ -98,24 +235,85 @@ async function fetchSeries(): Promise<SeriesResponse[]> {
   return res.json();
 }
 
-async function fetchSeriesPosts(series: string): Promise<PostsResponse> {
-  const res = await fetch(`/api/posts?series=${encodeURIComponent(series)}`);
+async function fetchSeriesDetail(series: string): Promise<SeriesDetail> {
+  const res = await fetch(`/api/series/${encodeURIComponent(series)}`);
   if (!res.ok) {
-    throw new Error('Failed to fetch series posts');
+    if (res.status === 404) {
+      throw new Error('Series not found');
+    }
+    throw new Error('Failed to fetch series');
+  }
+  return res.json();
+}
+
+async function fetchAuthors(): Promise<AuthorCount[]> {
+  const res = await fetch('/api/authors');
+  if (!res.ok) {
+    throw new Error('Failed to fetch authors');
   }
   return res.json();
 }
 
+async function fetchAuthor(
+  id: string,
+  cursor?: string,
+): Promise<AuthorDetail> {
+  let url = `/api/authors/${encodeURIComponent(id)}`;
+  if (cursor) url += `?cursor=${encodeURIComponent(cursor)}`;
+
+  const res = await fetch(url);
+  if (!res.ok) {
+    if (res.status === 404) {
+      throw new Error('Author not found');
+    }
+    throw new Error('Failed to fetch author');
+  }
+  return res.json();
+}
+
+async function fetchArchive(): Promise<ArchiveYear[]> {
+  const res = await fetch('/api/archive');
+  if (!res.ok) {
+    throw new Error('Failed to fetch archive');
+  }
+  return res.json();
+}
+
+async function fetchArchivePeriod(
+  year: number,
+  month?: number,
+  cursor?: string,
+): Promise<ArchivePeriod> {
+  let url = `/api${archiveURL(year, month)}`;
+  if (cursor) url += `?cursor=${encodeURIComponent(cursor)}`;
+
+  const res = await fetch(url);
+  if (!res.ok) {
+    if (res.status === 404) {
+      throw new Error('No posts from then');
+    }
+    throw new Error('Failed to fetch archive');
+  }
+  return res.json();
+}
+
+// The page for a year, or a month of it: /archive/2024 or /archive/2024/03
+export function archiveURL(year: number, month?: number): string {
+  return month
+    ? `/archive/${year}/${String(month).padStart(2, '0')}`
+    : `/archive/${year}`;
+}
+
 // React Query hooks
 export function usePosts(tag?: string) {
   return useQuery({
     queryKey: ['posts', tag ?? 'all'],
-    queryFn: () => fetchPosts({tag}),
+    queryFn: () => fetchAllPosts(tag),
   });
 }
 
 export function usePaginatedPosts(options: PostsQueryOptions = {}) {
-  const {tag, limit = 10, offset = 0, sortBy, sortOrder} = options;
+  const {tag, limit = 10, offset = 0, cursor, sortBy, sortOrder} = options;
 
   return useQuery({
     queryKey: [
@@ -124,18 +322,29 @@ export function usePaginatedPosts(options: PostsQueryOptions = {}) {
       tag ?? 'all',
       limit,
       offset,
+      cursor,
       sortBy,
       sortOrder,
     ],
-    queryFn: () => fetchPosts({tag, limit, offset, sortBy, sortOrder}),
+    queryFn: () => fetchPosts({tag, limit, offset, cursor, sortBy, sortOrder}),
     placeholderData: keepPreviousData, // Keep previous data while loading new page
   });
 }
 
-export function usePost(slug: string) {
+// Full-text search over every published post, best matches first
+export function useSearch(query: string, limit = 12) {
   return useQuery({
-    queryKey: ['post', slug],
-    queryFn: () => fetchPost(slug),
+    queryKey: ['search', query, limit],
+    queryFn: () => fetchSearch(query, limit),
+    enabled: query.length >= 2,
+    placeholderData: keepPreviousData, // Keep results on screen while typing
+  });
+}
+
+export function usePost(slug: string, preview?: string) {
+  return useQuery({
+    queryKey: preview ? ['post', slug, 'preview', preview] : ['post', slug],
+    queryFn: () => fetchPost(slug, preview),
     enabled: !!slug,
   });
 }
@@ -154,10 +363,44 @@ export function useSeries() {
   });
 }
 
-export function useSeriesPosts(series: string) {
+// Posts in a series, in reading order
+export function useSeriesDetail(series: string) {
   return useQuery({
-    queryKey: ['posts', 'series', series],
-    queryFn: () => fetchSeriesPosts(series),
+    queryKey: ['series', series],
+    queryFn: () => fetchSeriesDetail(series),
     enabled: !!series,
   });
 }
+
+export function useAuthors() {
+  return useQuery({
+    queryKey: ['authors'],
+    queryFn: fetchAuthors,
+  });
+}
+
+// An author's profile with all of their posts
+export function useAuthor(id: string) {
+  return useQuery({
+    queryKey: ['author', id],
+    queryFn: () => fetchAllPages(cursor => fetchAuthor(id, cursor)),
+    enabled: !!id,
+  });
+}
+
+export function useArchive() {
+  return useQuery({
+    queryKey: ['archive'],
+    queryFn: fetchArchive,
+  });
+}
+
+// All posts from a year, or a month of it if month is set
+export function useArchivePeriod(year: number, month?: number) {
+  return useQuery({
+    queryKey: ['archive', year, month],
+    queryFn: () =>
+      fetchAllPages(cursor => fetchArchivePeriod(year, month, cursor)),
+    enabled: year > 0,
+  });
+}
@@ -7,6 +7,7 @@ interface PageMeta {
   description?: string;
   type?: 'website' | 'article';
   publishedTime?: string;
+  modifiedTime?: string;
   author?: string;
   url?: string;
   image?: string;
@@ -82,6 +83,9 @@ export function usePageMeta(meta: PageMeta | string = {}) {
     if (opts.publishedTime) {
       setMetaTag('property', 'article:published_time', opts.publishedTime);
     }
+    if (opts.modifiedTime) {
+      setMetaTag('property', 'article:modified_time', opts.modifiedTime);
+    }
 
     // Twitter Card - use large image card when image is available
     setMetaTag(
@@ -103,6 +107,7 @@ export function usePageMeta(meta: PageMeta | string = {}) {
       clearMetaTag('property', 'og:url');
       clearMetaTag('property', 'og:image');
       clearMetaTag('property', 'article:published_time');
+      clearMetaTag('property', 'article:modified_time');
       clearMetaTag('name', 'twitter:card');
       clearMetaTag('name', 'twitter:title');
       clearMetaTag('name', 'twitter:description');
@@ -115,5 +120,6 @@ export function usePageMeta(meta: PageMeta | string = {}) {
     opts.url,
     opts.image,
     opts.publishedTime,
+    opts.modifiedTime,
   ]);
 }
@@ -5,6 +5,14 @@ import {useQueryClient} from '@tanstack/react-query';
  * SSG data embedded in the page by the Go SSG generator.
  * Structure matches the API response types.
  */
+interface SSGAuthor {
+  id?: string;
+  name: string;
+  avatar?: string;
+  bio?: string;
+  url?: string;
+}
+
 interface SSGData {
   // For post list pages (home, tag)
   posts?: Array<{
@@ -29,11 +37,41 @@ interface SSGData {
     series?: string;
     readingTime: number;
     htmlContent: string;
-    author?: {
-      name: string;
-      avatar?: string;
-      bio?: string;
-    };
+    author?: SSGAuthor;
+    authors?: SSGAuthor[];
+  };
+
+  // For author pages
+  author?: SSGAuthor & {
+    id: string;
+    posts: SSGData['posts'];
+    total: number;
+  };
+
+  // For archive year and month pages
+  archive?: {
+    year: number;
+    month?: number;
+    posts: SSGData['posts'];
+    total: number;
+  };
+
+  // For series pages
+  series?: {
+    series: string;
+    title: string;
+    description?: string;
+    cover?: string;
+    posts: Array<{
+      slug: string;
+      title: string;
+      summary?: string;
+      publishDate: string;
+      tags?: string[];
+      series?: string;
+      readingTime: number;
+      part: number;
+    }>;
   };
 }
 
@@ -68,6 +106,24 @@ export function useSSGData() {
         queryClient.setQueryData(['post', data.post.slug], data.post);
       }
 
+      // Pre-seed author detail data
+      if (data.author) {
+        queryClient.setQueryData(['author', data.author.id], data.author);
+      }
+
+      // Pre-seed archive period data
+      if (data.archive) {
+        queryClient.setQueryData(
+          ['archive', data.archive.year, data.archive.month],
+          data.archive,
+        );
+      }
+
+      // Pre-seed series detail data
+      if (data.series) {
+        queryClient.setQueryData(['series', data.series.series], data.series);
+      }
+
       // Pre-seed posts list data
       if (data.posts && data.total !== undefined) {
         // Build the query key based on what filters were used
@@ -92,7 +148,7 @@ export function useSSGData() {
  */
 function buildPostsQueryKey(tag?: string): Array<string | number | undefined> {
   // Match the key structure from usePaginatedPosts in api.ts:
-  // ["posts", "paginated", tag ?? "all", limit, offset, sortBy, sortOrder]
+  // ["posts", "paginated", tag ?? "all", limit, offset, cursor, sortBy, sortOrder]
   // Home page uses limit=10, tag pages use limit=6
   // sortBy and sortOrder default to undefined (use API defaults)
   return [
@@ -101,6 +157,7 @@ function buildPostsQueryKey(tag?: string): Array<string | number | undefined> {
     tag ?? 'all',
     tag ? 6 : 10, // limit: 6 for tag pages, 10 for home
     0, // offset: always 0 for SSG (first page only)
+    undefined, // cursor: none for the first page
     undefined, // sortBy: use API default
     undefined, // sortOrder: use API default
   ];
@@ -11,6 +11,8 @@ interface UseScrollspyOptions {
   containerRef: React.RefObject<HTMLElement | null>;
   /** Ref to the TOC element itself for dynamic horizon calculation */
   tocRef?: React.RefObject<HTMLElement | null>;
+  /** Headings computed server-side; scraped from the container if omitted */
+  toc?: Heading[];
   /** Throttle delay in ms */
   throttleMs?: number;
 }
@@ -65,6 +67,7 @@ function getHeaderHeight(): number {
 export function useScrollspy({
   containerRef,
   tocRef,
+  toc,
   throttleMs = 10,
 }: UseScrollspyOptions) {
   const [headings, setHeadings] = useState<Heading[]>([]);
@@ -89,20 +92,21 @@ export function useScrollspy({
     const container = containerRef.current;
     if (!container) return;
 
-    const extracted = extractHeadings(container);
+    const extracted = toc ?? extractHeadings(container);
     setHeadings(extracted);
 
     // Store elements in reverse order for efficient lookup
-    const headingElements =
-      container.querySelectorAll<HTMLElement>('h2[id], h3[id]');
-    sectionsRef.current = [...headingElements].reverse();
+    const headingElements = extracted
+      .map(h => container.querySelector<HTMLElement>(`#${CSS.escape(h.id)}`))
+      .filter((el): el is HTMLElement => el !== null);
+    sectionsRef.current = headingElements.reverse();
 
     // Set initial active heading (only once)
     if (extracted.length > 0 && !initializedRef.current) {
       initializedRef.current = true;
       setActiveId(extracted[0].id);
     }
-  }, [containerRef]);
+  }, [containerRef, toc]);
 
   // Schedule heading extraction after render
   useEffect(() => {
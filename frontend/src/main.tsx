@@ -14,6 +14,11 @@ import {
   TagPage,
   AboutPage,
   SeriesPage,
+  SeriesDetailPage,
+  AuthorsPage,
+  AuthorPage,
+  ArchivePage,
+  ArchivePeriodPage,
 } from './pages';
 
 const queryClient = new QueryClient({
@@ -41,6 +46,15 @@ function App() {
               <Route path="/tags" element={<TagsPage />} />
               <Route path="/tags/:tag" element={<TagPage />} />
               <Route path="/series" element={<SeriesPage />} />
+              <Route path="/series/:name" element={<SeriesDetailPage />} />
+              <Route path="/authors" element={<AuthorsPage />} />
+              <Route path="/authors/:id" element={<AuthorPage />} />
+              <Route path="/archive" element={<ArchivePage />} />
+              <Route path="/archive/:year" element={<ArchivePeriodPage />} />
+              <Route
+                path="/archive/:year/:month"
+                element={<ArchivePeriodPage />}
+              />
               <Route path="/about" element={<AboutPage />} />
             </Route>
           </Routes>
@@ -0,0 +1,86 @@
+import {Skeleton} from '@heroui/react';
+import {archiveURL, useArchive} from '../hooks/api';
+import {TransitionLink} from '../components/TransitionLink';
+import {usePageMeta} from '../hooks/usePageMeta';
+
+function monthName(month: number): string {
+  return new Date(2000, month - 1).toLocaleDateString('en-US', {
+    month: 'long',
+  });
+}
+
+export function ArchivePage() {
+  usePageMeta({
+    title: 'Archive',
+    description: 'Browse every post on Therefore by year and month.',
+  });
+  const {data: archive, isLoading, error} = useArchive();
+
+  if (isLoading) {
+    return (
+      <div className="max-w-3xl mx-auto">
+        <h1 className="text-4xl font-display font-bold mb-8">Archive</h1>
+        <div className="space-y-8">
+          <Skeleton className="h-24 w-full" />
+          <Skeleton className="h-24 w-full" />
+        </div>
+      </div>
+    );
+  }
+
+  if (error) {
+    return (
+      <div className="text-center py-12">
+        <p className="text-danger">
+          Failed to load the archive. Please try again.
+        </p>
+      </div>
+    );
+  }
+
+  if (!archive?.length) {
+    return (
+      <div className="text-center py-12">
+        <h1 className="text-4xl font-display font-bold mb-4">Archive</h1>
+        <p className="text-default-500">No posts yet.</p>
+      </div>
+    );
+  }
+
+  return (
+    <div className="max-w-3xl mx-auto">
+      <h1 className="text-4xl font-display font-bold mb-8">Archive</h1>
+      <div className="space-y-8">
+        {archive.map(year => (
+          <section key={year.year}>
+            <div className="flex items-baseline justify-between gap-3 mb-3">
+              <h2 className="text-2xl font-display font-semibold">
+                <TransitionLink
+                  to={archiveURL(year.year)}
+                  className="hover:text-accent transition-colors"
+                >
+                  {year.year}
+                </TransitionLink>
+              </h2>
+              <span className="text-muted text-sm">
+                {year.count} post{year.count !== 1 ? 's' : ''}
+              </span>
+            </div>
+            <div className="flex flex-wrap gap-3">
+              {year.months.map(month => (
+                <TransitionLink
+                  key={month.month}
+                  to={archiveURL(year.year, month.month)}
+                  className="inline-flex items-center gap-2 px-4 py-2 rounded-full bg-surface hover:bg-surface-hover border border-border transition-colors"
+                >
+                  {monthName(month.month)}{' '}
+                  <span className="text-muted text-sm">({month.count})</span>
+                </TransitionLink>
+              ))}
+            </div>
+          </section>
+        ))}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,136 @@
+import {useParams} from 'react-router-dom';
+import {Skeleton} from '@heroui/react';
+import {archiveURL, useArchivePeriod} from '../hooks/api';
+import {TransitionLink} from '../components/TransitionLink';
+import {TagLink} from '../components/TagLink';
+import {usePageMeta} from '../hooks/usePageMeta';
+import {useSSGData} from '../hooks/useSSGData';
+
+function BackLink() {
+  return (
+    <nav className="mb-8">
+      <TransitionLink
+        to="/archive"
+        className="text-default-500 hover:text-primary transition-colors"
+      >
+        &larr; Archive
+      </TransitionLink>
+    </nav>
+  );
+}
+
+// "2024" for a year, "March 2024" for a month of it
+function periodTitle(year: number, month?: number): string {
+  if (!month) {
+    return String(year);
+  }
+  return new Date(year, month - 1).toLocaleDateString('en-US', {
+    year: 'numeric',
+    month: 'long',
+  });
+}
+
+export function ArchivePeriodPage() {
+  useSSGData(); // Pre-seed query cache from SSG data
+  const params = useParams<{year: string; month?: string}>();
+  const isNumber = (s?: string) => /^\d+$/.test(s ?? '');
+  // A year of 0 disables the query, for paths that can't be a period
+  const valid =
+    isNumber(params.year) && (!params.month || isNumber(params.month));
+  const year = valid ? Number(params.year) : 0;
+  const month = params.month ? Number(params.month) : undefined;
+  const {data, isLoading, error} = useArchivePeriod(year, month);
+  const title = periodTitle(year, month);
+  usePageMeta(
+    data
+      ? {
+          title,
+          description: `All posts from ${title} on Therefore.`,
+          url: `${window.location.origin}${archiveURL(year, month)}`,
+        }
+      : {},
+  );
+
+  if (isLoading) {
+    return (
+      <div className="max-w-3xl mx-auto">
+        <BackLink />
+        <Skeleton className="h-10 w-1/3 mb-2" />
+        <Skeleton className="h-5 w-20 mb-8" />
+        <div className="space-y-6">
+          <Skeleton className="h-32 w-full" />
+          <Skeleton className="h-32 w-full" />
+        </div>
+      </div>
+    );
+  }
+
+  if (error || !data) {
+    const isNotFound = !year || error?.message === 'No posts from then';
+    return (
+      <div className="max-w-3xl mx-auto text-center py-12">
+        <h1 className="text-2xl font-display font-bold mb-4">
+          {isNotFound ? 'Nothing Here' : 'Error'}
+        </h1>
+        <p className="text-default-500 mb-6">
+          {isNotFound
+            ? 'There are no posts from then.'
+            : 'Failed to load the archive. Please try again.'}
+        </p>
+        <TransitionLink to="/archive" className="text-primary hover:underline">
+          &larr; Archive
+        </TransitionLink>
+      </div>
+    );
+  }
+
+  return (
+    <div className="max-w-3xl mx-auto">
+      <BackLink />
+      <h1 className="text-4xl font-display font-bold mb-2">{title}</h1>
+      <p className="text-muted mb-8">
+        {data.total} post{data.total !== 1 ? 's' : ''}
+      </p>
+      <div className="space-y-6">
+        {data.posts.map(post => (
+          <article
+            key={post.slug}
+            className="p-6 rounded-lg bg-surface hover:bg-surface-hover transition-colors border border-border"
+          >
+            <h2 className="text-2xl font-display font-semibold pb-2">
+              <TransitionLink
+                to={`/posts/${post.slug}`}
+                className="hover:text-accent transition-colors"
+              >
+                {post.title}
+              </TransitionLink>
+            </h2>
+            <div className="flex items-center gap-3 text-sm text-muted mb-3">
+              <time dateTime={post.publishDate}>
+                {new Date(post.publishDate).toLocaleDateString('en-US', {
+                  year: 'numeric',
+                  month: 'long',
+                  day: 'numeric',
+                })}
+              </time>
+              <span>&middot;</span>
+              <span>{post.readingTime} min read</span>
+            </div>
+            {post.summary && (
+              <p className="text-foreground/80 leading-relaxed">
+                {post.summary}
+              </p>
+            )}
+            {post.tags && post.tags.length > 0 && (
+              <div className="pt-3 flex flex-wrap gap-3">
+                {post.tags.map(tag => (
+                  <TagLink key={tag} tag={tag} className="text-sm" />
+                ))}
+              </div>
+            )}
+          </article>
+        ))}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,151 @@
+import {useParams} from 'react-router-dom';
+import {Skeleton} from '@heroui/react';
+import {useAuthor} from '../hooks/api';
+import {TransitionLink} from '../components/TransitionLink';
+import {TagLink} from '../components/TagLink';
+import {usePageMeta} from '../hooks/usePageMeta';
+import {useSSGData} from '../hooks/useSSGData';
+
+function BackLink() {
+  return (
+    <nav className="mb-8">
+      <TransitionLink
+        to="/authors"
+        className="text-default-500 hover:text-primary transition-colors"
+      >
+        &larr; All Authors
+      </TransitionLink>
+    </nav>
+  );
+}
+
+export function AuthorPage() {
+  useSSGData(); // Pre-seed query cache from SSG data
+  const {id} = useParams<{id: string}>();
+  const {data, isLoading, error} = useAuthor(id ?? '');
+  usePageMeta(
+    data
+      ? {
+          title: data.name,
+          description: data.bio ?? `All posts by ${data.name} on Therefore.`,
+          url: `${window.location.origin}/authors/${data.id}`,
+          image: data.avatar,
+        }
+      : {},
+  );
+
+  if (isLoading) {
+    return (
+      <div className="max-w-3xl mx-auto">
+        <BackLink />
+        <div className="flex items-center gap-6 mb-8">
+          <Skeleton className="w-24 h-24 rounded-full" />
+          <div className="flex-1">
+            <Skeleton className="h-10 w-2/3 mb-2" />
+            <Skeleton className="h-5 w-20" />
+          </div>
+        </div>
+        <div className="space-y-6">
+          <Skeleton className="h-32 w-full" />
+          <Skeleton className="h-32 w-full" />
+        </div>
+      </div>
+    );
+  }
+
+  if (error || !data) {
+    const isNotFound = error?.message === 'Author not found';
+    return (
+      <div className="max-w-3xl mx-auto text-center py-12">
+        <h1 className="text-2xl font-display font-bold mb-4">
+          {isNotFound ? 'Author Not Found' : 'Error'}
+        </h1>
+        <p className="text-default-500 mb-6">
+          {isNotFound
+            ? "The author you're looking for doesn't exist."
+            : 'Failed to load the author. Please try again.'}
+        </p>
+        <TransitionLink to="/authors" className="text-primary hover:underline">
+          &larr; All Authors
+        </TransitionLink>
+      </div>
+    );
+  }
+
+  return (
+    <div className="max-w-3xl mx-auto">
+      <BackLink />
+      <div className="flex items-center gap-6 mb-8">
+        {data.avatar && (
+          <img
+            src={data.avatar}
+            alt={data.name}
+            className="w-24 h-24 rounded-full object-cover flex-shrink-0"
+          />
+        )}
+        <div>
+          <h1 className="text-4xl font-display font-bold mb-2">{data.name}</h1>
+          <p className="text-muted">
+            {data.total} post{data.total !== 1 ? 's' : ''}
+          </p>
+        </div>
+      </div>
+      {data.bio && (
+        <p className="text-lg text-foreground/80 leading-relaxed mb-4">
+          {data.bio}
+        </p>
+      )}
+      {data.url && (
+        <p className="mb-8">
+          <a
+            href={data.url}
+            className="text-primary hover:underline"
+            rel="author"
+          >
+            {data.url}
+          </a>
+        </p>
+      )}
+      <div className="space-y-6">
+        {data.posts.map(post => (
+          <article
+            key={post.slug}
+            className="p-6 rounded-lg bg-surface hover:bg-surface-hover transition-colors border border-border"
+          >
+            <h2 className="text-2xl font-display font-semibold pb-2">
+              <TransitionLink
+                to={`/posts/${post.slug}`}
+                className="hover:text-accent transition-colors"
+              >
+                {post.title}
+              </TransitionLink>
+            </h2>
+            <div className="flex items-center gap-3 text-sm text-muted mb-3">
+              <time dateTime={post.publishDate}>
+                {new Date(post.publishDate).toLocaleDateString('en-US', {
+                  year: 'numeric',
+                  month: 'long',
+                  day: 'numeric',
+                })}
+              </time>
+              <span>&middot;</span>
+              <span>{post.readingTime} min read</span>
+            </div>
+            {post.summary && (
+              <p className="text-foreground/80 leading-relaxed">
+                {post.summary}
+              </p>
+            )}
+            {post.tags && post.tags.length > 0 && (
+              <div className="pt-3 flex flex-wrap gap-3">
+                {post.tags.map(tag => (
+                  <TagLink key={tag} tag={tag} className="text-sm" />
+                ))}
+              </div>
+            )}
+          </article>
+        ))}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,83 @@
+import {Skeleton} from '@heroui/react';
+import {useAuthors} from '../hooks/api';
+import {TransitionLink} from '../components/TransitionLink';
+import {usePageMeta} from '../hooks/usePageMeta';
+
+export function AuthorsPage() {
+  usePageMeta({
+    title: 'Authors',
+    description: 'Meet the authors writing on Therefore.',
+  });
+  const {data: authors, isLoading, error} = useAuthors();
+
+  if (isLoading) {
+    return (
+      <div className="max-w-3xl mx-auto">
+        <h1 className="text-4xl font-display font-bold mb-8">Authors</h1>
+        <div className="space-y-4">
+          <Skeleton className="h-28 w-full" />
+          <Skeleton className="h-28 w-full" />
+        </div>
+      </div>
+    );
+  }
+
+  if (error) {
+    return (
+      <div className="text-center py-12">
+        <p className="text-danger">Failed to load authors. Please try again.</p>
+      </div>
+    );
+  }
+
+  if (!authors?.length) {
+    return (
+      <div className="text-center py-12">
+        <h1 className="text-4xl font-display font-bold mb-4">Authors</h1>
+        <p className="text-default-500">No authors yet.</p>
+      </div>
+    );
+  }
+
+  return (
+    <div className="max-w-3xl mx-auto">
+      <h1 className="text-4xl font-display font-bold mb-8">Authors</h1>
+      <div className="space-y-4">
+        {authors.map(author => (
+          <div
+            key={author.id}
+            className="p-6 rounded-lg bg-surface border border-border flex items-center gap-6"
+          >
+            {author.avatar && (
+              <img
+                src={author.avatar}
+                alt={author.name}
+                className="w-16 h-16 rounded-full object-cover flex-shrink-0"
+              />
+            )}
+            <div className="min-w-0">
+              <div className="flex items-center justify-between gap-3">
+                <h2 className="text-xl font-display font-semibold">
+                  <TransitionLink
+                    to={`/authors/${author.id}`}
+                    className="hover:text-accent transition-colors"
+                  >
+                    {author.name}
+                  </TransitionLink>
+                </h2>
+                <span className="text-muted text-sm">
+                  {author.count} post{author.count !== 1 ? 's' : ''}
+                </span>
+              </div>
+              {author.bio && (
+                <p className="mt-2 text-foreground/80 leading-relaxed">
+                  {author.bio}
+                </p>
+              )}
+            </div>
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+}
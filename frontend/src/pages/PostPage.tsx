@@ -1,4 +1,4 @@
-import {useParams} from 'react-router-dom';
+import {useParams, useSearchParams} from 'react-router-dom';
 import {useEffect, useRef} from 'react';
 import {Spinner} from '@heroui/react';
 import {usePost} from '../hooks/api';
@@ -7,6 +7,8 @@ import {initTagLinks} from '../components/hydration/taglink';
 import {TransitionLink} from '../components/TransitionLink';
 import {useViewTransitionNavigate} from '../hooks/useViewTransition';
 import {TableOfContents} from '../components/TableOfContents';
+import {RelatedPosts} from '../components/RelatedPosts';
+import {SeriesNav} from '../components/SeriesNav';
 import {usePageMeta} from '../hooks/usePageMeta';
 import {useJsonLd} from '../hooks/useJsonLd';
 import {useSSGData} from '../hooks/useSSGData';
@@ -15,7 +17,11 @@ import {useSSGData} from '../hooks/useSSGData';
 export function PostPage() {
   useSSGData(); // Pre-seed query cache from SSG data
   const {slug} = useParams<{slug: string}>();
-  const {data: post, isLoading, error} = usePost(slug ?? '');
+  const [searchParams] = useSearchParams();
+  const {data: post, isLoading, error} = usePost(
+    slug ?? '',
+    searchParams.get('preview') ?? undefined,
+  );
   usePageMeta(
     post
       ? {
@@ -23,7 +29,8 @@ export function PostPage() {
           description: post.summary,
           type: 'article',
           publishedTime: post.publishDate,
-          author: post.author?.name,
+          modifiedTime: post.updatedDate,
+          author: post.authors?.map(author => author.name).join(', '),
           url: `${window.location.origin}/posts/${post.slug}`,
         }
       : {},
@@ -36,8 +43,15 @@ export function PostPage() {
           headline: post.title,
           description: post.summary,
           datePublished: post.publishDate,
-          ...(post.author?.name && {
-            author: {'@type': 'Person', name: post.author.name},
+          dateModified: post.updatedDate ?? post.publishDate,
+          ...(post.authors?.length && {
+            author: post.authors.map(author => ({
+              '@type': 'Person',
+              name: author.name,
+              ...(author.id
+                ? {url: `${window.location.origin}/authors/${author.id}`}
+                : author.url && {url: author.url}),
+            })),
           }),
           ...(post.tags?.length && {
             keywords: post.tags.join(', '),
@@ -135,12 +149,15 @@ export function PostPage() {
           className="post-content"
           dangerouslySetInnerHTML={{__html: post.htmlContent}}
         />
+
+        <SeriesNav series={post.series} nav={post.seriesNav} />
+        <RelatedPosts posts={post.related} />
       </div>
 
       {/* Table of contents - sticky in right column */}
       <aside className="hidden xl:block">
         <div className="sticky top-[calc(var(--header-height,4rem)+1rem)] max-h-[calc(100vh-var(--header-height,4rem)-2rem)] overflow-y-auto">
-          <TableOfContents containerRef={contentRef} />
+          <TableOfContents containerRef={contentRef} toc={post.toc} />
         </div>
       </aside>
     </div>
@@ -0,0 +1,123 @@
+import {useParams} from 'react-router-dom';
+import {Skeleton} from '@heroui/react';
+import {useSeriesDetail} from '../hooks/api';
+import {TransitionLink} from '../components/TransitionLink';
+import {usePageMeta} from '../hooks/usePageMeta';
+import {useSSGData} from '../hooks/useSSGData';
+
+function BackLink() {
+  return (
+    <nav className="mb-8">
+      <TransitionLink
+        to="/series"
+        className="text-default-500 hover:text-primary transition-colors"
+      >
+        &larr; All Series
+      </TransitionLink>
+    </nav>
+  );
+}
+
+export function SeriesDetailPage() {
+  useSSGData(); // Pre-seed query cache from SSG data
+  const {name} = useParams<{name: string}>();
+  const {data, isLoading, error} = useSeriesDetail(name ?? '');
+  usePageMeta(
+    data
+      ? {
+          title: data.title,
+          description:
+            data.description ??
+            `All posts in the "${data.title}" series on Therefore.`,
+          url: `${window.location.origin}/series/${encodeURIComponent(data.series)}`,
+          image: data.cover,
+        }
+      : {},
+  );
+
+  if (isLoading) {
+    return (
+      <div className="max-w-3xl mx-auto">
+        <BackLink />
+        <Skeleton className="h-10 w-2/3 mb-4" />
+        <Skeleton className="h-5 w-20 mb-8" />
+        <div className="space-y-4">
+          <Skeleton className="h-28 w-full" />
+          <Skeleton className="h-28 w-full" />
+          <Skeleton className="h-28 w-full" />
+        </div>
+      </div>
+    );
+  }
+
+  if (error || !data) {
+    const isNotFound = error?.message === 'Series not found';
+    return (
+      <div className="max-w-3xl mx-auto text-center py-12">
+        <h1 className="text-2xl font-display font-bold mb-4">
+          {isNotFound ? 'Series Not Found' : 'Error'}
+        </h1>
+        <p className="text-default-500 mb-6">
+          {isNotFound
+            ? "The series you're looking for doesn't exist."
+            : 'Failed to load the series. Please try again.'}
+        </p>
+        <TransitionLink to="/series" className="text-primary hover:underline">
+          &larr; All Series
+        </TransitionLink>
+      </div>
+    );
+  }
+
+  return (
+    <div className="max-w-3xl mx-auto">
+      <BackLink />
+      {data.cover && (
+        <img src={data.cover} alt="" className="w-full rounded-lg mb-8" />
+      )}
+      <h1 className="text-4xl font-display font-bold mb-2">{data.title}</h1>
+      <p className="text-muted mb-6">
+        {data.posts.length} part{data.posts.length !== 1 ? 's' : ''}
+      </p>
+      {data.description && (
+        <p className="text-lg text-foreground/80 leading-relaxed mb-8">
+          {data.description}
+        </p>
+      )}
+      <ol className="space-y-4">
+        {data.posts.map(post => (
+          <li
+            key={post.slug}
+            className="p-6 rounded-lg bg-surface border border-border"
+          >
+            <div className="text-sm text-muted mb-1">Part {post.part}</div>
+            <h2 className="text-xl font-display font-semibold">
+              <TransitionLink
+                to={`/posts/${post.slug}`}
+                className="hover:text-accent transition-colors"
+              >
+                {post.title}
+              </TransitionLink>
+            </h2>
+            <div className="flex items-center gap-3 text-sm text-muted mt-1">
+              <time dateTime={post.publishDate}>
+                {new Date(post.publishDate).toLocaleDateString('en-US', {
+                  year: 'numeric',
+                  month: 'long',
+                  day: 'numeric',
+                })}
+              </time>
+              <span>&middot;</span>
+              <span>{post.readingTime} min read</span>
+            </div>
+            {post.summary && (
+              <p className="mt-2 text-foreground/80 leading-relaxed">
+                {post.summary}
+              </p>
+            )}
+          </li>
+        ))}
+      </ol>
+    </div>
+  );
+}
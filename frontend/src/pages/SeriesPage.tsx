@@ -267,6 +267,7 @@ export function SeriesPage() {
           <SeriesAccordion
             key={s.series}
             series={s.series}
+            title={s.title}
             count={s.count}
             topTags={s.topTags}
             hasRecentPosts={s.hasRecentPosts}
@@ -5,3 +5,8 @@ export {TagsPage} from './TagsPage';
 export {TagPage} from './TagPage';
 export {AboutPage} from './AboutPage';
 export {SeriesPage} from './SeriesPage';
+export {SeriesDetailPage} from './SeriesDetailPage';
+export {AuthorsPage} from './AuthorsPage';
+export {AuthorPage} from './AuthorPage';
+export {ArchivePage} from './ArchivePage';
+export {ArchivePeriodPage} from './ArchivePeriodPage';
@@ -34,6 +34,10 @@ export default defineConfig({
         target: 'http://localhost:8080',
         changeOrigin: true,
       },
+      '/readyz': {
+        target: 'http://localhost:8080',
+        changeOrigin: true,
+      },
       // Proxy post bundle assets (e.g., /posts/my-post/image.jpg)
       '^/posts/[^/]+/[^/]+\\.[^/]+$': {
         target: 'http://localhost:8080',
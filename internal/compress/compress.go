@@ -1,9 +1,19 @@
-// Package compress provides Brotli compression utilities with caching.
+// Package compress serves Brotli and gzip encoded responses from a cache of
+// precompressed bodies.
 package compress
 
 import (
 	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"path"
 	"slices"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/andybalholm/brotli"
@@ -16,21 +26,60 @@ const (
 	DefaultCompression = 6
 )
 
-// CompressedAsset holds both original and compressed versions of an asset.
+// Content-Encoding values the Compressor produces.
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+// Levels for bodies compressed on a cache miss. A response is waiting on
+// them, so they trade size for speed; precompressed and warmed bodies use
+// the Compressor's level.
+const (
+	fastBrotliLevel = 4
+	fastGzipLevel   = gzip.BestSpeed
+)
+
+// maxCacheEntries bounds the cache. Past it, responses are still compressed,
+// at the fast levels in the one encoding asked for, but no longer cached, so
+// a stream of distinct bodies (search results, or every revision of a post
+// edited under --content-dir) can't grow it forever.
+const maxCacheEntries = 4096
+
+// CompressedAsset holds the original and encoded versions of an asset. An
+// encoding not yet produced is nil.
 type CompressedAsset struct {
-	Original   []byte
-	Compressed []byte
-	MimeType   string
+	Original []byte
+	Brotli   []byte
+	Gzip     []byte
+	MimeType string
 }
 
-// Compressor handles Brotli compression with caching.
+// Encoded returns the asset in the given encoding, or nil if the encoding is
+// unknown or doesn't make the asset smaller.
+func (a *CompressedAsset) Encoded(encoding string) []byte {
+	var data []byte
+	switch encoding {
+	case EncodingBrotli:
+		data = a.Brotli
+	case EncodingGzip:
+		data = a.Gzip
+	}
+	if len(data) == 0 || len(data) >= len(a.Original) {
+		return nil
+	}
+	return data
+}
+
+// Compressor handles Brotli and gzip compression with caching.
 type Compressor struct {
 	level int
 	cache map[string]*CompressedAsset
 	mu    sync.RWMutex
 }
 
-// NewCompressor creates a new Brotli compressor with the given quality level.
+// NewCompressor creates a new compressor with the given Brotli quality level.
+// Gzip always uses its best compression, since results are cached.
 func NewCompressor(level int) *Compressor {
 	if level < BestSpeed || level > BestCompression {
 		level = DefaultCompression
@@ -43,8 +92,35 @@ func NewCompressor(level int) *Compressor {
 
 // Compress compresses data using Brotli.
 func (c *Compressor) Compress(data []byte) ([]byte, error) {
+	return brotliLevel(data, c.level)
+}
+
+// Gzip compresses data using gzip.
+func (c *Compressor) Gzip(data []byte) ([]byte, error) {
+	return gzipLevel(data, gzip.BestCompression)
+}
+
+func brotliLevel(data []byte, level int) ([]byte, error) {
 	var buf bytes.Buffer
-	writer := brotli.NewWriterLevel(&buf, c.level)
+	writer := brotli.NewWriterLevel(&buf, level)
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gzipLevel(data []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
 
 	if _, err := writer.Write(data); err != nil {
 		return nil, err
@@ -57,24 +133,92 @@ func (c *Compressor) Compress(data []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// CompressAndCache compresses data and caches the result.
+// CompressAndCache compresses data in both encodings at the Compressor's
+// level and caches the result, replacing any entry for key. The result is
+// returned but not cached once the cache is full.
 func (c *Compressor) CompressAndCache(key string, data []byte, mimeType string) (*CompressedAsset, error) {
 	compressed, err := c.Compress(data)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("brotli: %w", err)
+	}
+	gzipped, err := c.Gzip(data)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
 	}
 
 	asset := &CompressedAsset{
-		Original:   data,
-		Compressed: compressed,
-		MimeType:   mimeType,
+		Original: data,
+		Brotli:   compressed,
+		Gzip:     gzipped,
+		MimeType: mimeType,
+	}
+
+	c.put(key, asset)
+	return asset, nil
+}
+
+// Encode returns data in the given encoding, or nil if the encoding is
+// unknown or doesn't make data smaller. Unless the cache has it already,
+// data is compressed in that encoding alone at a fast level, and cached
+// alongside any other encoding of it.
+func (c *Compressor) Encode(key string, data []byte, mimeType, encoding string) ([]byte, error) {
+	asset, ok := c.Get(key)
+	if !ok {
+		asset = &CompressedAsset{Original: data, MimeType: mimeType}
+	}
+
+	// Cached assets are shared with readers, so fill in a copy, merged
+	// with whatever another request cached meanwhile
+	var fill func(*CompressedAsset)
+	switch {
+	case encoding == EncodingBrotli && asset.Brotli == nil:
+		encoded, err := brotliLevel(data, fastBrotliLevel)
+		if err != nil {
+			return nil, fmt.Errorf("brotli: %w", err)
+		}
+		fill = func(a *CompressedAsset) {
+			if a.Brotli == nil {
+				a.Brotli = encoded
+			}
+		}
+	case encoding == EncodingGzip && asset.Gzip == nil:
+		encoded, err := gzipLevel(data, fastGzipLevel)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		fill = func(a *CompressedAsset) {
+			if a.Gzip == nil {
+				a.Gzip = encoded
+			}
+		}
+	default:
+		return asset.Encoded(encoding), nil
 	}
 
 	c.mu.Lock()
-	c.cache[key] = asset
-	c.mu.Unlock()
+	defer c.mu.Unlock()
+	if cached, ok := c.cache[key]; ok {
+		asset = cached
+	}
+	next := *asset
+	fill(&next)
+	c.putLocked(key, &next)
+	return next.Encoded(encoding), nil
+}
 
-	return asset, nil
+// put caches asset under key, unless the cache is full and has no entry
+// for key to replace.
+func (c *Compressor) put(key string, asset *CompressedAsset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.putLocked(key, asset)
+}
+
+// putLocked is put for callers holding c.mu.
+func (c *Compressor) putLocked(key string, asset *CompressedAsset) {
+	if _, ok := c.cache[key]; ok || len(c.cache) < maxCacheEntries {
+		c.cache[key] = asset
+	}
 }
 
 // Get retrieves a cached compressed asset.
@@ -86,7 +230,44 @@ func (c *Compressor) Get(key string) (*CompressedAsset, bool) {
 	return asset, ok
 }
 
+// Precompress caches every compressible file in fsys, so the first request
+// for each is served from the cache. Returns the number of files cached.
+func (c *Compressor) Precompress(fsys fs.FS) (int, error) {
+	count := 0
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		mimeType := mime.TypeByExtension(path.Ext(name))
+		if !ShouldCompress(mimeType) {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if len(data) < minSize {
+			return nil
+		}
+		if _, err := c.CompressAndCache(Key(data), data, mimeType); err != nil {
+			return fmt.Errorf("compressing %s: %w", name, err)
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// Key returns the cache key for a response body. Keying by content rather
+// than URL means a body is compressed once however many URLs serve it, and a
+// changed body never hits a stale entry.
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // ShouldCompress returns true if the content type should be compressed.
+// Parameters such as charset are ignored.
 func ShouldCompress(contentType string) bool {
 	compressible := []string{
 		"text/html",
@@ -94,16 +275,63 @@ func ShouldCompress(contentType string) bool {
 		"text/javascript",
 		"application/javascript",
 		"application/json",
+		"application/manifest+json",
 		"image/svg+xml",
 		"text/plain",
 		"text/xml",
 		"application/xml",
+		"application/rss+xml",
+		"application/atom+xml",
 	}
 
-	return slices.Contains(compressible, contentType)
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return slices.Contains(compressible, strings.ToLower(strings.TrimSpace(mediaType)))
 }
 
 // AcceptsBrotli checks if the client accepts Brotli encoding.
 func AcceptsBrotli(acceptEncoding string) bool {
-	return bytes.Contains([]byte(acceptEncoding), []byte("br"))
+	return quality(acceptEncoding, EncodingBrotli) > 0
+}
+
+// Negotiate returns the encoding to serve for an Accept-Encoding header:
+// EncodingBrotli or EncodingGzip, whichever has the higher q-value with
+// Brotli winning ties, or "" if the client accepts neither.
+func Negotiate(acceptEncoding string) string {
+	br := quality(acceptEncoding, EncodingBrotli)
+	gz := quality(acceptEncoding, EncodingGzip)
+	switch {
+	case br > 0 && br >= gz:
+		return EncodingBrotli
+	case gz > 0:
+		return EncodingGzip
+	}
+	return ""
+}
+
+// quality returns the q-value an Accept-Encoding header gives an encoding,
+// falling back to the "*" entry and then to 0 if the header doesn't list it.
+func quality(acceptEncoding, encoding string) float64 {
+	wildcard := 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != encoding && coding != "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && key == "q" {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		if coding == encoding {
+			return q
+		}
+		wildcard = q
+	}
+	return wildcard
 }
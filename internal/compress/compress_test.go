@@ -0,0 +1,251 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v5"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip, deflate, br", EncodingBrotli},
+		{"gzip", EncodingGzip},
+		{"gzip;q=1.0, br;q=0.5", EncodingGzip},
+		{"br;q=0, gzip", EncodingGzip},
+		{"br;q=0, gzip;q=0", ""},
+		{"identity", ""},
+		{"*", EncodingBrotli},
+		{"*;q=0.5, br;q=0", EncodingGzip},
+		{"BR", EncodingBrotli},
+		{"abr, xgzip", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestAcceptsBrotli(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"gzip, br", true},
+		{"br;q=0", false},
+		{"gzip", false},
+		{"abr", false},
+	}
+
+	for _, tt := range tests {
+		if got := AcceptsBrotli(tt.header); got != tt.want {
+			t.Errorf("AcceptsBrotli(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestShouldCompress(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"text/html", true},
+		{"text/html; charset=utf-8", true},
+		{"application/json", true},
+		{"application/atom+xml; charset=utf-8", true},
+		{"image/png", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ShouldCompress(tt.contentType); got != tt.want {
+			t.Errorf("ShouldCompress(%q) = %v, want %v", tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestCompressor_Precompress(t *testing.T) {
+	page := []byte(strings.Repeat("<p>Therefore</p>\n", 100))
+	fsys := fstest.MapFS{
+		"index.html":         {Data: page},
+		"assets/app.js":      {Data: []byte(strings.Repeat("console.log(1);\n", 100))},
+		"assets/logo.png":    {Data: bytes.Repeat([]byte{0}, 1000)},
+		"assets/tiny.css":    {Data: []byte("body{}")},
+		"posts/a/index.html": {Data: page},
+	}
+
+	c := NewCompressor(DefaultCompression)
+	count, err := c.Precompress(fsys)
+	if err != nil {
+		t.Fatalf("Precompress() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Precompress() = %d, want 3 (images and tiny files skipped)", count)
+	}
+	if _, ok := c.Get(Key(page)); !ok {
+		t.Error("index.html not cached by content")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"title":"Therefore"}`, 50)
+	c := NewCompressor(DefaultCompression)
+
+	e := echo.New()
+	e.Use(Middleware(c))
+	e.GET("/json", func(c *echo.Context) error {
+		return c.Blob(http.StatusOK, "application/json", []byte(body))
+	})
+	e.GET("/small", func(c *echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	e.GET("/png", func(c *echo.Context) error {
+		return c.Blob(http.StatusOK, "image/png", []byte(body))
+	})
+
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set(echo.HeaderAcceptEncoding, acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("brotli", func(t *testing.T) {
+		rec := serve("/json", "gzip, br")
+		if got := rec.Header().Get(echo.HeaderContentEncoding); got != EncodingBrotli {
+			t.Fatalf("Content-Encoding = %q, want br", got)
+		}
+		decoded, err := io.ReadAll(brotli.NewReader(rec.Body))
+		if err != nil {
+			t.Fatalf("decoding brotli: %v", err)
+		}
+		if string(decoded) != body {
+			t.Error("decoded body doesn't match")
+		}
+		asset, ok := c.Get(Key([]byte(body)))
+		if !ok {
+			t.Fatal("response not cached")
+		}
+		if asset.Gzip != nil {
+			t.Error("gzip compressed on a brotli miss")
+		}
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		rec := serve("/json", "gzip")
+		if got := rec.Header().Get(echo.HeaderContentEncoding); got != EncodingGzip {
+			t.Fatalf("Content-Encoding = %q, want gzip", got)
+		}
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("opening gzip: %v", err)
+		}
+		decoded, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("decoding gzip: %v", err)
+		}
+		if string(decoded) != body {
+			t.Error("decoded body doesn't match")
+		}
+		if asset, _ := c.Get(Key([]byte(body))); asset.Brotli == nil || asset.Gzip == nil {
+			t.Error("cache doesn't hold both encodings after a request for each")
+		}
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+	}{
+		{"identity", "/json", ""},
+		{"refused", "/json", "br;q=0, gzip;q=0"},
+		{"small body", "/small", "br"},
+		{"not compressible", "/png", "br"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.path, tt.acceptEncoding)
+			if got := rec.Header().Get(echo.HeaderContentEncoding); got != "" {
+				t.Errorf("Content-Encoding = %q, want none", got)
+			}
+			if got := rec.Header().Get(echo.HeaderVary); got != echo.HeaderAcceptEncoding {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", rec.Code)
+			}
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		rec := serve("/missing", "br")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestMiddleware_Streams(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(NewCompressor(DefaultCompression)))
+
+	rec := httptest.NewRecorder()
+	e.GET("/png", func(c *echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, "image/png")
+		if _, err := c.Response().Write(bytes.Repeat([]byte{0}, 1000)); err != nil {
+			return err
+		}
+		// The body is on its way before the handler returns
+		if rec.Body.Len() != 1000 {
+			t.Errorf("body written so far = %d bytes, want 1000", rec.Body.Len())
+		}
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/png", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "br")
+	e.ServeHTTP(rec, req)
+	if got := rec.Header().Get(echo.HeaderContentEncoding); got != "" {
+		t.Errorf("Content-Encoding = %q, want none", got)
+	}
+}
+
+func TestWarm(t *testing.T) {
+	body := strings.Repeat("<p>Therefore</p>\n", 100)
+	c := NewCompressor(DefaultCompression)
+
+	e := echo.New()
+	e.Use(Middleware(c))
+	e.GET("/", func(c *echo.Context) error {
+		return c.HTML(http.StatusOK, body)
+	})
+
+	Warm(e, []string{"/"})
+	asset, ok := c.Get(Key([]byte(body)))
+	if !ok {
+		t.Fatal("warmed response not cached")
+	}
+	best, err := c.Compress([]byte(body))
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	if !bytes.Equal(asset.Brotli, best) || asset.Gzip == nil {
+		t.Error("warmed response not cached in both encodings at the compressor's level")
+	}
+}
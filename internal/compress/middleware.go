@@ -0,0 +1,204 @@
+package compress
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v5"
+)
+
+// minSize is the smallest body worth compressing. Below it the encoding
+// overhead eats most of the saving.
+const minSize = 256
+
+// Middleware returns an Echo middleware that serves compressible responses
+// Brotli or gzip encoded, whichever the client prefers, and unencoded to
+// clients that accept neither. Bodies are looked up in the cache by content,
+// so files cached by Precompress and responses seen before aren't compressed
+// again; anything else is compressed on first sight, in the one encoding
+// asked for at a fast level, and cached. Responses that won't be encoded,
+// such as images, are streamed rather than held in memory.
+func Middleware(c *Compressor) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx *echo.Context) error {
+			res := ctx.Response()
+			res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+
+			encoding := Negotiate(ctx.Request().Header.Get(echo.HeaderAcceptEncoding))
+			if encoding == "" {
+				return next(ctx)
+			}
+
+			bw := &bufferedWriter{ResponseWriter: res}
+			ctx.SetResponse(bw)
+			defer ctx.SetResponse(res)
+
+			err := next(ctx)
+			bw.finish(c, encoding, warming(ctx.Request().Context()))
+			return err
+		}
+	}
+}
+
+// bufferedWriter holds back the status and body of a compressible response
+// until the handler returns, so the whole body can be looked up in the
+// cache. Any other response is passed straight through.
+type bufferedWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	streaming   bool
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.wroteHeader {
+		return
+	}
+	w.code = code
+	w.wroteHeader = true
+	if !w.encodable() {
+		w.streaming = true
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// Flush gives up on compression: a handler that flushes wants its output
+// delivered as it's written, not held until it returns.
+func (w *bufferedWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		if w.wroteHeader {
+			w.ResponseWriter.WriteHeader(w.code)
+		}
+		_, _ = w.buf.WriteTo(w.ResponseWriter)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *bufferedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// encodable reports whether the status and headers allow the response to be
+// encoded. Partial and already-encoded responses pass through untouched. A
+// response without a Content-Type might be encoded once it's sniffed from
+// the body.
+func (w *bufferedWriter) encodable() bool {
+	header := w.Header()
+	if w.code != http.StatusOK || header.Get(echo.HeaderContentEncoding) != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := header.Get(echo.HeaderContentType)
+	return contentType == "" || ShouldCompress(contentType)
+}
+
+// finish writes the buffered response, encoded if it's compressible.
+// Warm-up responses are compressed in both encodings at the Compressor's
+// level.
+func (w *bufferedWriter) finish(c *Compressor, encoding string, warm bool) {
+	if w.streaming || !w.wroteHeader {
+		// Nothing was written: leave the response to the error handler
+		return
+	}
+
+	body := w.buf.Bytes()
+	header := w.Header()
+	if header.Get(echo.HeaderContentType) == "" && len(body) > 0 {
+		header.Set(echo.HeaderContentType, http.DetectContentType(body))
+	}
+
+	if encoded := w.encode(c, encoding, body, warm); encoded != nil {
+		header.Set(echo.HeaderContentEncoding, encoding)
+		header.Set(echo.HeaderContentLength, strconv.Itoa(len(encoded)))
+		body = encoded
+	}
+
+	w.ResponseWriter.WriteHeader(w.code)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// encode returns body in the given encoding, or nil if the response should
+// be sent as is.
+func (w *bufferedWriter) encode(c *Compressor, encoding string, body []byte, warm bool) []byte {
+	if len(body) < minSize || !w.encodable() {
+		return nil
+	}
+	contentType := w.Header().Get(echo.HeaderContentType)
+
+	key := Key(body)
+	if !warm {
+		encoded, err := c.Encode(key, body, contentType, encoding)
+		if err != nil {
+			slog.Warn("Compressing response failed", "error", err)
+			return nil
+		}
+		return encoded
+	}
+
+	asset, ok := c.Get(key)
+	if !ok || asset.Brotli == nil || asset.Gzip == nil {
+		var err error
+		if asset, err = c.CompressAndCache(key, body, contentType); err != nil {
+			slog.Warn("Compressing response failed", "error", err)
+			return nil
+		}
+	}
+	return asset.Encoded(encoding)
+}
+
+// WarmUserAgent is the User-Agent of the requests Warm makes.
+const WarmUserAgent = "therefore-warmup"
+
+// warmKey marks the context of a request made by Warm. Unlike the
+// User-Agent, clients can't set it.
+type warmKey struct{}
+
+func warming(ctx context.Context) bool {
+	warm, _ := ctx.Value(warmKey{}).(bool)
+	return warm
+}
+
+// Warm requests each path from h with compression accepted, so responses
+// that pass through Middleware are cached, in both encodings at the
+// Compressor's level, before the first real request.
+func Warm(h http.Handler, paths []string) {
+	ctx := context.WithValue(context.Background(), warmKey{}, true)
+	for _, p := range paths {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p, nil)
+		if err != nil {
+			slog.Warn("Skipping compression warm-up path", "path", p, "error", err)
+			continue
+		}
+		req.RequestURI = p
+		req.Header.Set(echo.HeaderAcceptEncoding, EncodingBrotli+", "+EncodingGzip)
+		req.Header.Set("User-Agent", WarmUserAgent)
+		h.ServeHTTP(&discardWriter{header: http.Header{}}, req)
+	}
+}
+
+// discardWriter is a ResponseWriter that throws the response away.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
@@ -2,8 +2,13 @@
 package content
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,8 +20,45 @@ import (
 // mockRenderer implements Renderer for testing.
 type mockRenderer struct{}
 
-func (m *mockRenderer) Render(raw string, _ *renderer.RenderContext) (string, error) {
-	return "<p>" + raw + "</p>", nil
+func (m *mockRenderer) RenderDocument(raw string, _ *renderer.RenderContext) (renderer.Document, error) {
+	return renderer.Document{HTML: "<p>" + raw + "</p>"}, nil
+}
+
+// testStore is the API of the stores that load posts from a content
+// filesystem.
+type testStore interface {
+	ContentStore
+	Reload(paths ...string) error
+	Watch(ctx context.Context, dir string) error
+	Schedule(ctx context.Context)
+	Stats() Stats
+}
+
+// storeOpener loads a store from a content filesystem.
+type storeOpener func(fs afero.Fs) (testStore, error)
+
+// forEachStore runs test against every store that loads posts from a
+// content filesystem, so that they behave the same.
+func forEachStore(t *testing.T, test func(t *testing.T, open storeOpener)) {
+	t.Run("embedded", func(t *testing.T) {
+		test(t, func(fs afero.Fs) (testStore, error) {
+			store, err := NewEmbeddedStore(fs, &mockRenderer{})
+			if err != nil {
+				return nil, err
+			}
+			return store, nil
+		})
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, func(fs afero.Fs) (testStore, error) {
+			store, err := NewSQLiteStore("", fs, &mockRenderer{})
+			if err != nil {
+				return nil, err
+			}
+			t.Cleanup(func() { _ = store.Close() })
+			return store, nil
+		})
+	})
 }
 
 func TestParseFrontmatter(t *testing.T) {
@@ -61,9 +103,9 @@ no closing delimiter`,
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			meta, content, err := parseFrontmatter([]byte(tt.content))
+			meta, content, err := ParseFrontmatter([]byte(tt.content))
 			if (err != nil) != tt.wantErr {
-				t.Errorf("parseFrontmatter() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("ParseFrontmatter() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if tt.wantErr {
@@ -86,49 +128,52 @@ no closing delimiter`,
 	}
 }
 
-func TestEmbeddedStore_GetPost(t *testing.T) {
-	fs := afero.NewMemMapFs()
-	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+func TestStore_GetPost(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
 
-	_ = afero.WriteFile(fs, "test-post.md", []byte(`---
+		_ = afero.WriteFile(fs, "test-post.md", []byte(`---
 title: Test Post
 slug: test-post
 publishDate: `+past+`
 ---
 Content here.`), 0644)
 
-	store, err := NewEmbeddedStore(fs, &mockRenderer{})
-	if err != nil {
-		t.Fatalf("NewEmbeddedStore() error = %v", err)
-	}
-
-	ctx := context.Background()
-
-	// Test successful get
-	post, err := store.GetPost(ctx, "test-post")
-	if err != nil {
-		t.Errorf("GetPost() error = %v", err)
-	}
-	if post.Meta.Title != "Test Post" {
-		t.Errorf("Title = %q, want %q", post.Meta.Title, "Test Post")
-	}
-	if post.HTMLContent != "<p>Content here.</p>" {
-		t.Errorf("HTMLContent = %q, want %q", post.HTMLContent, "<p>Content here.</p>")
-	}
-
-	// Test not found
-	_, err = store.GetPost(ctx, "nonexistent")
-	if !errors.Is(err, ErrPostNotFound) {
-		t.Errorf("GetPost(nonexistent) error = %v, want ErrPostNotFound", err)
-	}
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		// Test successful get
+		post, err := store.GetPost(ctx, "test-post")
+		if err != nil {
+			t.Errorf("GetPost() error = %v", err)
+		}
+		if post.Meta.Title != "Test Post" {
+			t.Errorf("Title = %q, want %q", post.Meta.Title, "Test Post")
+		}
+		if post.HTMLContent != "<p>Content here.</p>" {
+			t.Errorf("HTMLContent = %q, want %q", post.HTMLContent, "<p>Content here.</p>")
+		}
+
+		// Test not found
+		_, err = store.GetPost(ctx, "nonexistent")
+		if !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetPost(nonexistent) error = %v, want ErrPostNotFound", err)
+		}
+	})
 }
 
-func TestEmbeddedStore_DraftFiltering(t *testing.T) {
-	fs := afero.NewMemMapFs()
-	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+func TestStore_DraftFiltering(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
 
-	// Published post
-	_ = afero.WriteFile(fs, "published.md", []byte(`---
+		// Published post
+		_ = afero.WriteFile(fs, "published.md", []byte(`---
 title: Published
 slug: published
 publishDate: `+past+`
@@ -136,8 +181,8 @@ draft: false
 ---
 Published content.`), 0644)
 
-	// Draft post
-	_ = afero.WriteFile(fs, "draft.md", []byte(`---
+		// Draft post
+		_ = afero.WriteFile(fs, "draft.md", []byte(`---
 title: Draft
 slug: draft
 publishDate: `+past+`
@@ -145,75 +190,96 @@ draft: true
 ---
 Draft content.`), 0644)
 
-	store, err := NewEmbeddedStore(fs, &mockRenderer{})
-	if err != nil {
-		t.Fatalf("NewEmbeddedStore() error = %v", err)
-	}
-
-	ctx := context.Background()
-
-	// Published should be found
-	_, err = store.GetPost(ctx, "published")
-	if err != nil {
-		t.Errorf("GetPost(published) error = %v", err)
-	}
-
-	// Draft should not be found
-	_, err = store.GetPost(ctx, "draft")
-	if !errors.Is(err, ErrPostNotFound) {
-		t.Errorf("GetPost(draft) error = %v, want ErrPostNotFound", err)
-	}
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		// Published should be found
+		_, err = store.GetPost(ctx, "published")
+		if err != nil {
+			t.Errorf("GetPost(published) error = %v", err)
+		}
+
+		// Draft should not be found
+		_, err = store.GetPost(ctx, "draft")
+		if !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetPost(draft) error = %v, want ErrPostNotFound", err)
+		}
+
+		// Draft is available for preview, published post is not
+		if post, err := store.GetPreview(ctx, "draft"); err != nil || post.Meta.Title != "Draft" {
+			t.Errorf("GetPreview(draft) = %v, %v, want Draft", post, err)
+		}
+		if _, err := store.GetPreview(ctx, "published"); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetPreview(published) error = %v, want ErrPostNotFound", err)
+		}
+
+		// IncludeDraft lists drafts alongside published posts
+		posts, total, err := store.ListPosts(ctx, ListOptions{IncludeDraft: true})
+		if err != nil {
+			t.Fatalf("ListPosts(IncludeDraft) error = %v", err)
+		}
+		if total != 2 || len(posts) != 2 {
+			t.Errorf("ListPosts(IncludeDraft) total = %d, len = %d, want 2", total, len(posts))
+		}
+	})
 }
 
-func TestEmbeddedStore_FutureDateFiltering(t *testing.T) {
-	fs := afero.NewMemMapFs()
-	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
-	future := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+func TestStore_FutureDateFiltering(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+		future := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
 
-	// Past post
-	_ = afero.WriteFile(fs, "past.md", []byte(`---
+		// Past post
+		_ = afero.WriteFile(fs, "past.md", []byte(`---
 title: Past Post
 slug: past-post
 publishDate: `+past+`
 ---
 Past content.`), 0644)
 
-	// Future post
-	_ = afero.WriteFile(fs, "future.md", []byte(`---
+		// Future post
+		_ = afero.WriteFile(fs, "future.md", []byte(`---
 title: Future Post
 slug: future-post
 publishDate: `+future+`
 ---
 Future content.`), 0644)
 
-	store, err := NewEmbeddedStore(fs, &mockRenderer{})
-	if err != nil {
-		t.Fatalf("NewEmbeddedStore() error = %v", err)
-	}
-
-	ctx := context.Background()
-
-	// Past should be found
-	_, err = store.GetPost(ctx, "past-post")
-	if err != nil {
-		t.Errorf("GetPost(past-post) error = %v", err)
-	}
-
-	// Future should not be found
-	_, err = store.GetPost(ctx, "future-post")
-	if !errors.Is(err, ErrPostNotFound) {
-		t.Errorf("GetPost(future-post) error = %v, want ErrPostNotFound", err)
-	}
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		// Past should be found
+		_, err = store.GetPost(ctx, "past-post")
+		if err != nil {
+			t.Errorf("GetPost(past-post) error = %v", err)
+		}
+
+		// Future should not be found
+		_, err = store.GetPost(ctx, "future-post")
+		if !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetPost(future-post) error = %v, want ErrPostNotFound", err)
+		}
+	})
 }
 
-func TestEmbeddedStore_ListPosts(t *testing.T) {
-	fs := afero.NewMemMapFs()
-	now := time.Now()
+func TestStore_ListPosts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		now := time.Now()
 
-	// Create posts with different dates
-	for i, name := range []string{"third", "first", "second"} {
-		date := now.Add(time.Duration(-i*24) * time.Hour).Format(time.RFC3339)
-		_ = afero.WriteFile(fs, name+".md", []byte(`---
+		// Create posts with different dates
+		for i, name := range []string{"third", "first", "second"} {
+			date := now.Add(time.Duration(-i*24) * time.Hour).Format(time.RFC3339)
+			_ = afero.WriteFile(fs, name+".md", []byte(`---
 title: `+name+`
 slug: `+name+`
 publishDate: `+date+`
@@ -221,60 +287,62 @@ tags:
   - test
 ---
 Content.`), 0644)
-	}
-
-	store, err := NewEmbeddedStore(fs, &mockRenderer{})
-	if err != nil {
-		t.Fatalf("NewEmbeddedStore() error = %v", err)
-	}
-
-	ctx := context.Background()
-
-	// Test sorting (newest first)
-	posts, total, err := store.ListPosts(ctx, ListOptions{})
-	if err != nil {
-		t.Fatalf("ListPosts() error = %v", err)
-	}
-	if len(posts) != 3 {
-		t.Fatalf("len(posts) = %d, want 3", len(posts))
-	}
-	if total != 3 {
-		t.Errorf("total = %d, want 3", total)
-	}
-	if posts[0].Meta.Slug != "third" {
-		t.Errorf("posts[0].Slug = %q, want %q", posts[0].Meta.Slug, "third")
-	}
-
-	// Test limit
-	posts, total, err = store.ListPosts(ctx, ListOptions{Limit: 2})
-	if err != nil {
-		t.Fatalf("ListPosts(limit=2) error = %v", err)
-	}
-	if len(posts) != 2 {
-		t.Errorf("len(posts) = %d, want 2", len(posts))
-	}
-	if total != 3 {
-		t.Errorf("total = %d, want 3 (total before pagination)", total)
-	}
-
-	// Test offset
-	posts, total, err = store.ListPosts(ctx, ListOptions{Offset: 1})
-	if err != nil {
-		t.Fatalf("ListPosts(offset=1) error = %v", err)
-	}
-	if len(posts) != 2 {
-		t.Errorf("len(posts) = %d, want 2", len(posts))
-	}
-	if total != 3 {
-		t.Errorf("total = %d, want 3 (total before pagination)", total)
-	}
+		}
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		// Test sorting (newest first)
+		posts, total, err := store.ListPosts(ctx, ListOptions{})
+		if err != nil {
+			t.Fatalf("ListPosts() error = %v", err)
+		}
+		if len(posts) != 3 {
+			t.Fatalf("len(posts) = %d, want 3", len(posts))
+		}
+		if total != 3 {
+			t.Errorf("total = %d, want 3", total)
+		}
+		if posts[0].Meta.Slug != "third" {
+			t.Errorf("posts[0].Slug = %q, want %q", posts[0].Meta.Slug, "third")
+		}
+
+		// Test limit
+		posts, total, err = store.ListPosts(ctx, ListOptions{Limit: 2})
+		if err != nil {
+			t.Fatalf("ListPosts(limit=2) error = %v", err)
+		}
+		if len(posts) != 2 {
+			t.Errorf("len(posts) = %d, want 2", len(posts))
+		}
+		if total != 3 {
+			t.Errorf("total = %d, want 3 (total before pagination)", total)
+		}
+
+		// Test offset
+		posts, total, err = store.ListPosts(ctx, ListOptions{Offset: 1})
+		if err != nil {
+			t.Fatalf("ListPosts(offset=1) error = %v", err)
+		}
+		if len(posts) != 2 {
+			t.Errorf("len(posts) = %d, want 2", len(posts))
+		}
+		if total != 3 {
+			t.Errorf("total = %d, want 3 (total before pagination)", total)
+		}
+	})
 }
 
-func TestEmbeddedStore_TagFiltering(t *testing.T) {
-	fs := afero.NewMemMapFs()
-	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+func TestStore_TagFiltering(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
 
-	_ = afero.WriteFile(fs, "philosophy.md", []byte(`---
+		_ = afero.WriteFile(fs, "philosophy.md", []byte(`---
 title: Philosophy Post
 slug: philosophy
 publishDate: `+past+`
@@ -283,7 +351,7 @@ tags:
 ---
 Content.`), 0644)
 
-	_ = afero.WriteFile(fs, "theology.md", []byte(`---
+		_ = afero.WriteFile(fs, "theology.md", []byte(`---
 title: Theology Post
 slug: theology
 publishDate: `+past+`
@@ -292,7 +360,7 @@ tags:
 ---
 Content.`), 0644)
 
-	_ = afero.WriteFile(fs, "both.md", []byte(`---
+		_ = afero.WriteFile(fs, "both.md", []byte(`---
 title: Both Post
 slug: both
 publishDate: `+past+`
@@ -302,55 +370,121 @@ tags:
 ---
 Content.`), 0644)
 
-	store, err := NewEmbeddedStore(fs, &mockRenderer{})
-	if err != nil {
-		t.Fatalf("NewEmbeddedStore() error = %v", err)
-	}
-
-	ctx := context.Background()
-
-	// Filter by philosophy tag
-	posts, total, err := store.ListPosts(ctx, ListOptions{Tag: "philosophy"})
-	if err != nil {
-		t.Fatalf("ListPosts(tag=philosophy) error = %v", err)
-	}
-	if len(posts) != 2 {
-		t.Errorf("len(posts) = %d, want 2", len(posts))
-	}
-	if total != 2 {
-		t.Errorf("total = %d, want 2", total)
-	}
-
-	// Filter by theology tag
-	posts, total, err = store.ListPosts(ctx, ListOptions{Tag: "theology"})
-	if err != nil {
-		t.Fatalf("ListPosts(tag=theology) error = %v", err)
-	}
-	if len(posts) != 2 {
-		t.Errorf("len(posts) = %d, want 2", len(posts))
-	}
-	if total != 2 {
-		t.Errorf("total = %d, want 2", total)
-	}
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		// Filter by philosophy tag
+		posts, total, err := store.ListPosts(ctx, ListOptions{Tag: "philosophy"})
+		if err != nil {
+			t.Fatalf("ListPosts(tag=philosophy) error = %v", err)
+		}
+		if len(posts) != 2 {
+			t.Errorf("len(posts) = %d, want 2", len(posts))
+		}
+		if total != 2 {
+			t.Errorf("total = %d, want 2", total)
+		}
+
+		// Filter by theology tag
+		posts, total, err = store.ListPosts(ctx, ListOptions{Tag: "theology"})
+		if err != nil {
+			t.Fatalf("ListPosts(tag=theology) error = %v", err)
+		}
+		if len(posts) != 2 {
+			t.Errorf("len(posts) = %d, want 2", len(posts))
+		}
+		if total != 2 {
+			t.Errorf("total = %d, want 2", total)
+		}
+
+		// Filter by nonexistent tag
+		posts, total, err = store.ListPosts(ctx, ListOptions{Tag: "nonexistent"})
+		if err != nil {
+			t.Fatalf("ListPosts(tag=nonexistent) error = %v", err)
+		}
+		if len(posts) != 0 {
+			t.Errorf("len(posts) = %d, want 0", len(posts))
+		}
+		if total != 0 {
+			t.Errorf("total = %d, want 0", total)
+		}
+	})
+}
 
-	// Filter by nonexistent tag
-	posts, total, err = store.ListPosts(ctx, ListOptions{Tag: "nonexistent"})
-	if err != nil {
-		t.Fatalf("ListPosts(tag=nonexistent) error = %v", err)
-	}
-	if len(posts) != 0 {
-		t.Errorf("len(posts) = %d, want 0", len(posts))
-	}
-	if total != 0 {
-		t.Errorf("total = %d, want 0", total)
-	}
+func TestStore_ListFilters(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		_ = afero.WriteFile(fs, "config.yaml", []byte("authors:\n  alice:\n    name: Alice\n"), 0644)
+		for _, p := range []struct {
+			slug, date, frontmatter string
+			words                   int
+		}{
+			{"ethics-2023", "2023-06-01", "tags: [philosophy, ethics]\nauthors: [alice]\n", 1},
+			{"ethics-2024", "2024-03-15", "tags: [philosophy, ethics]\n", 400},
+			{"logic-2024", "2024-05-20", "tags: [philosophy, logic]\nauthors: [alice]\n", 1000},
+			{"grace-2024", "2024-12-31", "tags: [theology, ethics]\nauthors: [alice]\n", 600},
+		} {
+			_ = afero.WriteFile(fs, p.slug+".md", []byte("---\ntitle: "+p.slug+"\nslug: "+p.slug+
+				"\npublishDate: "+p.date+"T12:00:00Z\n"+p.frontmatter+"---\n"+strings.Repeat("word ", p.words)), 0644)
+		}
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+		ctx := context.Background()
+
+		year := func(y int) (time.Time, time.Time) {
+			return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(y, 12, 31, 23, 59, 59, 0, time.UTC)
+		}
+		from2024, to2024 := year(2024)
+
+		tests := []struct {
+			name string
+			opts ListOptions
+			want string
+		}{
+			{"tag intersection", ListOptions{Tags: []string{"philosophy", "ethics"}}, "ethics-2024 ethics-2023"},
+			{"tag and tags", ListOptions{Tag: "ethics", Tags: []string{"theology"}}, "grace-2024"},
+			{"from", ListOptions{From: from2024}, "grace-2024 logic-2024 ethics-2024"},
+			{"to", ListOptions{To: time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)}, "ethics-2024 ethics-2023"},
+			{"range and tag", ListOptions{Tag: "philosophy", From: from2024, To: to2024}, "logic-2024 ethics-2024"},
+			{"author and range", ListOptions{Author: "alice", From: from2024}, "grace-2024 logic-2024"},
+			{"nothing matches", ListOptions{Tags: []string{"logic", "theology"}}, ""},
+			{"any tag", ListOptions{Tags: []string{"logic", "theology"}, TagMode: TagMatchAny}, "grace-2024 logic-2024"},
+			{"any tag and tag", ListOptions{Tag: "ethics", Tags: []string{"logic", "theology"}, TagMode: TagMatchAny}, "grace-2024"},
+			{"excluded tags", ListOptions{ExcludeTags: []string{"logic", "theology"}}, "ethics-2024 ethics-2023"},
+			{"tag and excluded tag", ListOptions{Tag: "philosophy", ExcludeTags: []string{"ethics"}}, "logic-2024"},
+			{"min reading time", ListOptions{MinReadingTime: 2}, "grace-2024 logic-2024 ethics-2024"},
+			{"max reading time", ListOptions{MaxReadingTime: 3}, "grace-2024 ethics-2024 ethics-2023"},
+			{"reading time range", ListOptions{MinReadingTime: 3, MaxReadingTime: 5}, "grace-2024 logic-2024"},
+		}
+		for _, tt := range tests {
+			posts, total, err := store.ListPosts(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("%s: ListPosts() error = %v", tt.name, err)
+			}
+			var got []string
+			for _, post := range posts {
+				got = append(got, post.Meta.Slug)
+			}
+			if strings.Join(got, " ") != tt.want || total != len(got) {
+				t.Errorf("%s: ListPosts() = %v (total %d), want %s", tt.name, got, total, tt.want)
+			}
+		}
+	})
 }
 
-func TestEmbeddedStore_GetTags(t *testing.T) {
-	fs := afero.NewMemMapFs()
-	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+func TestStore_GetTags(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
 
-	_ = afero.WriteFile(fs, "post1.md", []byte(`---
+		_ = afero.WriteFile(fs, "post1.md", []byte(`---
 title: Post 1
 slug: post1
 publishDate: `+past+`
@@ -360,7 +494,7 @@ tags:
 ---
 Content.`), 0644)
 
-	_ = afero.WriteFile(fs, "post2.md", []byte(`---
+		_ = afero.WriteFile(fs, "post2.md", []byte(`---
 title: Post 2
 slug: post2
 publishDate: `+past+`
@@ -369,62 +503,66 @@ tags:
 ---
 Content.`), 0644)
 
-	store, err := NewEmbeddedStore(fs, &mockRenderer{})
-	if err != nil {
-		t.Fatalf("NewEmbeddedStore() error = %v", err)
-	}
-
-	ctx := context.Background()
-
-	tags, err := store.GetTags(ctx)
-	if err != nil {
-		t.Fatalf("GetTags() error = %v", err)
-	}
-	if len(tags) != 2 {
-		t.Fatalf("len(tags) = %d, want 2", len(tags))
-	}
-
-	// Tags should be sorted by count (descending), then name
-	if tags[0].Tag != "common" || tags[0].Count != 2 {
-		t.Errorf("tags[0] = %+v, want {Tag: common, Count: 2}", tags[0])
-	}
-	if tags[1].Tag != "rare" || tags[1].Count != 1 {
-		t.Errorf("tags[1] = %+v, want {Tag: rare, Count: 1}", tags[1])
-	}
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		tags, err := store.GetTags(ctx)
+		if err != nil {
+			t.Fatalf("GetTags() error = %v", err)
+		}
+		if len(tags) != 2 {
+			t.Fatalf("len(tags) = %d, want 2", len(tags))
+		}
+
+		// Tags should be sorted by count (descending), then name
+		if tags[0].Tag != "common" || tags[0].Count != 2 {
+			t.Errorf("tags[0] = %+v, want {Tag: common, Count: 2}", tags[0])
+		}
+		if tags[1].Tag != "rare" || tags[1].Count != 1 {
+			t.Errorf("tags[1] = %+v, want {Tag: rare, Count: 1}", tags[1])
+		}
+	})
 }
 
-func TestEmbeddedStore_DefaultSlug(t *testing.T) {
-	fs := afero.NewMemMapFs()
-	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+func TestStore_DefaultSlug(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
 
-	// Post without explicit slug - should use filename
-	_ = afero.WriteFile(fs, "my-post-name.md", []byte(`---
+		// Post without explicit slug - should use filename
+		_ = afero.WriteFile(fs, "my-post-name.md", []byte(`---
 title: My Post
 publishDate: `+past+`
 ---
 Content.`), 0644)
 
-	store, err := NewEmbeddedStore(fs, &mockRenderer{})
-	if err != nil {
-		t.Fatalf("NewEmbeddedStore() error = %v", err)
-	}
-
-	ctx := context.Background()
-
-	post, err := store.GetPost(ctx, "my-post-name")
-	if err != nil {
-		t.Errorf("GetPost(my-post-name) error = %v", err)
-	}
-	if post.Meta.Slug != "my-post-name" {
-		t.Errorf("Slug = %q, want %q", post.Meta.Slug, "my-post-name")
-	}
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		post, err := store.GetPost(ctx, "my-post-name")
+		if err != nil {
+			t.Errorf("GetPost(my-post-name) error = %v", err)
+		}
+		if post.Meta.Slug != "my-post-name" {
+			t.Errorf("Slug = %q, want %q", post.Meta.Slug, "my-post-name")
+		}
+	})
 }
 
-func TestEmbeddedStore_GetSeries(t *testing.T) {
-	fs := afero.NewMemMapFs()
-	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+func TestStore_GetSeries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
 
-	_ = afero.WriteFile(fs, "post1.md", []byte(`---
+		_ = afero.WriteFile(fs, "post1.md", []byte(`---
 title: Post 1
 slug: post1
 publishDate: `+past+`
@@ -433,7 +571,7 @@ tags: [philosophy, ethics]
 ---
 Content.`), 0644)
 
-	_ = afero.WriteFile(fs, "post2.md", []byte(`---
+		_ = afero.WriteFile(fs, "post2.md", []byte(`---
 title: Post 2
 slug: post2
 publishDate: `+past+`
@@ -442,7 +580,7 @@ tags: [philosophy, metaphysics]
 ---
 Content.`), 0644)
 
-	_ = afero.WriteFile(fs, "post3.md", []byte(`---
+		_ = afero.WriteFile(fs, "post3.md", []byte(`---
 title: Post 3
 slug: post3
 publishDate: `+past+`
@@ -451,6 +589,549 @@ tags: [theology]
 ---
 Content.`), 0644)
 
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		series, err := store.GetSeries(ctx)
+		if err != nil {
+			t.Fatalf("GetSeries() error = %v", err)
+		}
+		if len(series) != 2 {
+			t.Fatalf("len(series) = %d, want 2", len(series))
+		}
+
+		// Series should be sorted by count (descending), then name
+		if series[0].Series != "Series A" || series[0].Count != 2 {
+			t.Errorf("series[0] = %+v, want {Series: Series A, Count: 2}", series[0])
+		}
+		if series[1].Series != "Series B" || series[1].Count != 1 {
+			t.Errorf("series[1] = %+v, want {Series: Series B, Count: 1}", series[1])
+		}
+
+		// TopTags should be computed correctly
+		// Series A has: philosophy (2), ethics (1), metaphysics (1)
+		// Top 3 should be: philosophy, ethics, metaphysics (sorted alphabetically for ties)
+		if len(series[0].TopTags) != 3 {
+			t.Errorf("series[0].TopTags length = %d, want 3", len(series[0].TopTags))
+		}
+		if series[0].TopTags[0] != "philosophy" {
+			t.Errorf("series[0].TopTags[0] = %q, want philosophy", series[0].TopTags[0])
+		}
+
+		// Series B has: theology (1)
+		if len(series[1].TopTags) != 1 || series[1].TopTags[0] != "theology" {
+			t.Errorf("series[1].TopTags = %v, want [theology]", series[1].TopTags)
+		}
+	})
+}
+
+func TestStore_GetSeriesByName(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		day := func(n int) string {
+			return time.Now().AddDate(0, 0, -n).Format(time.RFC3339)
+		}
+
+		_ = afero.WriteFile(fs, "series.yaml", []byte(`Foundations:
+  title: The Foundations
+  description: Where to start.
+  cover: /covers/foundations.png
+`), 0644)
+
+		// Published out of reading order; the appendix has no seriesOrder
+		for _, p := range []struct{ slug, order, date string }{
+			{"appendix", "", day(1)},
+			{"part-two", "seriesOrder: 2\n", day(3)},
+			{"part-one", "seriesOrder: 1\n", day(2)},
+			{"notes", "", day(4)},
+		} {
+			_ = afero.WriteFile(fs, p.slug+".md", []byte("---\ntitle: "+p.slug+"\nslug: "+p.slug+
+				"\npublishDate: "+p.date+"\nseries: Foundations\n"+p.order+"---\nContent."), 0644)
+		}
+		_ = afero.WriteFile(fs, "other.md", []byte("---\ntitle: Other\npublishDate: "+day(1)+
+			"\nseries: Other\n---\nContent."), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+		ctx := context.Background()
+
+		series, err := store.GetSeriesByName(ctx, "Foundations")
+		if err != nil {
+			t.Fatalf("GetSeriesByName() error = %v", err)
+		}
+		var got []string
+		for _, post := range series.Posts {
+			got = append(got, post.Meta.Slug)
+		}
+		if want := "part-one part-two notes appendix"; strings.Join(got, " ") != want {
+			t.Errorf("Posts = %v, want %s", got, want)
+		}
+		if part := series.Part("notes"); part != 3 {
+			t.Errorf("Part(notes) = %d, want 3", part)
+		}
+		if series.Info.Title != "The Foundations" || series.Info.Cover != "/covers/foundations.png" {
+			t.Errorf("Info = %+v, want series.yaml values", series.Info)
+		}
+
+		// Series without a definition are titled by name
+		other, err := store.GetSeriesByName(ctx, "Other")
+		if err != nil {
+			t.Fatalf("GetSeriesByName() error = %v", err)
+		}
+		if other.Info.Title != "Other" {
+			t.Errorf("Info.Title = %q, want %q", other.Info.Title, "Other")
+		}
+
+		if _, err := store.GetSeriesByName(ctx, "Missing"); !errors.Is(err, ErrSeriesNotFound) {
+			t.Errorf("GetSeriesByName(Missing) error = %v, want ErrSeriesNotFound", err)
+		}
+
+		// Editing series.yaml takes effect on reload
+		_ = afero.WriteFile(fs, "series.yaml", []byte("Foundations:\n  title: First Things\n"), 0644)
+		if err := store.Reload("series.yaml"); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+		counts, err := store.GetSeries(ctx)
+		if err != nil {
+			t.Fatalf("GetSeries() error = %v", err)
+		}
+		for _, c := range counts {
+			if c.Series == "Foundations" && c.Info.Title != "First Things" {
+				t.Errorf("Info.Title after reload = %q, want %q", c.Info.Title, "First Things")
+			}
+		}
+	})
+}
+
+func TestStore_Reload(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+		_ = afero.WriteFile(fs, "post1.md", []byte(`---
+title: Post 1
+slug: post1
+publishDate: `+past+`
+tags: [philosophy]
+---
+Original.`), 0644)
+
+		_ = afero.WriteFile(fs, "bundle/index.md", []byte(`---
+title: Bundle
+publishDate: `+past+`
+---
+Bundle content.`), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		// Edit an existing post
+		_ = afero.WriteFile(fs, "post1.md", []byte(`---
+title: Post 1 Revised
+slug: post1
+publishDate: `+past+`
+tags: [theology]
+---
+Revised.`), 0644)
+
+		// Add a new post
+		_ = afero.WriteFile(fs, "post2.md", []byte(`---
+title: Post 2
+slug: post2
+publishDate: `+past+`
+tags: [theology]
+---
+New.`), 0644)
+
+		if err := store.Reload("post1.md", "post2.md"); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+
+		post, err := store.GetPost(ctx, "post1")
+		if err != nil {
+			t.Fatalf("GetPost(post1) error = %v", err)
+		}
+		if post.Meta.Title != "Post 1 Revised" {
+			t.Errorf("Title = %q, want %q", post.Meta.Title, "Post 1 Revised")
+		}
+
+		tags, _ := store.GetTags(ctx)
+		if len(tags) != 1 || tags[0].Tag != "theology" || tags[0].Count != 2 {
+			t.Errorf("GetTags() = %+v, want [{theology 2}]", tags)
+		}
+
+		// Deleting a file removes its post
+		_ = fs.Remove("post2.md")
+		if err := store.Reload("post2.md"); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+		if _, err := store.GetPost(ctx, "post2"); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetPost(post2) error = %v, want ErrPostNotFound", err)
+		}
+
+		// A changed bundle asset re-reads the bundle's index.md
+		_ = afero.WriteFile(fs, "bundle/index.md", []byte(`---
+title: Bundle Revised
+publishDate: `+past+`
+---
+Bundle content.`), 0644)
+		if err := store.Reload("bundle/diagram.svg"); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+		post, err = store.GetPost(ctx, "bundle")
+		if err != nil {
+			t.Fatalf("GetPost(bundle) error = %v", err)
+		}
+		if post.Meta.Title != "Bundle Revised" {
+			t.Errorf("Title = %q, want %q", post.Meta.Title, "Bundle Revised")
+		}
+
+		// A failed reload keeps the previous content
+		_ = afero.WriteFile(fs, "post1.md", []byte("---\ntitle: Broken"), 0644)
+		if err := store.Reload("post1.md"); err == nil {
+			t.Error("Reload() expected error for unclosed frontmatter")
+		}
+		if _, err := store.GetPost(ctx, "post1"); err != nil {
+			t.Errorf("GetPost(post1) after failed reload error = %v", err)
+		}
+	})
+}
+
+func TestStore_ReloadConfig(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+		_ = afero.WriteFile(fs, "config.yaml", []byte("author:\n  name: Alice\n"), 0644)
+		_ = afero.WriteFile(fs, "post.md", []byte(`---
+title: Post
+publishDate: `+past+`
+---
+Content.`), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		_ = afero.WriteFile(fs, "config.yaml", []byte("author:\n  name: Bob\n"), 0644)
+		if err := store.Reload("config.yaml"); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+
+		post, err := store.GetPost(context.Background(), "post")
+		if err != nil {
+			t.Fatalf("GetPost() error = %v", err)
+		}
+		if post.Meta.Author.Name != "Bob" {
+			t.Errorf("Author.Name = %q, want %q", post.Meta.Author.Name, "Bob")
+		}
+	})
+}
+
+func TestStore_Archive(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		for _, p := range []struct{ slug, date, extra string }{
+			{"dec", "2023-12-31T23:00:00Z", ""},
+			{"jan-early", "2024-01-02T12:00:00Z", "tags: [logic]\n"},
+			{"jan-late", "2024-01-30T12:00:00Z", ""},
+			// January in UTC, but the month is taken where it was written
+			{"feb", "2024-02-01T00:30:00+02:00", ""},
+			{"mar", "2024-03-15T12:00:00Z", "tags: [logic]\n"},
+			{"draft", "2022-05-01T12:00:00Z", "draft: true\n"},
+		} {
+			_ = afero.WriteFile(fs, p.slug+".md", []byte("---\ntitle: "+p.slug+"\nslug: "+p.slug+
+				"\npublishDate: "+p.date+"\n"+p.extra+"---\nContent."), 0644)
+		}
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+		ctx := context.Background()
+
+		archive, err := store.GetArchive(ctx)
+		if err != nil {
+			t.Fatalf("GetArchive() error = %v", err)
+		}
+		var got []string
+		for _, y := range archive {
+			months := make([]string, len(y.Months))
+			for i, m := range y.Months {
+				months[i] = fmt.Sprintf("%d:%d", m.Month, m.Count)
+			}
+			got = append(got, fmt.Sprintf("%d:%d [%s]", y.Year, y.Count, strings.Join(months, " ")))
+		}
+		if want := "2024:4 [3:1 2:1 1:2], 2023:1 [12:1]"; strings.Join(got, ", ") != want {
+			t.Errorf("GetArchive() = %s, want %s", strings.Join(got, ", "), want)
+		}
+
+		tests := []struct {
+			name string
+			opts ListOptions
+			want string
+		}{
+			{"year", ListOptions{Year: 2024}, "mar feb jan-late jan-early"},
+			{"month", ListOptions{Year: 2024, Month: time.January}, "jan-late jan-early"},
+			{"month in its own zone", ListOptions{Year: 2024, Month: time.February}, "feb"},
+			{"month and tag", ListOptions{Year: 2024, Month: time.January, Tag: "logic"}, "jan-early"},
+			{"empty month", ListOptions{Year: 2024, Month: time.April}, ""},
+			{"draft year", ListOptions{Year: 2022, IncludeDraft: true}, "draft"},
+		}
+		for _, tt := range tests {
+			posts, total, err := store.ListPosts(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("%s: ListPosts() error = %v", tt.name, err)
+			}
+			var slugs []string
+			for _, post := range posts {
+				slugs = append(slugs, post.Meta.Slug)
+			}
+			if strings.Join(slugs, " ") != tt.want || total != len(slugs) {
+				t.Errorf("%s: ListPosts() = %v (total %d), want %s", tt.name, slugs, total, tt.want)
+			}
+		}
+	})
+}
+
+func TestStore_Authors(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+		_ = afero.WriteFile(fs, "config.yaml", []byte(`author:
+  name: Site Owner
+authors:
+  alice:
+    name: Alice
+    bio: Editor.
+  bob:
+    name: Bob
+    url: https://bob.example.com
+  carol:
+    name: Carol
+`), 0644)
+		write := func(slug, frontmatter string) {
+			_ = afero.WriteFile(fs, slug+".md", []byte("---\ntitle: "+slug+"\nslug: "+slug+
+				"\npublishDate: "+past+"\n"+frontmatter+"---\nContent."), 0644)
+		}
+		write("co-written", "authors: [bob, alice]\n")
+		write("by-alice", "authors: [alice]\n")
+		write("inline", "author:\n  name: Guest\n")
+		write("default", "")
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+		ctx := context.Background()
+
+		names := func(slug string) string {
+			post, err := store.GetPost(ctx, slug)
+			if err != nil {
+				t.Fatalf("GetPost(%q) error = %v", slug, err)
+			}
+			var out []string
+			for _, a := range post.Meta.Authors {
+				out = append(out, a.Name)
+			}
+			return strings.Join(out, ", ")
+		}
+		for slug, want := range map[string]string{
+			"co-written": "Bob, Alice",
+			"by-alice":   "Alice",
+			"inline":     "Guest",
+			"default":    "Site Owner",
+		} {
+			if got := names(slug); got != want {
+				t.Errorf("%s: Authors = %q, want %q", slug, got, want)
+			}
+		}
+
+		// The first author is the primary author
+		post, _ := store.GetPost(ctx, "co-written")
+		if post.Meta.Author.ID != "bob" || post.Meta.Author.URL != "https://bob.example.com" {
+			t.Errorf("Author = %+v, want bob", post.Meta.Author)
+		}
+
+		// Carol has no posts, so isn't listed
+		authors, err := store.GetAuthors(ctx)
+		if err != nil {
+			t.Fatalf("GetAuthors() error = %v", err)
+		}
+		if len(authors) != 2 || authors[0].Author.ID != "alice" || authors[0].Count != 2 ||
+			authors[1].Author.ID != "bob" || authors[1].Count != 1 {
+			t.Errorf("GetAuthors() = %+v, want alice (2), bob (1)", authors)
+		}
+
+		posts, total, err := store.ListPosts(ctx, ListOptions{Author: "alice"})
+		if err != nil {
+			t.Fatalf("ListPosts() error = %v", err)
+		}
+		if total != 2 || len(posts) != 2 {
+			t.Errorf("ListPosts(Author: alice) total = %d, want 2", total)
+		}
+
+		carol, err := store.GetAuthor(ctx, "carol")
+		if err != nil || carol.Name != "Carol" || carol.ID != "carol" {
+			t.Errorf("GetAuthor(carol) = %+v, %v; want Carol", carol, err)
+		}
+		if _, err := store.GetAuthor(ctx, "dave"); !errors.Is(err, ErrAuthorNotFound) {
+			t.Errorf("GetAuthor(dave) error = %v, want ErrAuthorNotFound", err)
+		}
+
+		// Referring to an undefined author is an error
+		write("typo", "authors: [dave]\n")
+		if _, err := open(fs); err == nil || !strings.Contains(err.Error(), `unknown author "dave"`) {
+			t.Errorf("open() error = %v, want unknown author", err)
+		}
+	})
+}
+
+func TestStore_Changelog(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+
+		_ = afero.WriteFile(fs, "revised.md", []byte(`---
+title: Revised
+slug: revised
+publishDate: 2024-01-15T00:00:00Z
+updatedDate: 2024-03-01T00:00:00Z
+changelog:
+  - date: 2024-02-01T00:00:00Z
+    note: Fixed a citation.
+  - date: 2024-04-10T00:00:00Z
+    note: Expanded the conclusion.
+---
+Content.`), 0644)
+		_ = afero.WriteFile(fs, "untouched.md", []byte(`---
+title: Untouched
+slug: untouched
+publishDate: 2024-01-15T00:00:00Z
+---
+Content.`), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+		ctx := context.Background()
+
+		post, err := store.GetPost(ctx, "revised")
+		if err != nil {
+			t.Fatalf("GetPost() error = %v", err)
+		}
+		if len(post.Meta.Changelog) != 2 || post.Meta.Changelog[0].Note != "Expanded the conclusion." {
+			t.Errorf("Changelog = %+v, want newest first", post.Meta.Changelog)
+		}
+		// The newest changelog entry is later than updatedDate
+		if want := time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC); !post.Meta.LastModified().Equal(want) {
+			t.Errorf("LastModified() = %v, want %v", post.Meta.LastModified(), want)
+		}
+		if !post.Meta.Revised() {
+			t.Error("Revised() = false, want true")
+		}
+
+		post, err = store.GetPost(ctx, "untouched")
+		if err != nil {
+			t.Fatalf("GetPost() error = %v", err)
+		}
+		if !post.Meta.LastModified().Equal(post.Meta.PublishDate) {
+			t.Errorf("LastModified() = %v, want publish date", post.Meta.LastModified())
+		}
+		if post.Meta.Revised() {
+			t.Error("Revised() = true, want false")
+		}
+	})
+}
+
+func TestStore_BundleImages(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+		var chart bytes.Buffer
+		_ = png.Encode(&chart, image.NewNRGBA(image.Rect(0, 0, 1000, 500)))
+		_ = afero.WriteFile(fs, "essay/chart.png", chart.Bytes(), 0644)
+		_ = afero.WriteFile(fs, "essay/diagram.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 0644)
+		_ = afero.WriteFile(fs, "essay/index.md", []byte(`---
+title: Essay
+slug: essay
+publishDate: `+past+`
+---
+![A chart](./chart.png)
+![A diagram](diagram.svg)`), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+		ctx := context.Background()
+
+		post, err := store.GetPost(ctx, "essay")
+		if err != nil {
+			t.Fatalf("GetPost() error = %v", err)
+		}
+		for _, want := range []string{
+			`src="/posts/essay/chart.png"`,
+			`srcset="/posts/essay/_img/480/chart.png 480w, /posts/essay/_img/960/chart.png 960w, /posts/essay/chart.png 1000w"`,
+			`width="1000" height="500"`,
+			// SVGs aren't resized and keep the markdown image syntax
+			`![A diagram](/posts/essay/diagram.svg)`,
+		} {
+			if !strings.Contains(post.HTMLContent, want) {
+				t.Errorf("HTMLContent missing %q\n%s", want, post.HTMLContent)
+			}
+		}
+
+		img, err := store.GetPostImage(ctx, "essay", "chart.png")
+		if err != nil {
+			t.Fatalf("GetPostImage() error = %v", err)
+		}
+		if img.Width != 1000 || img.Height != 500 {
+			t.Errorf("GetPostImage() size = %dx%d, want 1000x500", img.Width, img.Height)
+		}
+		if _, err := store.GetPostImage(ctx, "essay", "diagram.svg"); err == nil {
+			t.Error("GetPostImage(diagram.svg) error = nil, want error")
+		}
+		if _, err := store.GetPostImage(ctx, "missing", "chart.png"); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetPostImage(missing) error = %v, want ErrPostNotFound", err)
+		}
+	})
+}
+
+func TestEmbeddedStore_ScheduledPublishing(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	past := time.Now().Add(-30 * 24 * time.Hour).Format(time.RFC3339)
+	future := time.Now().Add(24 * time.Hour)
+
+	_ = afero.WriteFile(fs, "old.md", []byte(`---
+title: Old Post
+slug: old
+publishDate: `+past+`
+series: Series A
+---
+Old content.`), 0644)
+
+	_ = afero.WriteFile(fs, "scheduled.md", []byte(`---
+title: Scheduled Post
+slug: scheduled
+publishDate: `+future.Format(time.RFC3339)+`
+series: Series A
+---
+Scheduled content.`), 0644)
+
 	store, err := NewEmbeddedStore(fs, &mockRenderer{})
 	if err != nil {
 		t.Fatalf("NewEmbeddedStore() error = %v", err)
@@ -458,34 +1139,194 @@ Content.`), 0644)
 
 	ctx := context.Background()
 
-	series, err := store.GetSeries(ctx)
+	upcoming := store.Upcoming()
+	if len(upcoming) != 1 || upcoming[0].Meta.Slug != "scheduled" {
+		t.Fatalf("Upcoming() = %v, want [scheduled]", upcoming)
+	}
+	if _, err := store.GetPost(ctx, "scheduled"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("GetPost(scheduled) before publish error = %v, want ErrPostNotFound", err)
+	}
+
+	// Nothing is due yet
+	if published := store.publishDue(time.Now()); len(published) != 0 {
+		t.Errorf("publishDue(now) = %v, want none", published)
+	}
+
+	published := store.publishDue(future.Add(time.Minute))
+	if len(published) != 1 || published[0].Meta.Slug != "scheduled" {
+		t.Fatalf("publishDue() = %v, want [scheduled]", published)
+	}
+
+	if _, err := store.GetPost(ctx, "scheduled"); err != nil {
+		t.Errorf("GetPost(scheduled) after publish error = %v", err)
+	}
+	if len(store.Upcoming()) != 0 {
+		t.Errorf("Upcoming() should be empty after publish")
+	}
+
+	series, _ := store.GetSeries(ctx)
+	if len(series) != 1 || series[0].Count != 2 {
+		t.Fatalf("GetSeries() = %+v, want Series A with 2 posts", series)
+	}
+	// Only the old post counted before, which is outside the 7-day window
+	if !series[0].HasRecentPosts {
+		t.Error("HasRecentPosts should be recomputed after publishing")
+	}
+}
+
+func TestStore_Schedule(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		soon := time.Now().Add(200 * time.Millisecond).Format(time.RFC3339Nano)
+
+		_ = afero.WriteFile(fs, "soon.md", []byte(`---
+title: Soon
+slug: soon
+publishDate: `+soon+`
+---
+Content.`), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go store.Schedule(ctx)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if _, err := store.GetPost(ctx, "soon"); err == nil {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Error("scheduled post was not published when its time arrived")
+	})
+}
+
+func TestEmbeddedStore_Version(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+	_ = afero.WriteFile(fs, "post1.md", []byte(`---
+title: Post 1
+slug: post1
+publishDate: `+past+`
+---
+Original.`), 0644)
+	_ = afero.WriteFile(fs, "post2.md", []byte(`---
+title: Post 2
+slug: post2
+publishDate: `+past+`
+---
+Unchanged.`), 0644)
+
+	store, err := NewEmbeddedStore(fs, &mockRenderer{})
 	if err != nil {
-		t.Fatalf("GetSeries() error = %v", err)
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+
+	ctx := context.Background()
+	before, _ := store.Version(ctx)
+	post1Before, _ := store.PostVersion(ctx, "post1")
+	post2Before, _ := store.PostVersion(ctx, "post2")
+	if before.ETag == "" || before.Modified.IsZero() {
+		t.Fatalf("Version() = %+v, want ETag and Modified set", before)
+	}
+
+	// Rebuilding unchanged indexes keeps every version
+	store.publishDue(time.Now())
+	if after, _ := store.Version(ctx); after != before {
+		t.Errorf("Version() after rebuild = %+v, want %+v", after, before)
 	}
-	if len(series) != 2 {
-		t.Fatalf("len(series) = %d, want 2", len(series))
+
+	// Editing a post changes its version and the listing version, but not
+	// an unrelated post's
+	_ = afero.WriteFile(fs, "post1.md", []byte(`---
+title: Post 1
+slug: post1
+publishDate: `+past+`
+---
+Revised.`), 0644)
+	if err := store.Reload("post1.md"); err != nil {
+		t.Fatalf("Reload() error = %v", err)
 	}
 
-	// Series should be sorted by count (descending), then name
-	if series[0].Series != "Series A" || series[0].Count != 2 {
-		t.Errorf("series[0] = %+v, want {Series: Series A, Count: 2}", series[0])
+	if after, _ := store.Version(ctx); after.ETag == before.ETag {
+		t.Error("Version() ETag unchanged after edit")
 	}
-	if series[1].Series != "Series B" || series[1].Count != 1 {
-		t.Errorf("series[1] = %+v, want {Series: Series B, Count: 1}", series[1])
+	if after, _ := store.PostVersion(ctx, "post1"); after.ETag == post1Before.ETag {
+		t.Error("PostVersion(post1) ETag unchanged after edit")
+	}
+	if after, _ := store.PostVersion(ctx, "post2"); after != post2Before {
+		t.Errorf("PostVersion(post2) = %+v, want %+v", after, post2Before)
 	}
 
-	// TopTags should be computed correctly
-	// Series A has: philosophy (2), ethics (1), metaphysics (1)
-	// Top 3 should be: philosophy, ethics, metaphysics (sorted alphabetically for ties)
-	if len(series[0].TopTags) != 3 {
-		t.Errorf("series[0].TopTags length = %d, want 3", len(series[0].TopTags))
+	// A post's version covers all of its series' info
+	_ = afero.WriteFile(fs, "post3.md", []byte(`---
+title: Post 3
+slug: post3
+publishDate: `+past+`
+series: Ethics
+---
+In a series.`), 0644)
+	_ = afero.WriteFile(fs, "series.yaml", []byte("Ethics:\n  title: Ethics\n"), 0644)
+	if err := store.Reload("series.yaml"); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	post3Before, _ := store.PostVersion(ctx, "post3")
+	_ = afero.WriteFile(fs, "series.yaml", []byte("Ethics:\n  title: Ethics\n  cover: /ethics.png\n"), 0644)
+	if err := store.Reload("series.yaml"); err != nil {
+		t.Fatalf("Reload() error = %v", err)
 	}
-	if series[0].TopTags[0] != "philosophy" {
-		t.Errorf("series[0].TopTags[0] = %q, want philosophy", series[0].TopTags[0])
+	if after, _ := store.PostVersion(ctx, "post3"); after.ETag == post3Before.ETag {
+		t.Error("PostVersion(post3) ETag unchanged after editing its series")
 	}
 
-	// Series B has: theology (1)
-	if len(series[1].TopTags) != 1 || series[1].TopTags[0] != "theology" {
-		t.Errorf("series[1].TopTags = %v, want [theology]", series[1].TopTags)
+	if _, err := store.PostVersion(ctx, "nope"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("PostVersion(nope) error = %v, want ErrPostNotFound", err)
 	}
 }
+
+func TestStore_Stats(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+		future := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+
+		_ = afero.WriteFile(fs, "published.md", []byte(`---
+title: Published
+slug: published
+publishDate: `+past+`
+tags: [ethics, logic]
+series: Foundations
+---
+Published content.`), 0644)
+		_ = afero.WriteFile(fs, "draft.md", []byte(`---
+title: Draft
+slug: draft
+publishDate: `+past+`
+draft: true
+tags: [drafts-only]
+---
+Draft content.`), 0644)
+		_ = afero.WriteFile(fs, "future.md", []byte(`---
+title: Future
+slug: future
+publishDate: `+future+`
+---
+Future content.`), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		want := Stats{Posts: 1, Drafts: 1, Scheduled: 1, Tags: 2, Series: 1}
+		if got := store.Stats(); got != want {
+			t.Errorf("Stats() = %+v, want %+v", got, want)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package content
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cursor is a position in a sorted post listing, just after or just before
+// a post. Unlike an offset, it keeps its place when posts are published or
+// removed ahead of it. Cursors travel as opaque tokens (see String).
+type Cursor struct {
+	sortBy    SortField
+	sortOrder SortOrder
+	key       sortKey
+	before    bool
+}
+
+// sortKey is what places a post in a listing.
+type sortKey struct {
+	title       string // Lowercased
+	readingTime int
+	date        time.Time
+	slug        string
+}
+
+func keyOf(post *Post) sortKey {
+	return sortKey{
+		title:       strings.ToLower(post.Meta.Title),
+		readingTime: post.Meta.ReadingTime(),
+		date:        post.Meta.PublishDate,
+		slug:        post.Meta.Slug,
+	}
+}
+
+// listSort returns the field and direction opts sorts by: newest first
+// unless set otherwise.
+func (opts ListOptions) listSort() (SortField, SortOrder) {
+	field, order := opts.SortBy, opts.SortOrder
+	if field == "" {
+		field = SortByDate
+	}
+	if order == "" {
+		order = SortDesc
+	}
+	return field, order
+}
+
+// compareKeys orders posts as a listing sorted by field and order does,
+// breaking ties newest first and then by slug.
+func compareKeys(a, b sortKey, field SortField, order SortOrder) int {
+	var c int
+	switch field {
+	case SortByTitle:
+		c = strings.Compare(a.title, b.title)
+	case SortByReadingTime:
+		c = cmp.Compare(a.readingTime, b.readingTime)
+	default:
+		c = a.date.Compare(b.date)
+	}
+	if order == SortDesc {
+		c = -c
+	}
+	if c != 0 {
+		return c
+	}
+	if c := b.date.Compare(a.date); c != 0 {
+		return c
+	}
+	return strings.Compare(a.slug, b.slug)
+}
+
+// CursorAfter returns a cursor for the posts following post in a listing
+// sorted as opts.
+func CursorAfter(post *Post, opts ListOptions) Cursor {
+	field, order := opts.listSort()
+	return Cursor{sortBy: field, sortOrder: order, key: keyOf(post)}
+}
+
+// CursorBefore returns a cursor for the posts preceding post in a listing
+// sorted as opts.
+func CursorBefore(post *Post, opts ListOptions) Cursor {
+	c := CursorAfter(post, opts)
+	c.before = true
+	return c
+}
+
+// Before reports whether the cursor pages backwards, to the posts
+// preceding it.
+func (c Cursor) Before() bool {
+	return c.before
+}
+
+// check returns ErrInvalidCursor unless the cursor comes from a listing
+// sorted as opts.
+func (c Cursor) check(opts ListOptions) error {
+	if field, order := opts.listSort(); c.sortBy != field || c.sortOrder != order {
+		return fmt.Errorf("%w: made for sortBy %s, sortOrder %s", ErrInvalidCursor, c.sortBy, c.sortOrder)
+	}
+	return nil
+}
+
+// cursorToken is the encoded form of a Cursor. Only the key the listing
+// sorts by, besides the date and slug, is kept.
+type cursorToken struct {
+	SortBy      SortField `json:"s"`
+	SortOrder   SortOrder `json:"o"`
+	Title       string    `json:"t,omitempty"`
+	ReadingTime int       `json:"r,omitempty"`
+	Date        time.Time `json:"d"`
+	Slug        string    `json:"p"`
+	Before      bool      `json:"b,omitempty"`
+}
+
+// String returns the cursor as an opaque, URL-safe token for ParseCursor.
+func (c Cursor) String() string {
+	token := cursorToken{
+		SortBy:    c.sortBy,
+		SortOrder: c.sortOrder,
+		Date:      c.key.date,
+		Slug:      c.key.slug,
+		Before:    c.before,
+	}
+	switch c.sortBy {
+	case SortByTitle:
+		token.Title = c.key.title
+	case SortByReadingTime:
+		token.ReadingTime = c.key.readingTime
+	}
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes a token from Cursor.String. Returns ErrInvalidCursor
+// if it is malformed.
+func ParseCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	switch token.SortBy {
+	case SortByDate, SortByTitle, SortByReadingTime:
+	default:
+		return Cursor{}, ErrInvalidCursor
+	}
+	if token.SortOrder != SortAsc && token.SortOrder != SortDesc {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{
+		sortBy:    token.SortBy,
+		sortOrder: token.SortOrder,
+		key: sortKey{
+			title:       token.Title,
+			readingTime: token.ReadingTime,
+			date:        token.Date,
+			slug:        token.Slug,
+		},
+		before: token.Before,
+	}, nil
+}
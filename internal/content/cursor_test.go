@@ -0,0 +1,148 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestStore_Cursor(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		// Shared dates, titles and reading times, so every sort has ties
+		for i, p := range []struct{ slug, title, date string }{
+			{"a", "Beta", "2024-01-01"},
+			{"b", "alpha", "2024-01-01"},
+			{"c", "Alpha", "2024-02-01"},
+			{"d", "Gamma", "2024-03-01"},
+			{"e", "Delta", "2024-03-01"},
+			{"f", "Beta", "2024-04-01"},
+			{"g", "Epsilon", "2024-05-01"},
+		} {
+			words := strings.Repeat("word ", 200*(1+i%3))
+			_ = afero.WriteFile(fs, p.slug+".md", []byte("---\ntitle: "+p.title+"\nslug: "+p.slug+
+				"\npublishDate: "+p.date+"T12:00:00Z\n---\n"+words), 0644)
+		}
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+		ctx := context.Background()
+
+		slugs := func(posts []*Post) string {
+			var got []string
+			for _, post := range posts {
+				got = append(got, post.Meta.Slug)
+			}
+			return strings.Join(got, "")
+		}
+
+		for _, sort := range []ListOptions{
+			{},
+			{SortOrder: SortAsc},
+			{SortBy: SortByTitle},
+			{SortBy: SortByTitle, SortOrder: SortAsc},
+			{SortBy: SortByReadingTime},
+			{SortBy: SortByReadingTime, SortOrder: SortAsc},
+		} {
+			name := fmt.Sprintf("%s %s", sort.SortBy, sort.SortOrder)
+			all, total, err := store.ListPosts(ctx, sort)
+			if err != nil {
+				t.Fatalf("%s: ListPosts() error = %v", name, err)
+			}
+			want := slugs(all)
+
+			// Walk forwards two at a time, then back from the end
+			var forward []*Post
+			opts := sort
+			opts.Limit = 2
+			for {
+				page, pageTotal, err := store.ListPosts(ctx, opts)
+				if err != nil {
+					t.Fatalf("%s: ListPosts() error = %v", name, err)
+				}
+				if pageTotal != total {
+					t.Errorf("%s: total = %d, want %d", name, pageTotal, total)
+				}
+				if len(page) == 0 {
+					break
+				}
+				forward = append(forward, page...)
+				cursor, err := ParseCursor(CursorAfter(page[len(page)-1], sort).String())
+				if err != nil {
+					t.Fatalf("%s: ParseCursor() error = %v", name, err)
+				}
+				opts.Cursor = &cursor
+			}
+			if got := slugs(forward); got != want {
+				t.Errorf("%s: forward pages = %s, want %s", name, got, want)
+			}
+
+			var backward []*Post
+			cursor := CursorBefore(all[len(all)-1], sort)
+			backward = append(backward, all[len(all)-1])
+			opts.Cursor = &cursor
+			for {
+				page, _, err := store.ListPosts(ctx, opts)
+				if err != nil {
+					t.Fatalf("%s: ListPosts() error = %v", name, err)
+				}
+				if len(page) == 0 {
+					break
+				}
+				backward = append(page, backward...)
+				cursor = CursorBefore(page[0], sort)
+			}
+			if got := slugs(backward); got != want {
+				t.Errorf("%s: backward pages = %s, want %s", name, got, want)
+			}
+		}
+
+		t.Run("offset from cursor", func(t *testing.T) {
+			all, _, _ := store.ListPosts(ctx, ListOptions{})
+			after := CursorAfter(all[1], ListOptions{})
+			posts, _, _ := store.ListPosts(ctx, ListOptions{Cursor: &after, Offset: 1, Limit: 2})
+			if got, want := slugs(posts), slugs(all[3:5]); got != want {
+				t.Errorf("after with offset = %s, want %s", got, want)
+			}
+			before := CursorBefore(all[5], ListOptions{})
+			posts, _, _ = store.ListPosts(ctx, ListOptions{Cursor: &before, Offset: 1, Limit: 2})
+			if got, want := slugs(posts), slugs(all[2:4]); got != want {
+				t.Errorf("before with offset = %s, want %s", got, want)
+			}
+		})
+
+		t.Run("stable across new posts", func(t *testing.T) {
+			page, _, _ := store.ListPosts(ctx, ListOptions{Limit: 2})
+			cursor := CursorAfter(page[1], ListOptions{})
+			next, _, _ := store.ListPosts(ctx, ListOptions{Cursor: &cursor, Limit: 2})
+
+			_ = afero.WriteFile(fs, "h.md", []byte("---\ntitle: Eta\nslug: h\npublishDate: 2024-06-01T12:00:00Z\n---\nNew."), 0644)
+			if err := store.Reload("h.md"); err != nil {
+				t.Fatalf("Reload() error = %v", err)
+			}
+			again, total, _ := store.ListPosts(ctx, ListOptions{Cursor: &cursor, Limit: 2})
+			if slugs(again) != slugs(next) || total != 8 {
+				t.Errorf("after publishing = %s (total %d), want %s (total 8)", slugs(again), total, slugs(next))
+			}
+		})
+
+		t.Run("invalid", func(t *testing.T) {
+			all, _, _ := store.ListPosts(ctx, ListOptions{})
+			byTitle := CursorAfter(all[0], ListOptions{SortBy: SortByTitle})
+			if _, _, err := store.ListPosts(ctx, ListOptions{Cursor: &byTitle}); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("ListPosts() with another sort's cursor error = %v, want ErrInvalidCursor", err)
+			}
+			for _, token := range []string{"", "not a cursor!", "e30"} {
+				if _, err := ParseCursor(token); !errors.Is(err, ErrInvalidCursor) {
+					t.Errorf("ParseCursor(%q) error = %v, want ErrInvalidCursor", token, err)
+				}
+			}
+		})
+	})
+}
@@ -6,15 +6,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
 	"io"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"therefore/internal/images"
 	"therefore/internal/renderer"
 
 	"github.com/spf13/afero"
@@ -37,77 +42,240 @@ var (
 
 // SiteConfig contains site-wide configuration loaded from config.yaml.
 type SiteConfig struct {
-	Author Author `yaml:"author"`
+	Author  Author            `yaml:"author"`  // Default for posts that don't name an author
+	Authors map[string]Author `yaml:"authors"` // Author profiles posts can refer to by id
 }
 
 // Compile-time interface compliance check.
 var _ ContentStore = (*EmbeddedStore)(nil)
 
 // EmbeddedStore implements ContentStore using an afero filesystem.
-// All posts are loaded and rendered at initialization time.
+// All posts are loaded and rendered at initialization time, and can be
+// re-read afterwards with Reload.
 type EmbeddedStore struct {
-	fs       afero.Fs
-	config   SiteConfig
-	posts    map[string]*Post // keyed by slug
-	sorted   []*Post          // sorted by date, newest first
-	tags     []TagCount
-	tagIndex map[string][]*Post
-	series   []SeriesCount
+	fs           afero.Fs
+	renderer     Renderer
+	config       SiteConfig
+	posts        map[string]*Post  // published, keyed by slug
+	pending      map[string]*Post  // scheduled for a future publish date, keyed by slug
+	drafts       map[string]*Post  // marked draft in frontmatter, keyed by slug
+	sources      map[string]string // source file path -> slug
+	sorted       []*Post           // sorted by date, newest first
+	tags         []TagCount
+	tagIndex     map[string][]*Post
+	archive      []ArchiveYear
+	archiveIndex map[archiveKey][]*Post // newest first
+	series       []SeriesCount
+	seriesInfo   map[string]SeriesInfo // from series.yaml, keyed by series name
+	seriesIndex  map[string]*Series    // posts in reading order, keyed by series name
+	authors      []AuthorCount         // configured authors with published posts
+	search       *searchIndex
+	related      map[string][]*Post // slug -> most similar posts, best first
+	version      Version            // of the published content as a whole
+	postVersions map[string]Version // published posts, keyed by slug
 
 	mu sync.RWMutex
+
+	// reloadMu serializes Reload calls so concurrent reloads can't
+	// overwrite each other's changes.
+	reloadMu sync.Mutex
+
+	// wake tells a running Schedule loop that the pending set changed.
+	wake chan struct{}
+
+	// dates, if set, supplies publish and updated dates for posts whose
+	// frontmatter leaves them out, from the post's source file and bundle
+	// directory.
+	dates func(source, bundleDir string) (published, updated time.Time)
+
+	// indexed, if set, is called at the end of every index rebuild, with
+	// s.mu held once the store is shared, so a copy of the store's content
+	// can be kept in step. See SQLiteStore.
+	indexed func()
+}
+
+// postSet holds the posts loaded from the filesystem while they are
+// being (re)built, before being swapped into the store.
+type postSet struct {
+	posts   map[string]*Post
+	pending map[string]*Post
+	drafts  map[string]*Post
+	sources map[string]string
+}
+
+func newPostSet() *postSet {
+	return &postSet{
+		posts:   make(map[string]*Post),
+		pending: make(map[string]*Post),
+		drafts:  make(map[string]*Post),
+		sources: make(map[string]string),
+	}
+}
+
+// lookup finds a post by slug whatever its publication state.
+func (ps *postSet) lookup(slug string) (*Post, bool) {
+	for _, m := range []map[string]*Post{ps.posts, ps.pending, ps.drafts} {
+		if post, ok := m[slug]; ok {
+			return post, true
+		}
+	}
+	return nil, false
+}
+
+// remove drops the post loaded from source, if any.
+func (ps *postSet) remove(source string) {
+	if slug, ok := ps.sources[source]; ok {
+		delete(ps.posts, slug)
+		delete(ps.pending, slug)
+		delete(ps.drafts, slug)
+		delete(ps.sources, source)
+	}
 }
 
 // NewEmbeddedStore creates a new store from the given filesystem.
 // The fs should contain markdown files in the root directory.
 // All posts are parsed and rendered immediately.
 func NewEmbeddedStore(fs afero.Fs, renderer Renderer) (*EmbeddedStore, error) {
-	store := &EmbeddedStore{
-		fs:       fs,
-		posts:    make(map[string]*Post),
-		tagIndex: make(map[string][]*Post),
+	store := newEmbeddedStore(fs, renderer)
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// newEmbeddedStore creates a store that hasn't loaded anything yet.
+func newEmbeddedStore(fs afero.Fs, renderer Renderer) *EmbeddedStore {
+	return &EmbeddedStore{
+		fs:           fs,
+		renderer:     renderer,
+		tagIndex:     make(map[string][]*Post),
+		archiveIndex: make(map[archiveKey][]*Post),
+		wake:         make(chan struct{}, 1),
 	}
+}
 
+// load reads the config and every post for a new store.
+func (s *EmbeddedStore) load() error {
 	// Load site config if present
-	if err := store.loadConfig(fs); err != nil {
-		return nil, fmt.Errorf("loading config: %w", err)
+	config, err := s.loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
 	}
+	s.config = config
 
-	if err := store.loadPosts(fs, renderer); err != nil {
-		return nil, fmt.Errorf("loading posts: %w", err)
+	seriesInfo, err := s.loadSeriesInfo()
+	if err != nil {
+		return fmt.Errorf("loading series: %w", err)
 	}
+	s.seriesInfo = seriesInfo
 
-	store.buildIndexes()
-	return store, nil
+	set := newPostSet()
+	if err := s.loadPosts(set); err != nil {
+		return fmt.Errorf("loading posts: %w", err)
+	}
+
+	s.posts, s.pending, s.drafts, s.sources = set.posts, set.pending, set.drafts, set.sources
+	s.buildIndexes()
+	return nil
 }
 
-func (s *EmbeddedStore) loadConfig(fs afero.Fs) error {
-	f, err := fs.Open("config.yaml")
+func (s *EmbeddedStore) loadConfig() (SiteConfig, error) {
+	var config SiteConfig
+
+	f, err := s.fs.Open("config.yaml")
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Config is optional
-			return nil
+			return config, nil
 		}
-		return fmt.Errorf("opening config: %w", err)
+		return config, fmt.Errorf("opening config: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
 	content, err := io.ReadAll(f)
 	if err != nil {
-		return fmt.Errorf("reading config: %w", err)
+		return config, fmt.Errorf("reading config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("parsing config: %w", err)
+	}
+
+	for id, author := range config.Authors {
+		author.ID = id
+		config.Authors[id] = author
+	}
+
+	return config, nil
+}
+
+// loadSeriesInfo reads the optional series.yaml, which maps series names
+// to their descriptions.
+func (s *EmbeddedStore) loadSeriesInfo() (map[string]SeriesInfo, error) {
+	info := make(map[string]SeriesInfo)
+
+	data, err := afero.ReadFile(s.fs, "series.yaml")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return info, nil
+		}
+		return nil, fmt.Errorf("reading series.yaml: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("parsing series.yaml: %w", err)
 	}
+	return info, nil
+}
 
-	if err := yaml.Unmarshal(content, &s.config); err != nil {
-		return fmt.Errorf("parsing config: %w", err)
+// loadPosts walks the filesystem and parses every post into set, then logs
+// a summary of what was loaded.
+func (s *EmbeddedStore) loadPosts(set *postSet) error {
+	start := time.Now()
+	err := s.walkSources(".", func(src Source) error {
+		return s.addPost(set, src.Path, src.BundleDir)
+	})
+	if err != nil {
+		return err
 	}
 
+	elapsed := time.Since(start)
+	loadDuration.Observe(elapsed.Seconds())
+	var perPost time.Duration
+	if total := len(set.posts) + len(set.pending) + len(set.drafts); total > 0 {
+		perPost = elapsed / time.Duration(total)
+	}
+	slog.Info("Loaded content",
+		"posts", len(set.posts),
+		"draftsSkipped", len(set.drafts),
+		"scheduledSkipped", len(set.pending),
+		"duration", elapsed.Round(time.Millisecond),
+		"perPost", perPost.Round(time.Microsecond))
 	return nil
 }
 
-func (s *EmbeddedStore) loadPosts(fs afero.Fs, renderer Renderer) error {
-	// Track directories we've processed as bundles to avoid double-processing
-	processedBundles := make(map[string]bool)
+// Source is a markdown file that defines a post.
+type Source struct {
+	Path      string // Path relative to the root of the content filesystem
+	BundleDir string // Page bundle directory (empty for standalone posts)
+}
 
-	return afero.Walk(fs, ".", func(path string, info os.FileInfo, err error) error {
+// Sources lists the files in fs that define posts, following the same
+// page bundle rules as NewEmbeddedStore.
+func Sources(fs afero.Fs) ([]Source, error) {
+	s := &EmbeddedStore{fs: fs}
+	var sources []Source
+	err := s.walkSources(".", func(src Source) error {
+		sources = append(sources, src)
+		return nil
+	})
+	return sources, err
+}
+
+// walkSources calls fn for every markdown file under root that defines a
+// post.
+func (s *EmbeddedStore) walkSources(root string, fn func(Source) error) error {
+	return afero.Walk(s.fs, root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -117,47 +285,205 @@ func (s *EmbeddedStore) loadPosts(fs afero.Fs, renderer Renderer) error {
 			return nil
 		}
 
-		// Determine if this is a page bundle (dir/index.md) or standalone post
-		var bundleDir string
-		dir := filepath.Dir(path)
-		filename := filepath.Base(path)
+		// Non-index markdown files inside a bundle belong to the bundle
+		source, bundleDir := s.postSource(path)
+		if source != path {
+			return nil
+		}
+
+		return fn(Source{Path: source, BundleDir: bundleDir})
+	})
+}
 
-		if filename == "index.md" && dir != "." {
-			// This is a page bundle
-			bundleDir = dir
-			if processedBundles[dir] {
-				return nil // Already processed
-			}
-			processedBundles[dir] = true
-		} else if dir != "." {
-			// This is a .md file inside a directory but not index.md
-			// Check if there's an index.md in the same dir (skip non-index files in bundles)
-			if exists, _ := afero.Exists(fs, filepath.Join(dir, "index.md")); exists {
-				return nil // Skip, the bundle's index.md will be processed
-			}
+// postSource maps a path in the content filesystem to the markdown file
+// that defines the post it belongs to. Markdown files in a directory with
+// an index.md belong to that page bundle, as does any asset nested under
+// the bundle directory; the bundle directory is returned alongside.
+func (s *EmbeddedStore) postSource(path string) (source, bundleDir string) {
+	path = filepath.Clean(path)
+	dir := filepath.Dir(path)
+
+	if filepath.Base(path) == "index.md" && dir != "." {
+		return path, dir
+	}
+
+	for ; dir != "."; dir = filepath.Dir(dir) {
+		index := filepath.Join(dir, "index.md")
+		if exists, _ := afero.Exists(s.fs, index); exists {
+			return index, dir
 		}
+		// Markdown files only belong to a bundle in their own directory
+		if strings.HasSuffix(path, ".md") {
+			break
+		}
+	}
 
-		post, err := s.parsePost(fs, path, renderer, bundleDir)
-		if err != nil {
-			return fmt.Errorf("parsing %s: %w", path, err)
+	return path, ""
+}
+
+// addPost parses the post at path and adds it to set. Drafts are kept
+// apart for previews, and posts with a future publish date are held in
+// set.pending until Schedule promotes them.
+func (s *EmbeddedStore) addPost(set *postSet, path, bundleDir string) error {
+	start := time.Now()
+	post, err := s.parsePost(s.fs, path, s.renderer, bundleDir)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	render := time.Since(start)
+	renderDuration.Observe(render.Seconds())
+
+	// Check for slug collision, including against drafts and scheduled posts
+	if existing, ok := set.lookup(post.Meta.Slug); ok {
+		return fmt.Errorf("duplicate slug %q: found in both %q and %q",
+			post.Meta.Slug, existing.Meta.Title, post.Meta.Title)
+	}
+
+	state := "published"
+	switch {
+	case post.Meta.Draft:
+		set.drafts[post.Meta.Slug] = post
+		state = "draft"
+	case post.Meta.PublishDate.After(time.Now()):
+		set.pending[post.Meta.Slug] = post
+		state = "scheduled"
+	default:
+		set.posts[post.Meta.Slug] = post
+	}
+	set.sources[path] = post.Meta.Slug
+
+	slog.Debug("Loaded post", "slug", post.Meta.Slug, "state", state, "render", render)
+	return nil
+}
+
+// Reload re-reads the given paths, relative to the root of the store's
+// filesystem, and atomically swaps the affected posts into the store.
+// Paths may name markdown files, bundle assets, directories, or files
+// that have been deleted. A change to config.yaml reloads every post, since the default
+// author applies to all of them, as does a change to series.yaml.
+func (s *EmbeddedStore) Reload(paths ...string) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	for _, path := range paths {
+		if clean := filepath.Clean(path); clean == "config.yaml" || clean == "series.yaml" {
+			return s.reloadAll()
 		}
+	}
 
-		// Skip drafts and future posts
-		if post.Meta.Draft || post.Meta.PublishDate.After(time.Now()) {
-			return nil
+	s.mu.RLock()
+	set := &postSet{
+		posts:   maps.Clone(s.posts),
+		pending: maps.Clone(s.pending),
+		drafts:  maps.Clone(s.drafts),
+		sources: maps.Clone(s.sources),
+	}
+	s.mu.RUnlock()
+
+	// Resolve each changed path to the post source it affects
+	changed := make(map[string]string) // source -> bundle dir
+	for _, path := range paths {
+		source, bundleDir := s.postSource(path)
+		if strings.HasSuffix(source, ".md") {
+			changed[source] = bundleDir
+			continue
+		}
+		if err := s.dirSources(set, path, changed); err != nil {
+			return err
+		}
+	}
+
+	// Drop the old versions first so a renamed slug doesn't collide with itself
+	for source := range changed {
+		set.remove(source)
+	}
+
+	for source, bundleDir := range changed {
+		if exists, _ := afero.Exists(s.fs, source); !exists {
+			continue // Deleted
+		}
+		if err := s.addPost(set, source, bundleDir); err != nil {
+			return err
 		}
+	}
+
+	s.swap(set)
+	return nil
+}
 
-		// Check for slug collision
-		if existing, ok := s.posts[post.Meta.Slug]; ok {
-			return fmt.Errorf("duplicate slug %q: found in both %q and %q",
-				post.Meta.Slug, existing.Meta.Title, post.Meta.Title)
+// dirSources adds to changed the sources of the posts loaded from under
+// dir and of those it holds now. A directory that is renamed, moved or
+// deleted only reports its own path, not the files within it.
+func (s *EmbeddedStore) dirSources(set *postSet, dir string, changed map[string]string) error {
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	for source := range set.sources {
+		if !strings.HasPrefix(source, prefix) {
+			continue
+		}
+		if filepath.Base(source) == "index.md" {
+			changed[source] = filepath.Dir(source)
+		} else {
+			changed[source] = ""
 		}
+	}
 
-		s.posts[post.Meta.Slug] = post
+	if isDir, _ := afero.IsDir(s.fs, dir); !isDir {
+		return nil
+	}
+	return s.walkSources(dir, func(src Source) error {
+		changed[src.Path] = src.BundleDir
 		return nil
 	})
 }
 
+// swap replaces the store's posts with set and rebuilds the indexes.
+func (s *EmbeddedStore) swap(set *postSet) {
+	s.mu.Lock()
+	s.posts, s.pending, s.drafts, s.sources = set.posts, set.pending, set.drafts, set.sources
+	s.buildIndexes()
+	s.mu.Unlock()
+
+	// A reload may have scheduled a post sooner than Schedule expects
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// reloadAll re-reads the config and every post from scratch.
+func (s *EmbeddedStore) reloadAll() error {
+	config, err := s.loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	seriesInfo, err := s.loadSeriesInfo()
+	if err != nil {
+		return fmt.Errorf("loading series: %w", err)
+	}
+
+	// parsePost applies the default author from s.config, so the new
+	// config must be in place before any post is parsed.
+	s.mu.Lock()
+	oldConfig := s.config
+	s.config = config
+	s.mu.Unlock()
+
+	set := newPostSet()
+	if err := s.loadPosts(set); err != nil {
+		s.mu.Lock()
+		s.config = oldConfig
+		s.mu.Unlock()
+		return fmt.Errorf("loading posts: %w", err)
+	}
+
+	s.mu.Lock()
+	s.seriesInfo = seriesInfo
+	s.mu.Unlock()
+
+	s.swap(set)
+	return nil
+}
+
 func (s *EmbeddedStore) parsePost(fs afero.Fs, path string, r Renderer, bundleDir string) (*Post, error) {
 	f, err := fs.Open(path)
 	if err != nil {
@@ -170,11 +496,22 @@ func (s *EmbeddedStore) parsePost(fs afero.Fs, path string, r Renderer, bundleDi
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
 
-	meta, raw, err := parseFrontmatter(content)
+	meta, raw, err := ParseFrontmatter(content)
 	if err != nil {
 		return nil, fmt.Errorf("parsing frontmatter: %w", err)
 	}
 
+	// Fill in dates the frontmatter leaves out
+	if s.dates != nil && (meta.PublishDate.IsZero() || meta.UpdatedDate.IsZero()) {
+		published, updated := s.dates(path, bundleDir)
+		if meta.PublishDate.IsZero() {
+			meta.PublishDate = published
+		}
+		if meta.UpdatedDate.IsZero() && updated.After(meta.PublishDate) {
+			meta.UpdatedDate = updated
+		}
+	}
+
 	// Default slug to filename/dirname without extension
 	if meta.Slug == "" {
 		if bundleDir != "" {
@@ -185,13 +522,25 @@ func (s *EmbeddedStore) parsePost(fs afero.Fs, path string, r Renderer, bundleDi
 		}
 	}
 
-	// Transform relative image paths for page bundles
+	// Resize bundle images and point relative image paths at the bundle
+	var bundleImages map[string]*images.Image
+	var renderCtx *renderer.RenderContext
 	if bundleDir != "" {
-		raw = transformBundleImagePaths(raw, meta.Slug)
+		bundleImages, err = processBundleImages(fs, bundleDir)
+		if err != nil {
+			return nil, err
+		}
+		renderCtx = &renderer.RenderContext{
+			BundleURL: "/posts/" + meta.Slug + "/",
+			Images:    make(map[string]*images.Image, len(bundleImages)),
+		}
+		for name, img := range bundleImages {
+			renderCtx.Images[renderCtx.BundleURL+name] = img
+		}
+		raw = transformBundleImagePaths(raw, meta.Slug, renderCtx.Images)
 	}
 
-	// Build render context with citations from frontmatter
-	var renderCtx *renderer.RenderContext
+	// Add citations from frontmatter to the render context
 	if len(meta.Citations) > 0 {
 		citations := make(map[string]struct {
 			Text string
@@ -203,35 +552,114 @@ func (s *EmbeddedStore) parsePost(fs afero.Fs, path string, r Renderer, bundleDi
 				URL  string
 			}{Text: c.Text, URL: c.URL}
 		}
-		renderCtx = &renderer.RenderContext{
-			Citations: citations,
+		if renderCtx == nil {
+			renderCtx = &renderer.RenderContext{}
 		}
+		renderCtx.Citations = citations
 	}
 
-	html, err := r.Render(raw, renderCtx)
+	doc, err := r.RenderDocument(raw, renderCtx)
 	if err != nil {
 		return nil, fmt.Errorf("rendering markdown: %w", err)
 	}
+	if n := renderer.CountPlaceholders(doc.HTML); n > 0 {
+		unknownShortcodes.Add(float64(n))
+		slog.Warn("Unknown shortcodes left unrendered", "path", path, "count", n)
+	}
 
 	// Calculate word count from raw markdown
 	meta.WordCount = countWords(raw)
 
-	// Apply default author if not specified
-	if meta.Author.Name == "" {
-		meta.Author = s.config.Author
+	sort.SliceStable(meta.Changelog, func(i, j int) bool {
+		return meta.Changelog[i].Date.After(meta.Changelog[j].Date)
+	})
+
+	authors, err := s.resolveAuthors(meta)
+	if err != nil {
+		return nil, err
+	}
+	meta.Authors = authors
+	if len(authors) > 0 {
+		meta.Author = authors[0]
 	}
 
 	return &Post{
 		Meta:        meta,
+		Hash:        hashPost(meta, doc.HTML),
 		RawContent:  raw,
-		HTMLContent: html,
+		HTMLContent: doc.HTML,
+		PlainText:   PlainText(doc.HTML),
+		TOC:         doc.TOC,
 		BundleDir:   bundleDir,
+		Images:      bundleImages,
 	}, nil
 }
 
+// resolveAuthors returns the authors of a post: the config.yaml authors it
+// names by id, otherwise its inline author block, otherwise the site default.
+func (s *EmbeddedStore) resolveAuthors(meta PostMeta) ([]Author, error) {
+	if len(meta.AuthorIDs) > 0 {
+		authors := make([]Author, 0, len(meta.AuthorIDs))
+		for _, id := range meta.AuthorIDs {
+			author, ok := s.config.Authors[id]
+			if !ok {
+				return nil, fmt.Errorf("unknown author %q", id)
+			}
+			authors = append(authors, author)
+		}
+		return authors, nil
+	}
+	if meta.Author.Name != "" {
+		return []Author{meta.Author}, nil
+	}
+	if s.config.Author.Name != "" {
+		return []Author{s.config.Author}, nil
+	}
+	return nil, nil
+}
+
+// processBundleImages resizes the JPEG and PNG files at the top level of a
+// page bundle, keyed by file name. Images that fail to decode are logged
+// and served as they are.
+func processBundleImages(fs afero.Fs, bundleDir string) (map[string]*images.Image, error) {
+	entries, err := afero.ReadDir(fs, bundleDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle: %w", err)
+	}
+
+	result := make(map[string]*images.Image)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isRasterImage(name) {
+			continue
+		}
+		data, err := afero.ReadFile(fs, filepath.Join(bundleDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		img, err := images.Process(data)
+		if err != nil {
+			slog.Warn("Serving bundle image without resized variants", "path", filepath.Join(bundleDir, name), "error", err)
+			continue
+		}
+		result[name] = img
+	}
+	return result, nil
+}
+
+func isRasterImage(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
 // transformBundleImagePaths converts relative image paths to absolute paths for page bundles.
 // e.g., ![alt](image.jpg) -> ![alt](/posts/my-slug/image.jpg)
-func transformBundleImagePaths(content, slug string) string {
+// Images with resized variants become <img> tags with srcset, sizes and
+// intrinsic dimensions instead, so the layout doesn't shift as they load.
+func transformBundleImagePaths(content, slug string, variants map[string]*images.Image) string {
 	return imgRegex.ReplaceAllStringFunc(content, func(match string) string {
 		parts := imgRegex.FindStringSubmatch(match)
 		if len(parts) != 3 {
@@ -243,8 +671,12 @@ func transformBundleImagePaths(content, slug string) string {
 			return match
 		}
 		// Strip leading ./ if present
-		path = strings.TrimPrefix(path, "./")
-		return fmt.Sprintf("![%s](/posts/%s/%s)", alt, slug, path)
+		src := fmt.Sprintf("/posts/%s/%s", slug, strings.TrimPrefix(path, "./"))
+		if img, ok := variants[src]; ok {
+			return fmt.Sprintf(`<img src="%s" alt="%s" srcset="%s" sizes="%s" width="%d" height="%d" loading="lazy" decoding="async">`,
+				html.EscapeString(src), html.EscapeString(alt), html.EscapeString(img.Srcset(src)), images.Sizes, img.Width, img.Height)
+		}
+		return fmt.Sprintf("![%s](%s)", alt, src)
 	})
 }
 
@@ -264,7 +696,9 @@ func countWords(text string) int {
 	return len(words)
 }
 
-func parseFrontmatter(content []byte) (PostMeta, string, error) {
+// ParseFrontmatter splits a post file into its YAML frontmatter and the
+// markdown body that follows it.
+func ParseFrontmatter(content []byte) (PostMeta, string, error) {
 	var meta PostMeta
 
 	reader := bufio.NewReader(bytes.NewReader(content))
@@ -342,6 +776,64 @@ func getTopTags(tagCounts map[string]int, n int) []string {
 	return result
 }
 
+// sortSeries puts a series' posts in reading order: posts with a
+// seriesOrder first, by that order, then the rest by publish date.
+func sortSeries(posts []*Post) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		a, b := posts[i].Meta, posts[j].Meta
+		if (a.SeriesOrder > 0) != (b.SeriesOrder > 0) {
+			return a.SeriesOrder > 0
+		}
+		if a.SeriesOrder != b.SeriesOrder {
+			return a.SeriesOrder < b.SeriesOrder
+		}
+		if !a.PublishDate.Equal(b.PublishDate) {
+			return a.PublishDate.Before(b.PublishDate)
+		}
+		return a.Slug < b.Slug
+	})
+}
+
+// newerFirst orders posts by publish date, newest first, breaking ties by
+// slug so that listings, and the versions hashed from them, don't depend
+// on map iteration order.
+func newerFirst(a, b *Post) bool {
+	if !a.Meta.PublishDate.Equal(b.Meta.PublishDate) {
+		return a.Meta.PublishDate.After(b.Meta.PublishDate)
+	}
+	return a.Meta.Slug < b.Meta.Slug
+}
+
+// archiveKey identifies a year of the archive, or a month of it.
+type archiveKey struct {
+	year  int
+	month time.Month // 0 for the whole year
+}
+
+// buildArchive returns the archive's years and months, latest first, with
+// their post counts.
+func buildArchive(index map[archiveKey][]*Post) []ArchiveYear {
+	archive := []ArchiveYear{}
+	for key, posts := range index {
+		if key.month == 0 {
+			archive = append(archive, ArchiveYear{Year: key.year, Count: len(posts)})
+		}
+	}
+	sort.Slice(archive, func(i, j int) bool {
+		return archive[i].Year > archive[j].Year
+	})
+	for i := range archive {
+		for month := time.December; month >= time.January; month-- {
+			if posts := index[archiveKey{archive[i].Year, month}]; len(posts) > 0 {
+				archive[i].Months = append(archive[i].Months, ArchiveMonth{Month: month, Count: len(posts)})
+			}
+		}
+	}
+	return archive
+}
+
+// buildIndexes rebuilds the derived indexes from s.posts.
+// Callers must hold s.mu for writing once the store is shared.
 func (s *EmbeddedStore) buildIndexes() {
 	// Build sorted list
 	s.sorted = make([]*Post, 0, len(s.posts))
@@ -349,10 +841,11 @@ func (s *EmbeddedStore) buildIndexes() {
 		s.sorted = append(s.sorted, post)
 	}
 	sort.Slice(s.sorted, func(i, j int) bool {
-		return s.sorted[i].Meta.PublishDate.After(s.sorted[j].Meta.PublishDate)
+		return newerFirst(s.sorted[i], s.sorted[j])
 	})
 
 	// Build tag index
+	s.tagIndex = make(map[string][]*Post)
 	tagCounts := make(map[string]int)
 	// Build series index
 	seriesCounts := make(map[string]int)
@@ -386,10 +879,62 @@ func (s *EmbeddedStore) buildIndexes() {
 	for tag := range s.tagIndex {
 		posts := s.tagIndex[tag]
 		sort.Slice(posts, func(i, j int) bool {
-			return posts[i].Meta.PublishDate.After(posts[j].Meta.PublishDate)
+			return newerFirst(posts[i], posts[j])
 		})
 	}
 
+	// Build series index, with each series' posts in reading order
+	s.seriesIndex = make(map[string]*Series)
+	for _, post := range s.sorted {
+		name := post.Meta.Series
+		if name == "" {
+			continue
+		}
+		series, ok := s.seriesIndex[name]
+		if !ok {
+			info := s.seriesInfo[name]
+			if info.Title == "" {
+				info.Title = name
+			}
+			series = &Series{Name: name, Info: info}
+			s.seriesIndex[name] = series
+		}
+		series.Posts = append(series.Posts, post)
+	}
+	for _, series := range s.seriesIndex {
+		sortSeries(series.Posts)
+	}
+
+	// Build archive index, by year and by month
+	s.archiveIndex = make(map[archiveKey][]*Post)
+	for _, post := range s.sorted {
+		year, month := post.Meta.PublishDate.Year(), post.Meta.PublishDate.Month()
+		for _, key := range []archiveKey{{year, 0}, {year, month}} {
+			s.archiveIndex[key] = append(s.archiveIndex[key], post)
+		}
+	}
+	s.archive = buildArchive(s.archiveIndex)
+
+	// Count published posts by each configured author
+	authorCounts := make(map[string]int)
+	for _, post := range s.posts {
+		for _, author := range post.Meta.Authors {
+			if author.ID != "" {
+				authorCounts[author.ID]++
+			}
+		}
+	}
+	s.authors = make([]AuthorCount, 0, len(authorCounts))
+	for id, count := range authorCounts {
+		s.authors = append(s.authors, AuthorCount{Author: s.config.Authors[id], Count: count})
+	}
+	sort.Slice(s.authors, func(i, j int) bool {
+		if s.authors[i].Count != s.authors[j].Count {
+			return s.authors[i].Count > s.authors[j].Count
+		}
+		return s.authors[i].Author.Name < s.authors[j].Author.Name
+	})
+
 	// Build sorted tag counts
 	s.tags = make([]TagCount, 0, len(tagCounts))
 	for tag, count := range tagCounts {
@@ -413,11 +958,18 @@ func (s *EmbeddedStore) buildIndexes() {
 		hasRecentPosts := seriesLatestDate[series].After(sevenDaysAgo)
 		s.series = append(s.series, SeriesCount{
 			Series:         series,
+			Info:           s.seriesIndex[series].Info,
 			Count:          count,
 			TopTags:        topTags,
 			HasRecentPosts: hasRecentPosts,
 		})
 	}
+	// Build full-text search index
+	s.search = buildSearchIndex(s.sorted)
+
+	// Rank related posts for each post
+	s.related = buildRelated(s.sorted, s.tagIndex)
+
 	// Sort series: active (posts within 30 days) first, then by count descending, then alphabetically
 	sort.Slice(s.series, func(i, j int) bool {
 		iActive := seriesLatestDate[s.series[i].Series].After(thirtyDaysAgo)
@@ -433,6 +985,12 @@ func (s *EmbeddedStore) buildIndexes() {
 		// Alphabetical as tiebreaker
 		return s.series[i].Series < s.series[j].Series
 	})
+
+	s.buildVersions()
+
+	if s.indexed != nil {
+		s.indexed()
+	}
 }
 
 // GetPost retrieves a single post by slug.
@@ -447,6 +1005,45 @@ func (s *EmbeddedStore) GetPost(_ context.Context, slug string) (*Post, error) {
 	return post, nil
 }
 
+// GetRelated returns the published posts most similar to the post with the
+// given slug, best first.
+func (s *EmbeddedStore) GetRelated(_ context.Context, slug string) ([]*Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.posts[slug]; !ok {
+		return nil, ErrPostNotFound
+	}
+	return s.related[slug], nil
+}
+
+// GetHistory returns no revisions for a published post: files on disk
+// don't record their history. See GitStore.
+func (s *EmbeddedStore) GetHistory(_ context.Context, slug string) ([]Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.posts[slug]; !ok {
+		return nil, ErrPostNotFound
+	}
+	return nil, nil
+}
+
+// GetPreview retrieves a post that isn't public yet, a draft or a
+// scheduled post, by slug.
+func (s *EmbeddedStore) GetPreview(_ context.Context, slug string) (*Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if post, ok := s.drafts[slug]; ok {
+		return post, nil
+	}
+	if post, ok := s.pending[slug]; ok {
+		return post, nil
+	}
+	return nil, ErrPostNotFound
+}
+
 // ListPosts returns posts matching the given options.
 // Returns posts, total count (before pagination), and any error.
 func (s *EmbeddedStore) ListPosts(_ context.Context, opts ListOptions) ([]*Post, int, error) {
@@ -455,68 +1052,108 @@ func (s *EmbeddedStore) ListPosts(_ context.Context, opts ListOptions) ([]*Post,
 
 	var source []*Post
 
-	// Filter by tag if specified
-	if opts.Tag != "" {
+	// Start from the tag's or period's posts if specified
+	switch {
+	case opts.Tag != "":
 		source = s.tagIndex[opts.Tag]
-	} else {
+	case opts.Year != 0:
+		source = s.archiveIndex[archiveKey{opts.Year, opts.Month}]
+	default:
 		source = s.sorted
 	}
 
-	// Filter by series if specified
+	// Merge in drafts if requested, keeping date order
+	if opts.IncludeDraft && len(s.drafts) > 0 {
+		source = slices.Clone(source)
+		for _, post := range s.drafts {
+			source = append(source, post)
+		}
+		sort.Slice(source, func(i, j int) bool {
+			return newerFirst(source[i], source[j])
+		})
+	}
+
 	var filtered []*Post
 	for _, post := range source {
-		if opts.Series != "" && post.Meta.Series != opts.Series {
-			continue
+		if opts.matches(post) {
+			filtered = append(filtered, post)
 		}
-		filtered = append(filtered, post)
 	}
 
 	// Apply sorting (default is date descending, which is already the default order)
+	field, order := opts.listSort()
 	if opts.SortBy != "" || opts.SortOrder != "" {
-		sortBy := opts.SortBy
-		if sortBy == "" {
-			sortBy = SortByDate
-		}
-		sortOrder := opts.SortOrder
-		if sortOrder == "" {
-			sortOrder = SortDesc
-		}
-
-		sort.SliceStable(filtered, func(i, j int) bool {
-			var less bool
-			switch sortBy {
-			case SortByTitle:
-				less = strings.ToLower(filtered[i].Meta.Title) < strings.ToLower(filtered[j].Meta.Title)
-			case SortByReadingTime:
-				less = filtered[i].Meta.ReadingTime() < filtered[j].Meta.ReadingTime()
-			case SortByDate:
-				fallthrough
-			default:
-				less = filtered[i].Meta.PublishDate.Before(filtered[j].Meta.PublishDate)
-			}
-			if sortOrder == SortDesc {
-				return !less
-			}
-			return less
+		sort.Slice(filtered, func(i, j int) bool {
+			return compareKeys(keyOf(filtered[i]), keyOf(filtered[j]), field, order) < 0
 		})
 	}
 
 	// Capture total count before pagination
 	total := len(filtered)
 
-	// Apply offset and limit
+	if opts.Cursor == nil {
+		return paginate(filtered, opts), total, nil
+	}
+	cursor := *opts.Cursor
+	if err := cursor.check(opts); err != nil {
+		return nil, 0, err
+	}
+	// Posts up to and including the cursor's, for a forward cursor, or
+	// up to but excluding it for a backward one
+	split := sort.Search(len(filtered), func(i int) bool {
+		c := compareKeys(keyOf(filtered[i]), cursor.key, field, order)
+		return c > 0 || cursor.before && c == 0
+	})
+	if !cursor.before {
+		return paginate(filtered[split:], opts), total, nil
+	}
+	// Backwards, the offset and limit count from the cursor too
+	end := max(split-max(opts.Offset, 0), 0)
+	start := 0
+	if opts.Limit > 0 {
+		start = max(end-opts.Limit, 0)
+	}
+	return filtered[start:end], total, nil
+}
+
+// Search returns posts matching a full-text query, ranked by relevance.
+// Returns results, total count (before pagination), and any error.
+func (s *EmbeddedStore) Search(_ context.Context, query string, opts ListOptions) ([]SearchResult, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []SearchResult
+	for _, r := range s.search.search(query) {
+		if opts.matches(r.Post) {
+			results = append(results, r)
+		}
+	}
+
+	total := len(results)
+	results = paginate(results, opts)
+
+	// Only build snippets for the page being returned
+	for i := range results {
+		results[i].Snippet = highlight(results[i].Post.PlainText, query)
+	}
+
+	return results, total, nil
+}
+
+// paginate applies the offset and limit from opts to items.
+func paginate[T any](items []T, opts ListOptions) []T {
 	if opts.Offset > 0 {
-		if opts.Offset >= len(filtered) {
-			return nil, total, nil
+		if opts.Offset >= len(items) {
+			return nil
 		}
-		filtered = filtered[opts.Offset:]
+		items = items[opts.Offset:]
 	}
 
-	if opts.Limit > 0 && opts.Limit < len(filtered) {
-		filtered = filtered[:opts.Limit]
+	if opts.Limit > 0 && opts.Limit < len(items) {
+		items = items[:opts.Limit]
 	}
 
-	return filtered, total, nil
+	return items
 }
 
 // GetTags returns all tags with their post counts.
@@ -527,6 +1164,15 @@ func (s *EmbeddedStore) GetTags(_ context.Context) ([]TagCount, error) {
 	return s.tags, nil
 }
 
+// GetArchive returns the years and months with published posts, latest
+// first, with their post counts.
+func (s *EmbeddedStore) GetArchive(_ context.Context) ([]ArchiveYear, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.archive, nil
+}
+
 // GetSeries returns all series with their post counts.
 func (s *EmbeddedStore) GetSeries(_ context.Context) ([]SeriesCount, error) {
 	s.mu.RLock()
@@ -535,6 +1181,59 @@ func (s *EmbeddedStore) GetSeries(_ context.Context) ([]SeriesCount, error) {
 	return s.series, nil
 }
 
+// GetAuthors returns the authors defined in config.yaml that have published
+// posts, with their post counts, most prolific first.
+func (s *EmbeddedStore) GetAuthors(_ context.Context) ([]AuthorCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.authors, nil
+}
+
+// GetAuthor returns the author defined in config.yaml with the given id.
+func (s *EmbeddedStore) GetAuthor(_ context.Context, id string) (Author, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	author, ok := s.config.Authors[id]
+	if !ok {
+		return Author{}, ErrAuthorNotFound
+	}
+	return author, nil
+}
+
+// GetSeriesByName returns the named series with its published posts in
+// reading order. The returned Series must not be modified.
+func (s *EmbeddedStore) GetSeriesByName(_ context.Context, name string) (*Series, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series, ok := s.seriesIndex[name]
+	if !ok {
+		return nil, ErrSeriesNotFound
+	}
+	return series, nil
+}
+
+// GetPostImage returns the resized variants of an image in a post's bundle
+// directory. Returns an error if the post isn't published or the file has
+// no variants.
+func (s *EmbeddedStore) GetPostImage(_ context.Context, slug, filename string) (*images.Image, error) {
+	s.mu.RLock()
+	post, ok := s.posts[slug]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrPostNotFound
+	}
+
+	img, ok := post.Images[filename]
+	if !ok {
+		return nil, fmt.Errorf("image not found: %s", filename)
+	}
+	return img, nil
+}
+
 // GetPostAsset retrieves an asset file from a post's bundle directory.
 // Returns the file contents and an error if not found or not a bundle.
 func (s *EmbeddedStore) GetPostAsset(_ context.Context, slug, filename string) ([]byte, error) {
@@ -0,0 +1,442 @@
+package content
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Compile-time interface compliance check.
+var _ ContentStore = (*GitStore)(nil)
+
+// GitStore is a ContentStore that reads posts from a git repository, bare
+// or with a working tree, at a given ref. Posts whose frontmatter leaves out
+// publishDate are dated by the first commit that added them, and updatedDate
+// defaults to the last commit that changed them. It shells out to the git
+// command, which must be installed.
+//
+// The tree at the ref is copied into memory and served by an embedded
+// EmbeddedStore, so everything but GetHistory behaves the same as for posts
+// on disk. Sync reloads the posts a new commit changed.
+type GitStore struct {
+	*EmbeddedStore
+
+	repo string   // Path to the repository
+	dir  string   // Directory within the repository holding the posts
+	fs   afero.Fs // The posts directory at the loaded commit
+
+	// syncMu serializes Sync and SetRef, and guards the fields below.
+	syncMu sync.Mutex
+	ref    string
+	commit string            // Loaded commit
+	blobs  map[string]string // File path relative to dir -> blob id in fs
+
+	historyMu sync.RWMutex
+	history   []gitCommit // Commits at or before commit touching dir, newest first
+}
+
+// gitCommit is a commit with the files it changed, relative to the posts
+// directory.
+type gitCommit struct {
+	Revision
+	files []string
+}
+
+// NewGitStore creates a store from the posts in dir, a directory within the
+// repository at repo ("" for its root), at ref. All posts are parsed and
+// rendered immediately.
+func NewGitStore(repo, dir, ref string, renderer Renderer) (*GitStore, error) {
+	g := &GitStore{
+		repo:  repo,
+		dir:   strings.Trim(filepath.ToSlash(dir), "/"),
+		fs:    afero.NewMemMapFs(),
+		ref:   ref,
+		blobs: make(map[string]string),
+	}
+
+	commit, err := g.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.checkout(commit); err != nil {
+		return nil, err
+	}
+	g.commit = commit
+
+	store := newEmbeddedStore(g.fs, renderer)
+	store.dates = g.dates
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	g.EmbeddedStore = store
+
+	slog.Info("Loaded content from git", "repo", repo, "ref", ref, "commit", shortID(commit))
+	return g, nil
+}
+
+// Ref returns the ref the store follows and the commit it has loaded.
+func (g *GitStore) Ref() (ref, commit string) {
+	g.syncMu.Lock()
+	defer g.syncMu.Unlock()
+	return g.ref, g.commit
+}
+
+// SetRef switches the store to another ref, such as a branch or tag, and
+// reloads every post from it. On error the store keeps its current ref.
+func (g *GitStore) SetRef(ref string) error {
+	g.syncMu.Lock()
+	defer g.syncMu.Unlock()
+
+	if ref == g.ref {
+		return nil
+	}
+	commit, err := g.resolve(ref)
+	if err != nil {
+		return err
+	}
+	if err := g.update(commit, true); err != nil {
+		return err
+	}
+
+	slog.Info("Switched content ref", "ref", ref, "commit", shortID(commit))
+	g.ref = ref
+	return nil
+}
+
+// Sync reloads the posts changed since the loaded commit if the ref has
+// moved. Commits that rewrite history reload every post.
+func (g *GitStore) Sync() error {
+	g.syncMu.Lock()
+	defer g.syncMu.Unlock()
+
+	commit, err := g.resolve(g.ref)
+	if err != nil {
+		return err
+	}
+	if commit == g.commit {
+		return nil
+	}
+
+	// Dates of unchanged posts only hold while the old commit is still in
+	// the ref's history; merge-base exits non-zero when it isn't
+	_, err = g.git(nil, "merge-base", "--is-ancestor", g.commit, commit)
+	if err := g.update(commit, err != nil); err != nil {
+		return err
+	}
+
+	slog.Info("Synced content", "ref", g.ref, "commit", shortID(commit))
+	return nil
+}
+
+// Poll calls Sync every interval until ctx is cancelled, so commits pushed
+// to the ref go live without a restart. Sync failures are logged and the
+// previous content is kept.
+func (g *GitStore) Poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.Sync(); err != nil {
+				ref, _ := g.Ref()
+				slog.Error("Failed to sync content", "ref", ref, "error", err)
+			}
+		}
+	}
+}
+
+// update checks out commit and reloads the posts it changed, or every
+// post if all is set. If the reload fails, the loaded commit's files are
+// checked out again, so the next sync diffs from the posts being served.
+// Must be called with syncMu held.
+func (g *GitStore) update(commit string, all bool) error {
+	changed, err := g.checkout(commit)
+	if err != nil {
+		return err
+	}
+	if err := g.reload(changed, all); err != nil {
+		if _, restoreErr := g.checkout(g.commit); restoreErr != nil {
+			return errors.Join(err, fmt.Errorf("restoring commit %s: %w", shortID(g.commit), restoreErr))
+		}
+		return err
+	}
+	g.commit = commit
+	return nil
+}
+
+// reload reloads the posts at the changed paths, or every post if all is
+// set.
+func (g *GitStore) reload(changed []string, all bool) error {
+	if all {
+		g.reloadMu.Lock()
+		defer g.reloadMu.Unlock()
+		return g.reloadAll()
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return g.Reload(changed...)
+}
+
+// GetHistory returns the commits that changed a published post, or any
+// file in its bundle, newest first.
+func (g *GitStore) GetHistory(_ context.Context, slug string) ([]Revision, error) {
+	g.mu.RLock()
+	post, ok := g.posts[slug]
+	var source string
+	for src, s := range g.sources {
+		if s == slug {
+			source = src
+			break
+		}
+	}
+	g.mu.RUnlock()
+	if !ok {
+		return nil, ErrPostNotFound
+	}
+
+	history := g.revisions(source, post.BundleDir)
+	revisions := make([]Revision, len(history))
+	for i, c := range history {
+		revisions[i] = c.Revision
+	}
+	return revisions, nil
+}
+
+// dates returns the dates of the first and last commits that changed a
+// post, for EmbeddedStore.dates.
+func (g *GitStore) dates(source, bundleDir string) (published, updated time.Time) {
+	history := g.revisions(source, bundleDir)
+	if len(history) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return history[len(history)-1].Date, history[0].Date
+}
+
+// revisions returns the loaded commits that changed source or any file
+// under bundleDir, newest first.
+func (g *GitStore) revisions(source, bundleDir string) []gitCommit {
+	source = filepath.ToSlash(source)
+	prefix := filepath.ToSlash(bundleDir) + "/"
+
+	g.historyMu.RLock()
+	defer g.historyMu.RUnlock()
+
+	var matched []gitCommit
+	for _, c := range g.history {
+		for _, file := range c.files {
+			if file == source || (bundleDir != "" && strings.HasPrefix(file, prefix)) {
+				matched = append(matched, c)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// checkout copies the posts directory at commit into g.fs, writing only
+// files whose contents changed, and loads its history. Returns the paths
+// that were written or removed. It doesn't record commit as loaded; that
+// is left to the caller once the posts are reloaded. Must be called with
+// syncMu held, or before the store is shared.
+func (g *GitStore) checkout(commit string) ([]string, error) {
+	tree, err := g.lsTree(commit)
+	if err != nil {
+		return nil, err
+	}
+	history, err := g.log(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed, ids []string
+	for file, id := range tree {
+		if g.blobs[file] != id {
+			changed = append(changed, file)
+			ids = append(ids, id)
+		}
+	}
+	blobs, err := g.catFiles(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Install the new history first so reloaded posts are dated from it
+	g.historyMu.Lock()
+	g.history = history
+	g.historyMu.Unlock()
+
+	for i, file := range changed {
+		name := filepath.FromSlash(file)
+		if err := g.fs.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return nil, fmt.Errorf("creating directory for %s: %w", file, err)
+		}
+		if err := afero.WriteFile(g.fs, name, blobs[ids[i]], 0o644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", file, err)
+		}
+	}
+	for file := range g.blobs {
+		if _, ok := tree[file]; !ok {
+			if err := g.fs.Remove(filepath.FromSlash(file)); err != nil {
+				return nil, fmt.Errorf("removing %s: %w", file, err)
+			}
+			changed = append(changed, file)
+		}
+	}
+
+	g.blobs = tree
+	return changed, nil
+}
+
+// resolve returns the commit a ref points to.
+func (g *GitStore) resolve(ref string) (string, error) {
+	out, err := g.git(nil, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("resolving ref %q: %w", ref, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// lsTree lists the files in the posts directory at commit, keyed by path
+// relative to the directory, with their blob ids.
+func (g *GitStore) lsTree(commit string) (map[string]string, error) {
+	treeish := commit
+	if g.dir != "" {
+		treeish += ":" + g.dir
+	}
+	out, err := g.git(nil, "ls-tree", "-r", "-z", treeish)
+	if err != nil {
+		return nil, fmt.Errorf("listing files: %w", err)
+	}
+
+	// Each entry is "<mode> <type> <id>\t<path>\x00"
+	tree := make(map[string]string)
+	for entry := range strings.SplitSeq(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		info, file, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 || fields[1] != "blob" {
+			continue // Submodules have no contents to serve
+		}
+		tree[file] = fields[2]
+	}
+	return tree, nil
+}
+
+// catFiles reads the blobs with the given ids in one git process.
+func (g *GitStore) catFiles(ids []string) (map[string][]byte, error) {
+	blobs := make(map[string][]byte, len(ids))
+	if len(ids) == 0 {
+		return blobs, nil
+	}
+
+	out, err := g.git(strings.NewReader(strings.Join(ids, "\n")+"\n"), "cat-file", "--batch")
+	if err != nil {
+		return nil, fmt.Errorf("reading files: %w", err)
+	}
+
+	// Each blob is "<id> <type> <size>\n<contents>\n"
+	r := bufio.NewReader(bytes.NewReader(out))
+	for range ids {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("reading files: %w", err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("reading files: unexpected header %q", strings.TrimSpace(header))
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("reading files: unexpected header %q", strings.TrimSpace(header))
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("reading files: %w", err)
+		}
+		if _, err := r.Discard(1); err != nil {
+			return nil, fmt.Errorf("reading files: %w", err)
+		}
+		blobs[fields[0]] = data
+	}
+	return blobs, nil
+}
+
+// log returns the commits reachable from commit that changed the posts
+// directory, newest first, with the files each changed.
+func (g *GitStore) log(commit string) ([]gitCommit, error) {
+	args := []string{"log", "-z", "--name-only", "--no-renames", "--format=%x1e%H%x1f%cI%x1f%an%x1f%s", commit}
+	if g.dir != "" {
+		args = append(args, "--relative="+g.dir, "--", g.dir)
+	}
+	out, err := g.git(nil, args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+
+	// Each commit is "\x1e<header>\x00\n<file>\x00<file>\x00..."
+	var history []gitCommit
+	for record := range strings.SplitSeq(string(out), "\x1e") {
+		if record == "" {
+			continue
+		}
+		fields := strings.Split(record, "\x00")
+		header := strings.Split(fields[0], "\x1f")
+		if len(header) != 4 {
+			return nil, fmt.Errorf("reading history: unexpected commit %q", fields[0])
+		}
+		date, err := time.Parse(time.RFC3339, header[1])
+		if err != nil {
+			return nil, fmt.Errorf("reading history: %w", err)
+		}
+
+		c := gitCommit{Revision: Revision{ID: header[0], Date: date, Author: header[2], Message: header[3]}}
+		for _, file := range fields[1:] {
+			if file = strings.TrimPrefix(file, "\n"); file != "" {
+				c.files = append(c.files, path.Clean(file))
+			}
+		}
+		history = append(history, c)
+	}
+	return history, nil
+}
+
+// git runs a git command in the repository and returns its output.
+func (g *GitStore) git(stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", g.repo}, args...)...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// shortID abbreviates a commit hash for logs.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,294 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// gitFixture is a throwaway repository for GitStore tests.
+type gitFixture struct {
+	t   *testing.T
+	dir string
+}
+
+func newGitFixture(t *testing.T) *gitFixture {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	f := &gitFixture{t: t, dir: t.TempDir()}
+	f.run(time.Time{}, "init", "-q", "-b", "main")
+	return f
+}
+
+// run runs git in the fixture, committing at date if it isn't zero.
+func (f *gitFixture) run(date time.Time, args ...string) {
+	f.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", f.dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+		"GIT_COMMITTER_NAME=Alice", "GIT_COMMITTER_EMAIL=alice@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+	if !date.IsZero() {
+		stamp := date.Format(time.RFC3339)
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_DATE="+stamp, "GIT_COMMITTER_DATE="+stamp)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		f.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func (f *gitFixture) write(path, data string) {
+	f.t.Helper()
+	full := filepath.Join(f.dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(data), 0o644); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *gitFixture) commit(date time.Time, message string) {
+	f.t.Helper()
+	f.run(time.Time{}, "add", "-A")
+	f.run(date, "commit", "-q", "-m", message)
+}
+
+func TestGitStore(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+
+	f := newGitFixture(t)
+	f.write("README.md", "Not a post.")
+	f.write("posts/undated.md", "---\ntitle: Undated\nslug: undated\n---\nFirst draft.")
+	f.write("posts/bundle/index.md", "---\ntitle: Bundle\nslug: bundle\npublishDate: 2023-12-25T00:00:00Z\n---\nSee the photo.")
+	f.commit(day(1), "Add posts")
+	f.run(time.Time{}, "branch", "first")
+
+	f.write("posts/undated.md", "---\ntitle: Undated\nslug: undated\n---\nSecond draft.")
+	f.commit(day(2), "Revise undated")
+	f.write("posts/bundle/photo.txt", "not really a photo")
+	f.commit(day(3), "Add photo to bundle")
+	f.write("README.md", "Still not a post.")
+	f.commit(day(4), "Touch readme")
+
+	store, err := NewGitStore(f.dir, "posts", "main", &mockRenderer{})
+	if err != nil {
+		t.Fatalf("NewGitStore() error = %v", err)
+	}
+
+	t.Run("dates from history", func(t *testing.T) {
+		undated, err := store.GetPost(ctx, "undated")
+		if err != nil {
+			t.Fatalf("GetPost(undated) error = %v", err)
+		}
+		if !undated.Meta.PublishDate.Equal(day(1)) {
+			t.Errorf("PublishDate = %v, want first commit %v", undated.Meta.PublishDate, day(1))
+		}
+		if !undated.Meta.UpdatedDate.Equal(day(2)) {
+			t.Errorf("UpdatedDate = %v, want last commit %v", undated.Meta.UpdatedDate, day(2))
+		}
+
+		bundle, err := store.GetPost(ctx, "bundle")
+		if err != nil {
+			t.Fatalf("GetPost(bundle) error = %v", err)
+		}
+		if want := time.Date(2023, 12, 25, 0, 0, 0, 0, time.UTC); !bundle.Meta.PublishDate.Equal(want) {
+			t.Errorf("PublishDate = %v, want frontmatter date %v", bundle.Meta.PublishDate, want)
+		}
+		if !bundle.Meta.UpdatedDate.Equal(day(3)) {
+			t.Errorf("UpdatedDate = %v, want bundle asset commit %v", bundle.Meta.UpdatedDate, day(3))
+		}
+	})
+
+	t.Run("history", func(t *testing.T) {
+		tests := []struct {
+			slug string
+			want []string
+		}{
+			{"undated", []string{"Revise undated", "Add posts"}},
+			{"bundle", []string{"Add photo to bundle", "Add posts"}},
+		}
+		for _, tt := range tests {
+			revisions, err := store.GetHistory(ctx, tt.slug)
+			if err != nil {
+				t.Fatalf("GetHistory(%s) error = %v", tt.slug, err)
+			}
+			var got []string
+			for _, r := range revisions {
+				got = append(got, r.Message)
+				if r.ID == "" || r.Author != "Alice" {
+					t.Errorf("revision = %+v, want ID and author", r)
+				}
+			}
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Errorf("GetHistory(%s) = %v, want %v", tt.slug, got, tt.want)
+			}
+		}
+
+		if _, err := store.GetHistory(ctx, "missing"); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetHistory(missing) error = %v, want ErrPostNotFound", err)
+		}
+	})
+
+	t.Run("bundle assets", func(t *testing.T) {
+		data, err := store.GetPostAsset(ctx, "bundle", "photo.txt")
+		if err != nil || string(data) != "not really a photo" {
+			t.Errorf("GetPostAsset() = %q, %v", data, err)
+		}
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		f.run(time.Time{}, "rm", "-q", "posts/bundle/index.md", "posts/bundle/photo.txt")
+		f.write("posts/new.md", "---\ntitle: New\nslug: new\n---\nFresh.")
+		f.commit(day(5), "Replace bundle with new post")
+
+		if err := store.Sync(); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+		if _, err := store.GetPost(ctx, "bundle"); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetPost(bundle) error = %v, want ErrPostNotFound after deletion", err)
+		}
+		post, err := store.GetPost(ctx, "new")
+		if err != nil {
+			t.Fatalf("GetPost(new) error = %v", err)
+		}
+		if !post.Meta.PublishDate.Equal(day(5)) {
+			t.Errorf("PublishDate = %v, want %v", post.Meta.PublishDate, day(5))
+		}
+	})
+
+	t.Run("set ref", func(t *testing.T) {
+		if err := store.SetRef("first"); err != nil {
+			t.Fatalf("SetRef() error = %v", err)
+		}
+		if ref, _ := store.Ref(); ref != "first" {
+			t.Errorf("Ref() = %q, want first", ref)
+		}
+
+		post, err := store.GetPost(ctx, "undated")
+		if err != nil {
+			t.Fatalf("GetPost(undated) error = %v", err)
+		}
+		if post.RawContent != "First draft." {
+			t.Errorf("RawContent = %q, want the first commit's", post.RawContent)
+		}
+		if !post.Meta.UpdatedDate.IsZero() {
+			t.Errorf("UpdatedDate = %v, want none with a single commit", post.Meta.UpdatedDate)
+		}
+		if _, err := store.GetPost(ctx, "new"); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetPost(new) error = %v, want ErrPostNotFound on the old ref", err)
+		}
+
+		if err := store.SetRef("no-such-branch"); err == nil {
+			t.Error("SetRef(no-such-branch) error = nil, want error")
+		}
+		if ref, _ := store.Ref(); ref != "first" {
+			t.Errorf("Ref() after failed switch = %q, want first", ref)
+		}
+	})
+
+	t.Run("bare repository", func(t *testing.T) {
+		bare := filepath.Join(t.TempDir(), "bare.git")
+		f.run(time.Time{}, "clone", "-q", "--bare", f.dir, bare)
+
+		store, err := NewGitStore(bare, "posts", "main", &mockRenderer{})
+		if err != nil {
+			t.Fatalf("NewGitStore(bare) error = %v", err)
+		}
+		if _, err := store.GetPost(ctx, "new"); err != nil {
+			t.Errorf("GetPost(new) error = %v", err)
+		}
+	})
+}
+
+func TestGitStore_FailedSync(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	post := func(slug, body string) string {
+		return "---\ntitle: " + slug + "\nslug: " + slug + "\npublishDate: 2024-01-01T00:00:00Z\n---\n" + body
+	}
+
+	f := newGitFixture(t)
+	f.write("a.md", post("a", "A1"))
+	f.write("b.md", post("b", "B1"))
+	f.commit(day(1), "Add posts")
+
+	store, err := NewGitStore(f.dir, "", "main", &mockRenderer{})
+	if err != nil {
+		t.Fatalf("NewGitStore() error = %v", err)
+	}
+	_, loaded := store.Ref()
+	body := func(slug string) string {
+		t.Helper()
+		p, err := store.GetPost(ctx, slug)
+		if err != nil {
+			t.Fatalf("GetPost(%s) error = %v", slug, err)
+		}
+		return p.RawContent
+	}
+
+	// Edit a and c, and break b in the same commit
+	f.write("a.md", post("a", "A2"))
+	f.write("b.md", "---\ntitle: [\n---\nBroken.")
+	f.write("c.md", post("c", "C2"))
+	f.commit(day(2), "Edit a and c, break b")
+	if err := store.Sync(); err == nil {
+		t.Fatal("Sync() error = nil, want error for the broken post")
+	}
+	if _, commit := store.Ref(); commit != loaded {
+		t.Errorf("commit after failed sync = %s, want %s still loaded", commit, loaded)
+	}
+	if got := body("a"); got != "A1" {
+		t.Errorf("a after failed sync = %q, want A1", got)
+	}
+
+	// Fix b and put c back, so only b differs from the failed commit
+	f.write("b.md", post("b", "B3"))
+	f.run(time.Time{}, "rm", "-q", "c.md")
+	f.commit(day(3), "Fix b, drop c")
+	if err := store.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := body("a"); got != "A2" {
+		t.Errorf("a after recovery = %q, want A2 from the failed commit", got)
+	}
+	if got := body("b"); got != "B3" {
+		t.Errorf("b after recovery = %q, want B3", got)
+	}
+	if _, err := store.GetPost(ctx, "c"); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("GetPost(c) error = %v, want ErrPostNotFound", err)
+	}
+
+	// A ref that fails to load leaves the current one in place
+	f.run(time.Time{}, "checkout", "-q", "-b", "broken")
+	f.write("a.md", "---\ntitle: [\n---\nBroken.")
+	f.commit(day(4), "Break a")
+	f.run(time.Time{}, "checkout", "-q", "main")
+	_, loaded = store.Ref()
+	if err := store.SetRef("broken"); err == nil {
+		t.Fatal("SetRef(broken) error = nil, want error")
+	}
+	if ref, commit := store.Ref(); ref != "main" || commit != loaded {
+		t.Errorf("Ref() after failed switch = %s, %s, want main, %s", ref, commit, loaded)
+	}
+
+	f.write("b.md", post("b", "B5"))
+	f.commit(day(5), "Edit b")
+	if err := store.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := body("a"); got != "A2" {
+		t.Errorf("a after sync = %q, want A2", got)
+	}
+	if got := body("b"); got != "B5" {
+		t.Errorf("b after sync = %q, want B5", got)
+	}
+}
@@ -0,0 +1,37 @@
+package content
+
+import "therefore/internal/metrics"
+
+var (
+	loadDuration = metrics.NewHistogramVec("therefore_content_load_seconds",
+		"Time to load and render every post, at startup and on full reloads.", metrics.DefaultBuckets)
+	renderDuration = metrics.NewHistogramVec("therefore_content_render_seconds",
+		"Time to parse and render a single post.", metrics.DefaultBuckets)
+	unknownShortcodes = metrics.NewCounterVec("therefore_content_unknown_shortcodes_total",
+		"Shortcode placeholders left in rendered HTML because no renderer handles them.")
+)
+
+// Stats counts what the store holds.
+type Stats struct {
+	Posts     int // Published
+	Drafts    int
+	Scheduled int // Waiting for a future publish date
+	Tags      int
+	Series    int
+	Authors   int
+}
+
+// Stats returns the current counts of posts by state, tags, series and
+// authors.
+func (s *EmbeddedStore) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Stats{
+		Posts:     len(s.posts),
+		Drafts:    len(s.drafts),
+		Scheduled: len(s.pending),
+		Tags:      len(s.tags),
+		Series:    len(s.series),
+		Authors:   len(s.authors),
+	}
+}
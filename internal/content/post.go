@@ -1,12 +1,20 @@
 package content
 
-import "time"
+import (
+	"slices"
+	"time"
+
+	"therefore/internal/images"
+	"therefore/internal/renderer"
+)
 
 // Author contains information about the post author.
 type Author struct {
+	ID     string `yaml:"-"` // Key in the config.yaml authors map; empty for inline authors
 	Name   string `yaml:"name"`
 	Avatar string `yaml:"avatar"` // URL or path to avatar image
 	Bio    string `yaml:"bio"`
+	URL    string `yaml:"url,omitempty"` // Personal site or profile
 }
 
 // Citation represents a reusable citation defined in frontmatter.
@@ -15,20 +23,69 @@ type Citation struct {
 	URL  string `yaml:"url"`  // Link to source
 }
 
+// ChangelogEntry is a dated note describing a revision to a post.
+type ChangelogEntry struct {
+	Date time.Time `yaml:"date"`
+	Note string    `yaml:"note"`
+}
+
+// Revision is a recorded change to a post, such as a git commit.
+type Revision struct {
+	ID      string    // Commit hash
+	Date    time.Time // When the change was committed
+	Author  string
+	Message string // First line of the commit message
+}
+
 // PostMeta contains metadata parsed from YAML frontmatter.
 type PostMeta struct {
 	Title       string              `yaml:"title"`
 	Slug        string              `yaml:"slug"`
 	Summary     string              `yaml:"summary"`
 	Series      string              `yaml:"series,omitempty"`
+	SeriesOrder int                 `yaml:"seriesOrder,omitempty"` // Position in the series; unordered posts follow by publish date
 	PublishDate time.Time           `yaml:"publishDate"`
+	UpdatedDate time.Time           `yaml:"updatedDate,omitempty"`
+	Changelog   []ChangelogEntry    `yaml:"changelog,omitempty"` // Sorted newest first after loading
 	Draft       bool                `yaml:"draft,omitempty"`
 	Tags        []string            `yaml:"tags,omitempty"`
-	Author      Author              `yaml:"author,omitempty"`
+	Author      Author              `yaml:"author,omitempty"`    // Inline author; after loading, the primary author
+	AuthorIDs   []string            `yaml:"authors,omitempty"`   // Ids of authors defined in config.yaml
+	Authors     []Author            `yaml:"-"`                   // Resolved from AuthorIDs, Author or the site default
 	Citations   map[string]Citation `yaml:"citations,omitempty"` // Alias -> Citation mapping
 	WordCount   int                 `yaml:"-"`                   // Computed from content, not parsed from YAML
 }
 
+// HasAuthor reports whether the author with the given id wrote the post.
+func (m PostMeta) HasAuthor(id string) bool {
+	for _, a := range m.Authors {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// LastModified returns the latest of the publish date, the updated date and
+// the changelog dates.
+func (m PostMeta) LastModified() time.Time {
+	latest := m.PublishDate
+	if m.UpdatedDate.After(latest) {
+		latest = m.UpdatedDate
+	}
+	for _, entry := range m.Changelog {
+		if entry.Date.After(latest) {
+			latest = entry.Date
+		}
+	}
+	return latest
+}
+
+// Revised reports whether the post was modified after it was published.
+func (m PostMeta) Revised() bool {
+	return m.LastModified().After(m.PublishDate)
+}
+
 // ReadingTime returns the estimated reading time in minutes.
 // Assumes ~200 words per minute reading speed.
 func (m PostMeta) ReadingTime() int {
@@ -42,9 +99,13 @@ func (m PostMeta) ReadingTime() int {
 // Post represents a blog post with metadata and content.
 type Post struct {
 	Meta        PostMeta
-	RawContent  string // Original markdown without frontmatter
-	HTMLContent string // Rendered HTML
-	BundleDir   string // Directory path for page bundles (empty for standalone posts)
+	Hash        string                   // Digest of the metadata and rendered HTML
+	RawContent  string                   // Original markdown without frontmatter
+	HTMLContent string                   // Rendered HTML
+	PlainText   string                   // Rendered HTML stripped to plain text, for search
+	TOC         []renderer.Heading       // Heading outline of the rendered content
+	BundleDir   string                   // Directory path for page bundles (empty for standalone posts)
+	Images      map[string]*images.Image // Resized variants of bundle images, keyed by file name
 }
 
 // SortField represents the field to sort posts by.
@@ -69,15 +130,86 @@ const (
 	SortDesc SortOrder = "desc"
 )
 
+// TagMode says how a post must match the tags in ListOptions.Tags.
+type TagMode string
+
+const (
+	// TagMatchAll requires every tag. It is the default.
+	TagMatchAll TagMode = "all"
+	// TagMatchAny requires at least one tag.
+	TagMatchAny TagMode = "any"
+)
+
 // ListOptions configures post list queries.
 type ListOptions struct {
-	Tag          string
-	Series       string
-	IncludeDraft bool
-	Limit        int
-	Offset       int
-	SortBy       SortField
-	SortOrder    SortOrder
+	Tag            string
+	Tags           []string // Matched by TagMode, as well as Tag
+	TagMode        TagMode
+	ExcludeTags    []string // Posts must have none of these
+	Series         string
+	Author         string     // Author id
+	From           time.Time  // Published at or after, if set
+	To             time.Time  // Published at or before, if set
+	MinReadingTime int        // Minutes, if set
+	MaxReadingTime int        // Minutes, if set
+	Year           int        // Published in this year, if set
+	Month          time.Month // And in this month of it, if set
+	IncludeDraft   bool
+	Cursor         *Cursor // Page from here rather than the start; Offset counts from it
+	Limit          int
+	Offset         int
+	SortBy         SortField
+	SortOrder      SortOrder
+}
+
+// matches reports whether post passes the filters in opts. It doesn't
+// check the draft status, which stores track separately.
+func (opts ListOptions) matches(post *Post) bool {
+	meta := post.Meta
+	if opts.Tag != "" && !slices.Contains(meta.Tags, opts.Tag) {
+		return false
+	}
+	if len(opts.Tags) > 0 {
+		matched := 0
+		for _, tag := range opts.Tags {
+			if slices.Contains(meta.Tags, tag) {
+				matched++
+			}
+		}
+		if matched == 0 || opts.TagMode != TagMatchAny && matched < len(opts.Tags) {
+			return false
+		}
+	}
+	for _, tag := range opts.ExcludeTags {
+		if slices.Contains(meta.Tags, tag) {
+			return false
+		}
+	}
+	if opts.Series != "" && meta.Series != opts.Series {
+		return false
+	}
+	if opts.Author != "" && !meta.HasAuthor(opts.Author) {
+		return false
+	}
+	if !opts.From.IsZero() && meta.PublishDate.Before(opts.From) {
+		return false
+	}
+	if !opts.To.IsZero() && meta.PublishDate.After(opts.To) {
+		return false
+	}
+	if opts.Year != 0 && meta.PublishDate.Year() != opts.Year {
+		return false
+	}
+	if opts.Month != 0 && meta.PublishDate.Month() != opts.Month {
+		return false
+	}
+	if opts.MinReadingTime > 0 && meta.ReadingTime() < opts.MinReadingTime {
+		return false
+	}
+	if opts.MaxReadingTime > 0 && meta.ReadingTime() > opts.MaxReadingTime {
+		return false
+	}
+	return true
 }
 
 // TagCount represents a tag with its post count.
@@ -86,9 +218,56 @@ type TagCount struct {
 	Count int
 }
 
+// AuthorCount represents an author with their published post count.
+type AuthorCount struct {
+	Author Author
+	Count  int
+}
+
+// SeriesInfo describes a series. It is read from series.yaml, keyed by
+// series name, and every field is optional.
+type SeriesInfo struct {
+	Title       string `yaml:"title"` // Display title, defaults to the series name
+	Description string `yaml:"description"`
+	Cover       string `yaml:"cover"` // URL or path to a cover image
+}
+
+// Series is a series with its posts in reading order.
+type Series struct {
+	Name  string
+	Info  SeriesInfo
+	Posts []*Post
+}
+
+// Part returns the 1-based position of the post with the given slug in
+// the series, or 0 if it isn't part of it.
+func (s *Series) Part(slug string) int {
+	for i, post := range s.Posts {
+		if post.Meta.Slug == slug {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ArchiveYear represents a year with published posts, with its post count
+// in total and by month.
+type ArchiveYear struct {
+	Year   int
+	Count  int
+	Months []ArchiveMonth // Months with posts, latest first
+}
+
+// ArchiveMonth represents a month with published posts and its post count.
+type ArchiveMonth struct {
+	Month time.Month
+	Count int
+}
+
 // SeriesCount represents a series with its post count.
 type SeriesCount struct {
 	Series         string
+	Info           SeriesInfo
 	Count          int
 	TopTags        []string // Top 3 most common tags across posts in this series
 	HasRecentPosts bool     // True if any post in the series was published within the last 7 days
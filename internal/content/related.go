@@ -0,0 +1,153 @@
+package content
+
+import (
+	"maps"
+	"math"
+	"sort"
+)
+
+// Weights for combining the related-post signals. Each signal is scaled to
+// [0, 1] before weighting, so the total score is also in [0, 1].
+const (
+	relatedTagWeight    = 0.4
+	relatedSeriesWeight = 0.2
+	relatedTextWeight   = 0.4
+)
+
+// relatedLimit is the number of related posts kept for each post.
+const relatedLimit = 3
+
+// minRelatedScore drops candidates that have almost nothing in common with
+// the post, so an unrelated post isn't suggested just to fill the list.
+const minRelatedScore = 0.05
+
+// buildRelated ranks, for every post, the other posts most like it. Posts
+// are compared on shared tags weighted by how rare each tag is, membership
+// of the same series, and the cosine similarity of their TF-IDF vectors.
+func buildRelated(posts []*Post, tagIndex map[string][]*Post) map[string][]*Post {
+	tagIDF := make(map[string]float64, len(tagIndex))
+	for tag, tagged := range tagIndex {
+		tagIDF[tag] = math.Log(1 + float64(len(posts))/float64(len(tagged)))
+	}
+	vectors := termVectors(posts)
+
+	type candidate struct {
+		post  *Post
+		score float64
+	}
+
+	related := make(map[string][]*Post, len(posts))
+	for i, post := range posts {
+		var candidates []candidate
+		for j, other := range posts {
+			if i == j {
+				continue
+			}
+			score := relatedTagWeight*tagSimilarity(post.Meta.Tags, other.Meta.Tags, tagIDF) +
+				relatedTextWeight*cosine(vectors[i], vectors[j])
+			if post.Meta.Series != "" && post.Meta.Series == other.Meta.Series {
+				score += relatedSeriesWeight
+			}
+			if score >= minRelatedScore {
+				candidates = append(candidates, candidate{post: other, score: score})
+			}
+		}
+
+		sort.Slice(candidates, func(a, b int) bool {
+			if candidates[a].score != candidates[b].score {
+				return candidates[a].score > candidates[b].score
+			}
+			if !candidates[a].post.Meta.PublishDate.Equal(candidates[b].post.Meta.PublishDate) {
+				return candidates[a].post.Meta.PublishDate.After(candidates[b].post.Meta.PublishDate)
+			}
+			return candidates[a].post.Meta.Slug < candidates[b].post.Meta.Slug
+		})
+
+		n := min(len(candidates), relatedLimit)
+		if n == 0 {
+			continue
+		}
+		related[post.Meta.Slug] = make([]*Post, n)
+		for k := range n {
+			related[post.Meta.Slug][k] = candidates[k].post
+		}
+	}
+	return related
+}
+
+// tagSimilarity is the weighted Jaccard similarity of two tag sets: the
+// weight of the tags they share over the weight of all their tags.
+func tagSimilarity(a, b []string, idf map[string]float64) float64 {
+	inA := make(map[string]bool, len(a))
+	for _, tag := range a {
+		inA[tag] = true
+	}
+	union := maps.Clone(inA)
+
+	var shared float64
+	for _, tag := range b {
+		if inA[tag] {
+			shared += idf[tag]
+			delete(inA, tag) // Count repeated tags once
+		}
+		union[tag] = true
+	}
+	if shared == 0 {
+		return 0
+	}
+
+	var total float64
+	for tag := range union {
+		total += idf[tag]
+	}
+	return shared / total
+}
+
+// termVectors returns a unit-length TF-IDF vector over the plain text of
+// each post, in the same order as posts. Terms that appear in every post
+// get an IDF of zero, which keeps common words out of the comparison.
+func termVectors(posts []*Post) []map[string]float64 {
+	counts := make([]map[string]int, len(posts))
+	df := make(map[string]int)
+	for i, post := range posts {
+		counts[i] = make(map[string]int)
+		for _, tok := range tokenize(post.PlainText) {
+			counts[i][tok.term]++
+		}
+		for term := range counts[i] {
+			df[term]++
+		}
+	}
+
+	vectors := make([]map[string]float64, len(posts))
+	for i, tf := range counts {
+		vec := make(map[string]float64, len(tf))
+		var norm float64
+		for term, n := range tf {
+			w := (1 + math.Log(float64(n))) * math.Log(float64(len(posts))/float64(df[term]))
+			if w == 0 {
+				continue
+			}
+			vec[term] = w
+			norm += w * w
+		}
+		norm = math.Sqrt(norm)
+		for term := range vec {
+			vec[term] /= norm
+		}
+		vectors[i] = vec
+	}
+	return vectors
+}
+
+// cosine returns the dot product of two unit vectors.
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, w := range a {
+		dot += w * b[term]
+	}
+	return dot
+}
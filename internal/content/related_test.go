@@ -0,0 +1,87 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestStore_GetRelated(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour)
+
+		write := func(slug, series string, tags []string, body string, age int) {
+			meta := "title: " + slug + "\nslug: " + slug + "\npublishDate: " +
+				past.AddDate(0, 0, -age).Format(time.RFC3339) + "\n"
+			if series != "" {
+				meta += "series: " + series + "\n"
+			}
+			if len(tags) > 0 {
+				meta += "tags: [" + strings.Join(tags, ", ") + "]\n"
+			}
+			_ = afero.WriteFile(fs, slug+".md", []byte("---\n"+meta+"---\n"+body), 0644)
+		}
+
+		write("forms", "", []string{"plato", "metaphysics"}, "The theory of forms and the allegory of the cave.", 0)
+		write("cave", "", []string{"plato"}, "The allegory of the cave and the ascent to the good.", 1)
+		write("hylomorphism", "", []string{"metaphysics"}, "Matter and form in Aristotle.", 2)
+		write("grace-1", "grace", []string{"theology"}, "Nature and grace.", 3)
+		write("grace-2", "grace", []string{"theology"}, "Merit and justification.", 4)
+		write("tithing", "", nil, "Budgets, accounts and spreadsheets.", 5)
+		_ = afero.WriteFile(fs, "draft.md", []byte("---\ntitle: Draft\nslug: draft\ndraft: true\ntags: [plato]\n---\nThe allegory of the cave."), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+		ctx := context.Background()
+
+		slugs := func(posts []*Post) []string {
+			out := make([]string, len(posts))
+			for i, p := range posts {
+				out[i] = p.Meta.Slug
+			}
+			return out
+		}
+
+		tests := []struct {
+			slug string
+			want []string
+		}{
+			// Shares the rare tag and the text with cave; only a tag with hylomorphism
+			{slug: "forms", want: []string{"cave", "hylomorphism"}},
+			// Same series outranks everything else
+			{slug: "grace-1", want: []string{"grace-2"}},
+			// Nothing in common with any post
+			{slug: "tithing", want: []string{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.slug, func(t *testing.T) {
+				related, err := store.GetRelated(ctx, tt.slug)
+				if err != nil {
+					t.Fatalf("GetRelated() error = %v", err)
+				}
+				got := slugs(related)
+				if len(got) != len(tt.want) {
+					t.Fatalf("GetRelated(%q) = %v, want %v", tt.slug, got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Errorf("GetRelated(%q) = %v, want %v", tt.slug, got, tt.want)
+						break
+					}
+				}
+			})
+		}
+
+		// Drafts are neither related to nor have related posts
+		if _, err := store.GetRelated(ctx, "draft"); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("GetRelated(draft) error = %v, want ErrPostNotFound", err)
+		}
+	})
+}
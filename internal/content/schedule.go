@@ -0,0 +1,81 @@
+package content
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// indexRefreshInterval is how often Schedule rebuilds the indexes even when
+// no post is due, so that time-relative fields such as
+// SeriesCount.HasRecentPosts stay current in a long-running process.
+const indexRefreshInterval = time.Hour
+
+// Upcoming returns the posts scheduled for a future publish date,
+// soonest first.
+func (s *EmbeddedStore) Upcoming() []*Post {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	upcoming := make([]*Post, 0, len(s.pending))
+	for _, post := range s.pending {
+		upcoming = append(upcoming, post)
+	}
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].Meta.PublishDate.Before(upcoming[j].Meta.PublishDate)
+	})
+	return upcoming
+}
+
+// Schedule publishes pending posts as their publish dates arrive, until
+// ctx is cancelled. Without it, scheduled posts only appear after the
+// content is reloaded.
+func (s *EmbeddedStore) Schedule(ctx context.Context) {
+	for {
+		wait := indexRefreshInterval
+		if upcoming := s.Upcoming(); len(upcoming) > 0 {
+			wait = min(wait, time.Until(upcoming[0].Meta.PublishDate))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			// The pending set changed; recompute the wait
+			timer.Stop()
+		case <-timer.C:
+			for _, post := range s.publishDue(time.Now()) {
+				slog.Info("Published scheduled post", "slug", post.Meta.Slug, "publishDate", post.Meta.PublishDate)
+			}
+		}
+	}
+}
+
+// publishDue moves every pending post whose publish date is not after now
+// into the published set and rebuilds the indexes. Returns the posts that
+// were published.
+func (s *EmbeddedStore) publishDue(now time.Time) []*Post {
+	// Keep a concurrent Reload from swapping in a stale pending set
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var published []*Post
+	for slug, post := range s.pending {
+		if post.Meta.PublishDate.After(now) {
+			continue
+		}
+		delete(s.pending, slug)
+		s.posts[slug] = post
+		published = append(published, post)
+	}
+
+	// Rebuild even if nothing was published, to refresh HasRecentPosts
+	s.buildIndexes()
+	return published
+}
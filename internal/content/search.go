@@ -0,0 +1,313 @@
+package content
+
+import (
+	"cmp"
+	"html"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// Match HTML tags
+	htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+	// Match runs of whitespace
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// Field weights for search ranking. A match in the title counts for more
+// than the same match deep in the body.
+const (
+	titleWeight   = 5.0
+	tagWeight     = 3.0
+	summaryWeight = 2.0
+	bodyWeight    = 1.0
+)
+
+// snippetRadius is the number of runes of context shown on either side
+// of the first match in a search snippet.
+const snippetRadius = 80
+
+// SearchResult is a post matching a search query.
+type SearchResult struct {
+	Post    *Post
+	Score   float64
+	Snippet string // HTML-escaped plain text with matches wrapped in <mark>
+}
+
+// PlainText strips tags from rendered HTML, decodes entities and collapses
+// whitespace, leaving the readable text of a post.
+func PlainText(htmlContent string) string {
+	text := htmlTagRegex.ReplaceAllString(htmlContent, " ")
+	text = html.UnescapeString(text)
+	text = whitespaceRegex.ReplaceAllString(text, " ")
+	return strings.TrimSpace(text)
+}
+
+// token is a normalized word and its byte span in the original text.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into words on any non-letter, non-digit rune, so
+// Greek and Hebrew text is split the same way as Latin text. Terms are
+// folded with foldTerm.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			if term := foldTerm(text[start:i]); term != "" {
+				tokens = append(tokens, token{term: term, start: start, end: i})
+			}
+			start = -1
+		}
+	}
+	if start >= 0 {
+		if term := foldTerm(text[start:]); term != "" {
+			tokens = append(tokens, token{term: term, start: start, end: len(text)})
+		}
+	}
+	return tokens
+}
+
+// foldTerm lowercases a word and strips diacritics (Greek accents and
+// breathings, Hebrew vowel points and cantillation), so that searches
+// match regardless of how a word was pointed or accented.
+func foldTerm(word string) string {
+	var sb strings.Builder
+	sb.Grow(len(word))
+	for _, r := range norm.NFD.String(word) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if r == 'ς' {
+			// Final sigma is the same letter as medial sigma
+			r = 'σ'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// posting records how strongly a term occurs in a post.
+type posting struct {
+	post   *Post
+	weight float64 // sum of field weights over every occurrence
+}
+
+// searchIndex is an inverted index from folded terms to the posts that
+// contain them.
+type searchIndex struct {
+	postings map[string][]posting
+	terms    []string // sorted, for prefix lookups
+	docs     int
+}
+
+// buildSearchIndex indexes the title, summary, tags and full plain text of
+// the given posts.
+func buildSearchIndex(posts []*Post) *searchIndex {
+	idx := &searchIndex{
+		postings: make(map[string][]posting),
+		docs:     len(posts),
+	}
+
+	for _, post := range posts {
+		weights := make(map[string]float64)
+		add := func(text string, weight float64) {
+			for _, tok := range tokenize(text) {
+				weights[tok.term] += weight
+			}
+		}
+		add(post.Meta.Title, titleWeight)
+		add(post.Meta.Summary, summaryWeight)
+		for _, tag := range post.Meta.Tags {
+			add(tag, tagWeight)
+		}
+		add(post.PlainText, bodyWeight)
+
+		for term, weight := range weights {
+			idx.postings[term] = append(idx.postings[term], posting{post: post, weight: weight})
+		}
+	}
+
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+
+	return idx
+}
+
+// expand returns the indexed terms a query term matches. The last term of
+// a query also matches as a prefix, so results appear while typing.
+func (idx *searchIndex) expand(term string, prefix bool) []string {
+	if !prefix {
+		if _, ok := idx.postings[term]; ok {
+			return []string{term}
+		}
+		return nil
+	}
+
+	var matches []string
+	for i := sort.SearchStrings(idx.terms, term); i < len(idx.terms); i++ {
+		if !strings.HasPrefix(idx.terms[i], term) {
+			break
+		}
+		matches = append(matches, idx.terms[i])
+	}
+	return matches
+}
+
+// search returns every post containing all query terms, ranked by a
+// TF-IDF score with field weighting, best first.
+func (idx *searchIndex) search(query string) []SearchResult {
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 || idx.docs == 0 {
+		return nil
+	}
+
+	scores := make(map[*Post]float64)
+	for i, qt := range queryTokens {
+		// Score for this query term, summed over the terms it expands to
+		termScores := make(map[*Post]float64)
+		for _, term := range idx.expand(qt.term, i == len(queryTokens)-1) {
+			postings := idx.postings[term]
+			idf := math.Log(1 + float64(idx.docs)/float64(len(postings)))
+			for _, p := range postings {
+				termScores[p.post] += (1 + math.Log(p.weight)) * idf
+			}
+		}
+
+		// Every query term must match
+		if i == 0 {
+			scores = termScores
+			continue
+		}
+		for post, score := range scores {
+			if ts, ok := termScores[post]; ok {
+				scores[post] = score + ts
+			} else {
+				delete(scores, post)
+			}
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for post, score := range scores {
+		results = append(results, SearchResult{Post: post, Score: score})
+	}
+	// Break ties by date, then slug, so pages of results don't overlap
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		if c := b.Post.Meta.PublishDate.Compare(a.Post.Meta.PublishDate); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Post.Meta.Slug, b.Post.Meta.Slug)
+	})
+
+	return results
+}
+
+// highlight returns an HTML snippet of text around the first token that
+// matches a query term, with every matching token wrapped in <mark>.
+// Falls back to the start of the text when nothing matches (e.g. the
+// match was in the title or tags only).
+func highlight(text, query string) string {
+	queryTokens := tokenize(query)
+	matches := func(term string) bool {
+		for i, qt := range queryTokens {
+			if term == qt.term || (i == len(queryTokens)-1 && strings.HasPrefix(term, qt.term)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	tokens := tokenize(text)
+	first := -1
+	for i, tok := range tokens {
+		if matches(tok.term) {
+			first = i
+			break
+		}
+	}
+
+	// Choose a window of roughly snippetRadius runes either side of the match
+	start, end := 0, len(text)
+	if first >= 0 {
+		start = backRunes(text, tokens[first].start, snippetRadius)
+		end = forwardRunes(text, tokens[first].end, snippetRadius)
+	} else {
+		end = forwardRunes(text, 0, 2*snippetRadius)
+	}
+
+	// Snap to word boundaries so the snippet doesn't open or close mid-word
+	if start > 0 {
+		if i := strings.IndexByte(text[start:], ' '); i >= 0 && start+i < end {
+			start += i + 1
+		}
+	}
+	if end < len(text) {
+		if i := strings.LastIndexByte(text[start:end], ' '); i > 0 {
+			end = start + i
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	pos := start
+	for _, tok := range tokens {
+		if tok.start < start || tok.end > end || !matches(tok.term) {
+			continue
+		}
+		sb.WriteString(html.EscapeString(text[pos:tok.start]))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(text[tok.start:tok.end]))
+		sb.WriteString("</mark>")
+		pos = tok.end
+	}
+	sb.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+// backRunes returns the byte offset n runes before offset in s.
+func backRunes(s string, offset, n int) int {
+	for ; n > 0 && offset > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(s[:offset])
+		offset -= size
+	}
+	return offset
+}
+
+// forwardRunes returns the byte offset n runes after offset in s.
+func forwardRunes(s string, offset, n int) int {
+	for ; n > 0 && offset < len(s); n-- {
+		_, size := utf8.DecodeRuneInString(s[offset:])
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,209 @@
+package content
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "latin with punctuation",
+			input: "Faith, reason & the Good!",
+			want:  []string{"faith", "reason", "the", "good"},
+		},
+		{
+			name:  "greek accents and final sigma",
+			input: "Ἐν ἀρχῇ ἦν ὁ λόγος",
+			want:  []string{"εν", "αρχη", "ην", "ο", "λογοσ"},
+		},
+		{
+			name:  "hebrew vowel points",
+			input: "בְּרֵאשִׁית בָּרָא",
+			want:  []string{"בראשית", "ברא"},
+		},
+		{
+			name:  "digits",
+			input: "John 3:16",
+			want:  []string{"john", "3", "16"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := tokenize(tt.input)
+			got := make([]string, len(tokens))
+			for i, tok := range tokens {
+				got[i] = tok.term
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("tokenize(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := PlainText("<p>Grace &amp; nature&rsquo;s\n\n<em>perfection</em></p>")
+	want := "Grace & nature’s perfection"
+	if got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+}
+
+func TestStore_Search(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+		// The match is far past the first 800 characters of the body
+		_ = afero.WriteFile(fs, "long.md", []byte(`---
+title: A Long Essay
+slug: long
+publishDate: `+past+`
+tags: [philosophy]
+---
+`+strings.Repeat("Filler words about nothing in particular. ", 50)+`Finally we reach the λόγος.`), 0644)
+
+		_ = afero.WriteFile(fs, "logos.md", []byte(`---
+title: On the Logos
+slug: logos
+summary: The Word in John's prologue.
+publishDate: `+past+`
+tags: [theology]
+---
+In the beginning was the Word: Λόγος.`), 0644)
+
+		_ = afero.WriteFile(fs, "other.md", []byte(`---
+title: Unrelated
+slug: other
+publishDate: `+past+`
+---
+Nothing to see here.`), 0644)
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+
+		t.Run("unicode match past truncation point", func(t *testing.T) {
+			results, total, err := store.Search(ctx, "λογος", ListOptions{})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if total != 2 {
+				t.Fatalf("total = %d, want 2", total)
+			}
+			slugs := []string{results[0].Post.Meta.Slug, results[1].Post.Meta.Slug}
+			if !(slugs[0] == "logos" || slugs[1] == "logos") || !(slugs[0] == "long" || slugs[1] == "long") {
+				t.Errorf("results = %v, want logos and long", slugs)
+			}
+			for _, r := range results {
+				if !strings.Contains(r.Snippet, "<mark>") {
+					t.Errorf("snippet for %s has no highlight: %q", r.Post.Meta.Slug, r.Snippet)
+				}
+			}
+		})
+
+		t.Run("title ranks above body", func(t *testing.T) {
+			results, _, err := store.Search(ctx, "logos", ListOptions{})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(results) == 0 || results[0].Post.Meta.Slug != "logos" {
+				t.Fatalf("results[0] should be logos, got %+v", results)
+			}
+		})
+
+		t.Run("all terms must match", func(t *testing.T) {
+			_, total, _ := store.Search(ctx, "word nothing", ListOptions{})
+			if total != 0 {
+				t.Errorf("total = %d, want 0", total)
+			}
+		})
+
+		t.Run("prefix on last term", func(t *testing.T) {
+			results, _, _ := store.Search(ctx, "prolog", ListOptions{})
+			if len(results) != 1 || results[0].Post.Meta.Slug != "logos" {
+				t.Errorf("prefix search results = %+v, want [logos]", results)
+			}
+		})
+
+		t.Run("filter and paginate", func(t *testing.T) {
+			results, total, _ := store.Search(ctx, "the", ListOptions{Tag: "theology"})
+			if total != 1 || results[0].Post.Meta.Slug != "logos" {
+				t.Errorf("tag-filtered total = %d, want 1 (logos)", total)
+			}
+
+			results, total, _ = store.Search(ctx, "the", ListOptions{ExcludeTags: []string{"theology"}})
+			if total != 1 || results[0].Post.Meta.Slug == "logos" {
+				t.Errorf("tag-excluded total = %d, want 1 (not logos)", total)
+			}
+
+			results, total, _ = store.Search(ctx, "the", ListOptions{Limit: 1, Offset: 1})
+			if total != 2 || len(results) != 1 {
+				t.Errorf("paginated total = %d, len = %d, want 2, 1", total, len(results))
+			}
+		})
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	text := strings.Repeat("alpha ", 40) + "the <target> word " + strings.Repeat("omega ", 40)
+	got := highlight(text, "target")
+
+	if !strings.Contains(got, "&lt;<mark>target</mark>&gt;") {
+		t.Errorf("highlight() should escape HTML and mark the match, got %q", got)
+	}
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("highlight() should elide both ends, got %q", got)
+	}
+}
+
+func TestStore_SearchTies(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		fs := afero.NewMemMapFs()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+		// Identical text and dates, so only the slug tells them apart
+		for _, slug := range []string{"twin-b", "twin-a"} {
+			_ = afero.WriteFile(fs, slug+".md", []byte(`---
+title: Twin
+slug: `+slug+`
+publishDate: `+past+`
+---
+Castor and Pollux.`), 0644)
+		}
+
+		store, err := open(fs)
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx := context.Background()
+		var slugs []string
+		for offset := range 2 {
+			results, total, err := store.Search(ctx, "pollux", ListOptions{Limit: 1, Offset: offset})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if total != 2 || len(results) != 1 {
+				t.Fatalf("total = %d, len = %d, want 2, 1", total, len(results))
+			}
+			slugs = append(slugs, results[0].Post.Meta.Slug)
+		}
+		if slugs[0] != "twin-a" || slugs[1] != "twin-b" {
+			t.Errorf("pages = %v, want [twin-a twin-b]", slugs)
+		}
+	})
+}
@@ -0,0 +1,1135 @@
+package content
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"therefore/internal/images"
+
+	"github.com/spf13/afero"
+	_ "modernc.org/sqlite" // Registers the pure-Go "sqlite" driver
+)
+
+// Compile-time interface compliance check.
+var _ ContentStore = (*SQLiteStore)(nil)
+
+// Post states, as stored in the posts table.
+const (
+	statePublished = "published"
+	stateScheduled = "scheduled"
+	stateDraft     = "draft"
+)
+
+// sqliteSchema creates the store's tables. The database is a copy of the
+// markdown source, so it is rebuilt from scratch whenever a store opens it.
+// Dates are Unix microseconds, which reach back to the zero time.Time.
+const sqliteSchema = `
+DROP TABLE IF EXISTS image_variants;
+DROP TABLE IF EXISTS post_images;
+DROP TABLE IF EXISTS post_assets;
+DROP TABLE IF EXISTS post_authors;
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS related;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS authors;
+DROP TABLE IF EXISTS series_info;
+DROP TABLE IF EXISTS series_rank;
+DROP TABLE IF EXISTS versions;
+DROP TABLE IF EXISTS search;
+
+CREATE TABLE posts (
+	slug         TEXT PRIMARY KEY,
+	state        TEXT NOT NULL, -- published, scheduled or draft
+	title        TEXT NOT NULL,
+	title_key    TEXT NOT NULL, -- Lowercased title, for sorting
+	series       TEXT NOT NULL,
+	series_order INTEGER NOT NULL,
+	publish_date INTEGER NOT NULL,
+	year         INTEGER NOT NULL, -- Of the publish date in its own time zone, for the archive
+	month        INTEGER NOT NULL,
+	reading_time INTEGER NOT NULL, -- Minutes
+	hash         TEXT NOT NULL,
+	meta         TEXT NOT NULL, -- PostMeta as JSON
+	raw          TEXT NOT NULL,
+	html         TEXT NOT NULL,
+	plain        TEXT NOT NULL,
+	toc          TEXT NOT NULL, -- Heading outline as JSON
+	bundle_dir   TEXT NOT NULL
+);
+CREATE INDEX posts_by_date ON posts (state, publish_date DESC, slug);
+CREATE INDEX posts_by_series ON posts (series);
+CREATE INDEX posts_by_month ON posts (state, year, month);
+
+CREATE TABLE post_tags (
+	slug TEXT NOT NULL REFERENCES posts ON DELETE CASCADE,
+	tag  TEXT NOT NULL,
+	PRIMARY KEY (slug, tag)
+);
+CREATE INDEX post_tags_by_tag ON post_tags (tag);
+
+-- Authors defined in config.yaml; inline authors are only in posts.meta
+CREATE TABLE post_authors (
+	slug      TEXT NOT NULL REFERENCES posts ON DELETE CASCADE,
+	author_id TEXT NOT NULL,
+	PRIMARY KEY (slug, author_id)
+);
+CREATE INDEX post_authors_by_author ON post_authors (author_id);
+
+-- Every file in a page bundle, by path relative to the bundle directory
+CREATE TABLE post_assets (
+	slug TEXT NOT NULL REFERENCES posts ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	data BLOB NOT NULL,
+	PRIMARY KEY (slug, name)
+);
+
+CREATE TABLE post_images (
+	slug   TEXT NOT NULL REFERENCES posts ON DELETE CASCADE,
+	name   TEXT NOT NULL,
+	width  INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	PRIMARY KEY (slug, name)
+);
+
+CREATE TABLE image_variants (
+	slug   TEXT NOT NULL,
+	name   TEXT NOT NULL,
+	width  INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	format TEXT NOT NULL,
+	data   BLOB NOT NULL,
+	FOREIGN KEY (slug, name) REFERENCES post_images ON DELETE CASCADE
+);
+CREATE INDEX image_variants_by_image ON image_variants (slug, name);
+
+CREATE TABLE related (
+	slug    TEXT NOT NULL,
+	rank    INTEGER NOT NULL,
+	related TEXT NOT NULL,
+	PRIMARY KEY (slug, rank)
+);
+
+CREATE TABLE authors (
+	id     TEXT PRIMARY KEY,
+	name   TEXT NOT NULL,
+	avatar TEXT NOT NULL,
+	bio    TEXT NOT NULL,
+	url    TEXT NOT NULL
+);
+
+CREATE TABLE series_info (
+	name        TEXT PRIMARY KEY,
+	title       TEXT NOT NULL,
+	description TEXT NOT NULL,
+	cover       TEXT NOT NULL
+);
+
+-- The source's series order and HasRecentPosts, which depend on when its
+-- indexes were built, so they change only with the listing version
+CREATE TABLE series_rank (
+	name   TEXT PRIMARY KEY,
+	rank   INTEGER NOT NULL,
+	recent INTEGER NOT NULL
+);
+
+-- The listing version has an empty slug
+CREATE TABLE versions (
+	slug     TEXT PRIMARY KEY,
+	etag     TEXT NOT NULL,
+	modified INTEGER NOT NULL
+);
+
+-- Fields hold terms folded by tokenize, so matching follows the same rules
+-- as EmbeddedStore's search
+CREATE VIRTUAL TABLE search USING fts5(
+	slug UNINDEXED, title, tags, summary, body,
+	tokenize = 'unicode61 remove_diacritics 0'
+);
+`
+
+// searchRank scores a search match with bm25, weighting the columns of
+// the search table as EmbeddedStore weights the fields. Lower is better.
+var searchRank = fmt.Sprintf("bm25(search, 0, %g, %g, %g, %g)", titleWeight, tagWeight, summaryWeight, bodyWeight)
+
+// postColumns are the columns of posts p that scanPost reads, in order.
+const postColumns = "p.slug, p.hash, p.meta, p.raw, p.html, p.plain, p.toc, p.bundle_dir"
+
+// SQLiteStore implements ContentStore on a SQLite database, so the archive
+// can be queried in SQL. Posts are parsed from the same markdown source as
+// EmbeddedStore, by an EmbeddedStore the SQLiteStore keeps for loading,
+// reloading and scheduling; every rebuild of its indexes is written to the
+// database, which answers every query.
+type SQLiteStore struct {
+	source *EmbeddedStore
+	db     *sql.DB
+
+	// synced is the posts last written to the database and their states,
+	// so a rebuild only rewrites the posts that changed. Guarded by
+	// source.mu, which is held while writing.
+	synced map[string]syncedPost
+}
+
+type syncedPost struct {
+	post  *Post
+	state string
+}
+
+// NewSQLiteStore loads every post from fs, as NewEmbeddedStore does, into
+// the SQLite database at path, replacing whatever it held. An empty path
+// keeps the database in memory.
+func NewSQLiteStore(path string, fs afero.Fs, renderer Renderer) (*SQLiteStore, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	source := newEmbeddedStore(fs, renderer)
+	if err := source.load(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	s := &SQLiteStore{source: source, db: db}
+	if err := s.write(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	source.indexed = s.sync
+	return s, nil
+}
+
+// openSQLite opens the database at path, or in memory if path is empty,
+// and creates the schema.
+func openSQLite(path string) (*sql.DB, error) {
+	name := path
+	if name == "" {
+		name = ":memory:"
+	}
+	dsn := "file:" + name + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	if path == "" {
+		// Every connection to :memory: is a database of its own
+		db.SetMaxOpenConns(1)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+	return db, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Reload re-reads the given paths, as EmbeddedStore.Reload does, and writes
+// the changes to the database.
+func (s *SQLiteStore) Reload(paths ...string) error {
+	return s.source.Reload(paths...)
+}
+
+// Watch reloads posts as they change in dir until ctx is cancelled. See
+// EmbeddedStore.Watch.
+func (s *SQLiteStore) Watch(ctx context.Context, dir string) error {
+	return s.source.Watch(ctx, dir)
+}
+
+// Schedule publishes pending posts as their publish dates arrive, until
+// ctx is cancelled. See EmbeddedStore.Schedule.
+func (s *SQLiteStore) Schedule(ctx context.Context) {
+	s.source.Schedule(ctx)
+}
+
+// sync writes the source's content to the database after an index
+// rebuild. A failed write leaves the database as it was.
+func (s *SQLiteStore) sync() {
+	start := time.Now()
+	if err := s.write(); err != nil {
+		slog.Error("Failed to write content to SQLite", "error", err)
+		return
+	}
+	slog.Debug("Wrote content to SQLite", "duration", time.Since(start))
+}
+
+// write copies the source's posts and indexes to the database in a single
+// transaction. Posts are only rewritten when the source has replaced them;
+// the indexes derived from every post are rewritten in full. Callers must
+// hold source.mu once the store is shared.
+func (s *SQLiteStore) write() error {
+	src := s.source
+	current := make(map[string]syncedPost, len(src.posts)+len(src.pending)+len(src.drafts))
+	for state, posts := range map[string]map[string]*Post{
+		statePublished: src.posts,
+		stateScheduled: src.pending,
+		stateDraft:     src.drafts,
+	} {
+		for slug, post := range posts {
+			current[slug] = syncedPost{post: post, state: state}
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Drop replaced and deleted posts first, so a new post can take the
+	// slug of a deleted one
+	for slug, old := range s.synced {
+		if cur, ok := current[slug]; ok && cur.post == old.post {
+			if cur.state != old.state {
+				if _, err := tx.Exec(`UPDATE posts SET state = ? WHERE slug = ?`, cur.state, slug); err != nil {
+					return fmt.Errorf("updating %s: %w", slug, err)
+				}
+			}
+			continue
+		}
+		if err := deletePost(tx, slug); err != nil {
+			return fmt.Errorf("deleting %s: %w", slug, err)
+		}
+	}
+	for slug, cur := range current {
+		if old, ok := s.synced[slug]; ok && old.post == cur.post {
+			continue
+		}
+		if err := s.insertPost(tx, cur.post, cur.state); err != nil {
+			return fmt.Errorf("writing %s: %w", slug, err)
+		}
+	}
+
+	if err := s.writeIndexes(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing: %w", err)
+	}
+	s.synced = current
+	return nil
+}
+
+func deletePost(tx *sql.Tx, slug string) error {
+	if _, err := tx.Exec(`DELETE FROM posts WHERE slug = ?`, slug); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DELETE FROM search WHERE slug = ?`, slug)
+	return err
+}
+
+// insertPost writes a post with its tags, authors, bundle files, image
+// variants and search terms.
+func (s *SQLiteStore) insertPost(tx *sql.Tx, post *Post, state string) error {
+	meta, err := json.Marshal(post.Meta)
+	if err != nil {
+		return fmt.Errorf("encoding metadata: %w", err)
+	}
+	toc, err := json.Marshal(post.TOC)
+	if err != nil {
+		return fmt.Errorf("encoding table of contents: %w", err)
+	}
+
+	m := post.Meta
+	if _, err := tx.Exec(`INSERT INTO posts (slug, state, title, title_key, series, series_order,
+		publish_date, year, month, reading_time, hash, meta, raw, html, plain, toc, bundle_dir)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.Slug, state, m.Title, strings.ToLower(m.Title), m.Series, m.SeriesOrder,
+		m.PublishDate.UnixMicro(), m.PublishDate.Year(), int(m.PublishDate.Month()), m.ReadingTime(), post.Hash, string(meta),
+		post.RawContent, post.HTMLContent, post.PlainText, string(toc), post.BundleDir); err != nil {
+		return err
+	}
+
+	for _, tag := range m.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO post_tags (slug, tag) VALUES (?, ?)`, m.Slug, tag); err != nil {
+			return err
+		}
+	}
+	for _, author := range m.Authors {
+		if author.ID == "" {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO post_authors (slug, author_id) VALUES (?, ?)`, m.Slug, author.ID); err != nil {
+			return err
+		}
+	}
+
+	if post.BundleDir != "" {
+		if err := s.insertAssets(tx, m.Slug, post.BundleDir); err != nil {
+			return err
+		}
+	}
+	for name, img := range post.Images {
+		if _, err := tx.Exec(`INSERT INTO post_images (slug, name, width, height) VALUES (?, ?, ?, ?)`,
+			m.Slug, name, img.Width, img.Height); err != nil {
+			return err
+		}
+		for _, v := range img.Variants {
+			if _, err := tx.Exec(`INSERT INTO image_variants (slug, name, width, height, format, data)
+				VALUES (?, ?, ?, ?, ?, ?)`, m.Slug, name, v.Width, v.Height, string(v.Format), v.Data); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO search (slug, title, tags, summary, body) VALUES (?, ?, ?, ?, ?)`,
+		m.Slug, foldText(m.Title), foldText(strings.Join(m.Tags, " ")), foldText(m.Summary), foldText(post.PlainText))
+	return err
+}
+
+// insertAssets copies every file in a page bundle from the source.
+func (s *SQLiteStore) insertAssets(tx *sql.Tx, slug, bundleDir string) error {
+	return afero.Walk(s.source.fs, bundleDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		data, err := afero.ReadFile(s.source.fs, path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(bundleDir, path)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO post_assets (slug, name, data) VALUES (?, ?, ?)`, slug, filepath.ToSlash(rel), data)
+		return err
+	})
+}
+
+// writeIndexes replaces the related posts, author profiles, series
+// descriptions and ranks, and versions with the source's.
+func (s *SQLiteStore) writeIndexes(tx *sql.Tx) error {
+	src := s.source
+	for _, table := range []string{"related", "authors", "series_info", "series_rank", "versions"} {
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return fmt.Errorf("clearing %s: %w", table, err)
+		}
+	}
+
+	for slug, related := range src.related {
+		for rank, post := range related {
+			if _, err := tx.Exec(`INSERT INTO related (slug, rank, related) VALUES (?, ?, ?)`,
+				slug, rank, post.Meta.Slug); err != nil {
+				return fmt.Errorf("writing related posts: %w", err)
+			}
+		}
+	}
+	for id, a := range src.config.Authors {
+		if _, err := tx.Exec(`INSERT INTO authors (id, name, avatar, bio, url) VALUES (?, ?, ?, ?, ?)`,
+			id, a.Name, a.Avatar, a.Bio, a.URL); err != nil {
+			return fmt.Errorf("writing authors: %w", err)
+		}
+	}
+	for name, info := range src.seriesInfo {
+		if _, err := tx.Exec(`INSERT INTO series_info (name, title, description, cover) VALUES (?, ?, ?, ?)`,
+			name, info.Title, info.Description, info.Cover); err != nil {
+			return fmt.Errorf("writing series: %w", err)
+		}
+	}
+	for rank, sc := range src.series {
+		if _, err := tx.Exec(`INSERT INTO series_rank (name, rank, recent) VALUES (?, ?, ?)`,
+			sc.Series, rank, sc.HasRecentPosts); err != nil {
+			return fmt.Errorf("writing series: %w", err)
+		}
+	}
+
+	versions := map[string]Version{"": src.version}
+	for slug, v := range src.postVersions {
+		versions[slug] = v
+	}
+	for slug, v := range versions {
+		if _, err := tx.Exec(`INSERT INTO versions (slug, etag, modified) VALUES (?, ?, ?)`,
+			slug, v.ETag, v.Modified.Unix()); err != nil {
+			return fmt.Errorf("writing versions: %w", err)
+		}
+	}
+	return nil
+}
+
+// foldText reduces text to its search terms, folded as tokenize folds them.
+func foldText(text string) string {
+	tokens := tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, tok := range tokens {
+		terms[i] = tok.term
+	}
+	return strings.Join(terms, " ")
+}
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPost reads the postColumns of a row, followed by any extra columns
+// into extra.
+func scanPost(row scanner, extra ...any) (*Post, error) {
+	var post Post
+	var meta, toc string
+	dest := append([]any{&post.Meta.Slug, &post.Hash, &meta, &post.RawContent, &post.HTMLContent,
+		&post.PlainText, &toc, &post.BundleDir}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(meta), &post.Meta); err != nil {
+		return nil, fmt.Errorf("decoding metadata of %s: %w", post.Meta.Slug, err)
+	}
+	if err := json.Unmarshal([]byte(toc), &post.TOC); err != nil {
+		return nil, fmt.Errorf("decoding table of contents of %s: %w", post.Meta.Slug, err)
+	}
+	return &post, nil
+}
+
+// queryPosts runs a query selecting postColumns and reads every row.
+func queryPosts(ctx context.Context, q interface {
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+}, query string, args ...any) ([]*Post, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var posts []*Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, rows.Err()
+}
+
+// getPost returns the post with the given slug in one of states.
+func (s *SQLiteStore) getPost(ctx context.Context, slug string, states ...string) (*Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts p WHERE p.slug = ? AND p.state IN (?` +
+		strings.Repeat(", ?", len(states)-1) + `)`
+	args := []any{slug}
+	for _, state := range states {
+		args = append(args, state)
+	}
+
+	post, err := scanPost(s.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying post: %w", err)
+	}
+	return post, nil
+}
+
+// published returns ErrPostNotFound unless a published post has the slug,
+// and the post's bundle directory if it has one.
+func (s *SQLiteStore) published(ctx context.Context, slug string) (bundleDir string, err error) {
+	err = s.db.QueryRowContext(ctx, `SELECT bundle_dir FROM posts WHERE slug = ? AND state = ?`,
+		slug, statePublished).Scan(&bundleDir)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrPostNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("querying post: %w", err)
+	}
+	return bundleDir, nil
+}
+
+// GetPost retrieves a single published post by slug.
+func (s *SQLiteStore) GetPost(ctx context.Context, slug string) (*Post, error) {
+	return s.getPost(ctx, slug, statePublished)
+}
+
+// GetPreview retrieves a draft or scheduled post by slug.
+func (s *SQLiteStore) GetPreview(ctx context.Context, slug string) (*Post, error) {
+	return s.getPost(ctx, slug, stateDraft, stateScheduled)
+}
+
+// GetRelated returns the published posts most similar to the post with the
+// given slug, best first.
+func (s *SQLiteStore) GetRelated(ctx context.Context, slug string) ([]*Post, error) {
+	if _, err := s.published(ctx, slug); err != nil {
+		return nil, err
+	}
+	posts, err := queryPosts(ctx, s.db, `SELECT `+postColumns+` FROM related r
+		JOIN posts p ON p.slug = r.related WHERE r.slug = ? ORDER BY r.rank`, slug)
+	if err != nil {
+		return nil, fmt.Errorf("querying related posts: %w", err)
+	}
+	return posts, nil
+}
+
+// GetHistory returns no revisions for a published post: the database
+// doesn't record history.
+func (s *SQLiteStore) GetHistory(ctx context.Context, slug string) ([]Revision, error) {
+	if _, err := s.published(ctx, slug); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// listFilter returns the WHERE clause, on posts p, and its arguments for
+// the posts matching opts.
+func listFilter(opts ListOptions) (string, []any) {
+	conds := []string{"p.state = ?"}
+	args := []any{statePublished}
+	if opts.IncludeDraft {
+		conds[0] = "p.state IN (?, ?)"
+		args = append(args, stateDraft)
+	}
+
+	const hasTag = "EXISTS (SELECT 1 FROM post_tags t WHERE t.slug = p.slug AND t.tag IN (%s))"
+	if opts.Tag != "" {
+		conds = append(conds, fmt.Sprintf(hasTag, "?"))
+		args = append(args, opts.Tag)
+	}
+	if len(opts.Tags) > 0 && opts.TagMode == TagMatchAny {
+		conds = append(conds, fmt.Sprintf(hasTag, placeholders(len(opts.Tags))))
+		args = appendStrings(args, opts.Tags)
+	} else {
+		for _, tag := range opts.Tags {
+			conds = append(conds, fmt.Sprintf(hasTag, "?"))
+			args = append(args, tag)
+		}
+	}
+	if len(opts.ExcludeTags) > 0 {
+		conds = append(conds, "NOT "+fmt.Sprintf(hasTag, placeholders(len(opts.ExcludeTags))))
+		args = appendStrings(args, opts.ExcludeTags)
+	}
+	if opts.Series != "" {
+		conds = append(conds, "p.series = ?")
+		args = append(args, opts.Series)
+	}
+	if opts.Author != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM post_authors a WHERE a.slug = p.slug AND a.author_id = ?)")
+		args = append(args, opts.Author)
+	}
+	if !opts.From.IsZero() {
+		conds = append(conds, "p.publish_date >= ?")
+		args = append(args, opts.From.UnixMicro())
+	}
+	if !opts.To.IsZero() {
+		conds = append(conds, "p.publish_date <= ?")
+		args = append(args, opts.To.UnixMicro())
+	}
+	if opts.Year != 0 {
+		conds = append(conds, "p.year = ?")
+		args = append(args, opts.Year)
+	}
+	if opts.Month != 0 {
+		conds = append(conds, "p.month = ?")
+		args = append(args, int(opts.Month))
+	}
+	if opts.MinReadingTime > 0 {
+		conds = append(conds, "p.reading_time >= ?")
+		args = append(args, opts.MinReadingTime)
+	}
+	if opts.MaxReadingTime > 0 {
+		conds = append(conds, "p.reading_time <= ?")
+		args = append(args, opts.MaxReadingTime)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// placeholders returns n comma-separated query placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// appendStrings appends values to query arguments.
+func appendStrings(args []any, values []string) []any {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
+
+// orderTerm is a column of a listing's ORDER BY, with the post's value
+// for it.
+type orderTerm struct {
+	column string
+	desc   bool
+	value  func(sortKey) any
+}
+
+// listTerms returns the ORDER BY terms for opts, ordering as compareKeys
+// does: by the sort field, then newest first, then by slug.
+func listTerms(opts ListOptions) []orderTerm {
+	field, order := opts.listSort()
+	date := orderTerm{"p.publish_date", true, func(k sortKey) any { return k.date.UnixMicro() }}
+	var terms []orderTerm
+	switch field {
+	case SortByTitle:
+		terms = []orderTerm{{"p.title_key", order == SortDesc, func(k sortKey) any { return k.title }}, date}
+	case SortByReadingTime:
+		terms = []orderTerm{{"p.reading_time", order == SortDesc, func(k sortKey) any { return k.readingTime }}, date}
+	default:
+		date.desc = order == SortDesc
+		terms = []orderTerm{date}
+	}
+	return append(terms, orderTerm{"p.slug", false, func(k sortKey) any { return k.slug }})
+}
+
+// listOrder returns the ORDER BY clause for terms, or for the reverse
+// order.
+func listOrder(terms []orderTerm, reverse bool) string {
+	columns := make([]string, len(terms))
+	for i, term := range terms {
+		direction := "ASC"
+		if term.desc != reverse {
+			direction = "DESC"
+		}
+		columns[i] = term.column + " " + direction
+	}
+	return strings.Join(columns, ", ")
+}
+
+// cursorFilter returns the condition, on posts p, and its arguments for
+// the posts on the cursor's side of it in a listing ordered by terms.
+func cursorFilter(cursor Cursor, terms []orderTerm) (string, []any) {
+	var alternatives []string
+	var args []any
+	for i, term := range terms {
+		conds := make([]string, 0, i+1)
+		for _, prev := range terms[:i] {
+			conds = append(conds, prev.column+" = ?")
+			args = append(args, prev.value(cursor.key))
+		}
+		op := ">"
+		if term.desc != cursor.before {
+			op = "<"
+		}
+		conds = append(conds, term.column+" "+op+" ?")
+		args = append(args, term.value(cursor.key))
+		alternatives = append(alternatives, "("+strings.Join(conds, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// limitOffset returns the LIMIT and OFFSET arguments for opts; a negative
+// limit means none.
+func limitOffset(opts ListOptions) (limit, offset int) {
+	limit = -1
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+	return limit, max(opts.Offset, 0)
+}
+
+// ListPosts returns posts matching the given options.
+// Returns posts, total count (before pagination), and any error.
+func (s *SQLiteStore) ListPosts(ctx context.Context, opts ListOptions) ([]*Post, int, error) {
+	where, args := listFilter(opts)
+	limit, offset := limitOffset(opts)
+	terms := listTerms(opts)
+
+	// A cursor narrows the page but not the total. Backwards, the posts
+	// nearest the cursor come first, so the query runs in reverse.
+	pageWhere, pageArgs := where, args
+	var reverse bool
+	if opts.Cursor != nil {
+		if err := opts.Cursor.check(opts); err != nil {
+			return nil, 0, err
+		}
+		cond, condArgs := cursorFilter(*opts.Cursor, terms)
+		pageWhere += " AND " + cond
+		pageArgs = append(slices.Clone(args), condArgs...)
+		reverse = opts.Cursor.before
+	}
+
+	// Count and page from the same snapshot
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var total int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM posts p WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting posts: %w", err)
+	}
+	posts, err := queryPosts(ctx, tx, `SELECT `+postColumns+` FROM posts p WHERE `+pageWhere+
+		` ORDER BY `+listOrder(terms, reverse)+` LIMIT ? OFFSET ?`, append(pageArgs, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing posts: %w", err)
+	}
+	if reverse {
+		slices.Reverse(posts)
+	}
+	return posts, total, nil
+}
+
+// Search returns posts matching a full-text query, ranked by bm25 with the
+// same field weights as EmbeddedStore. Every query term must match, and
+// the last also matches as a prefix.
+// Returns results, total count (before pagination), and any error.
+func (s *SQLiteStore) Search(ctx context.Context, query string, opts ListOptions) ([]SearchResult, int, error) {
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		return nil, 0, nil
+	}
+	terms := make([]string, len(tokens))
+	for i, tok := range tokens {
+		// Terms are letters and digits only, so quoting is enough to keep
+		// them from being read as FTS5 syntax
+		terms[i] = `"` + tok.term + `"`
+	}
+	terms[len(terms)-1] += "*"
+
+	opts.IncludeDraft = false
+	filter, filterArgs := listFilter(opts)
+	where := "search MATCH ? AND " + filter
+	args := append([]any{strings.Join(terms, " ")}, filterArgs...)
+	limit, offset := limitOffset(opts)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	from := ` FROM search JOIN posts p ON p.slug = search.slug WHERE ` + where
+	var total int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting search results: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+postColumns+`, `+searchRank+from+
+		` ORDER BY `+searchRank+`, p.publish_date DESC, p.slug LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("searching: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []SearchResult
+	for rows.Next() {
+		var rank float64
+		post, err := scanPost(rows, &rank)
+		if err != nil {
+			return nil, 0, fmt.Errorf("reading search result: %w", err)
+		}
+		results = append(results, SearchResult{
+			Post:    post,
+			Score:   -rank,
+			Snippet: highlight(post.PlainText, query),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("searching: %w", err)
+	}
+	return results, total, nil
+}
+
+// GetTags returns all tags of published posts with their post counts,
+// most used first.
+func (s *SQLiteStore) GetTags(ctx context.Context) ([]TagCount, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT t.tag, COUNT(*) FROM post_tags t
+		JOIN posts p ON p.slug = t.slug WHERE p.state = ?
+		GROUP BY t.tag ORDER BY COUNT(*) DESC, t.tag`, statePublished)
+	if err != nil {
+		return nil, fmt.Errorf("querying tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	tags := []TagCount{}
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, fmt.Errorf("reading tags: %w", err)
+		}
+		tags = append(tags, tc)
+	}
+	return tags, rows.Err()
+}
+
+// GetArchive returns the years and months with published posts, latest
+// first, with their post counts.
+func (s *SQLiteStore) GetArchive(ctx context.Context) ([]ArchiveYear, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT year, month, COUNT(*) FROM posts
+		WHERE state = ? GROUP BY year, month ORDER BY year DESC, month DESC`, statePublished)
+	if err != nil {
+		return nil, fmt.Errorf("querying archive: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	archive := []ArchiveYear{}
+	for rows.Next() {
+		var year, month, count int
+		if err := rows.Scan(&year, &month, &count); err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if len(archive) == 0 || archive[len(archive)-1].Year != year {
+			archive = append(archive, ArchiveYear{Year: year})
+		}
+		last := &archive[len(archive)-1]
+		last.Count += count
+		last.Months = append(last.Months, ArchiveMonth{Month: time.Month(month), Count: count})
+	}
+	return archive, rows.Err()
+}
+
+// GetSeries returns all series with published posts and their counts:
+// series with a post in the last 30 days first, then by count, then by
+// name. As in EmbeddedStore, "the last 30 days" and HasRecentPosts are
+// reckoned from when the indexes were last built.
+func (s *SQLiteStore) GetSeries(ctx context.Context) ([]SeriesCount, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT p.series, COUNT(*), COALESCE(r.recent, 0),
+		COALESCE(i.title, ''), COALESCE(i.description, ''), COALESCE(i.cover, '')
+		FROM posts p LEFT JOIN series_info i ON i.name = p.series
+		LEFT JOIN series_rank r ON r.name = p.series
+		WHERE p.state = ? AND p.series != ''
+		GROUP BY p.series
+		ORDER BY r.rank IS NULL, r.rank, COUNT(*) DESC, p.series`,
+		statePublished)
+	if err != nil {
+		return nil, fmt.Errorf("querying series: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	series := []SeriesCount{}
+	for rows.Next() {
+		var sc SeriesCount
+		if err := rows.Scan(&sc.Series, &sc.Count, &sc.HasRecentPosts,
+			&sc.Info.Title, &sc.Info.Description, &sc.Info.Cover); err != nil {
+			return nil, fmt.Errorf("reading series: %w", err)
+		}
+		if sc.Info.Title == "" {
+			sc.Info.Title = sc.Series
+		}
+		series = append(series, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading series: %w", err)
+	}
+
+	topTags, err := s.seriesTopTags(ctx, 3)
+	if err != nil {
+		return nil, err
+	}
+	for i := range series {
+		series[i].TopTags = topTags[series[i].Series]
+	}
+	return series, nil
+}
+
+// seriesTopTags returns the n most used tags in each series, by count and
+// then name.
+func (s *SQLiteStore) seriesTopTags(ctx context.Context, n int) (map[string][]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT p.series, t.tag FROM post_tags t
+		JOIN posts p ON p.slug = t.slug WHERE p.state = ? AND p.series != ''
+		GROUP BY p.series, t.tag ORDER BY p.series, COUNT(*) DESC, t.tag`, statePublished)
+	if err != nil {
+		return nil, fmt.Errorf("querying series tags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	top := make(map[string][]string)
+	for rows.Next() {
+		var series, tag string
+		if err := rows.Scan(&series, &tag); err != nil {
+			return nil, fmt.Errorf("reading series tags: %w", err)
+		}
+		if len(top[series]) < n {
+			top[series] = append(top[series], tag)
+		}
+	}
+	return top, rows.Err()
+}
+
+// GetAuthors returns the authors defined in config.yaml that have published
+// posts, with their post counts, most prolific first.
+func (s *SQLiteStore) GetAuthors(ctx context.Context) ([]AuthorCount, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT a.id, a.name, a.avatar, a.bio, a.url, COUNT(*)
+		FROM post_authors pa
+		JOIN posts p ON p.slug = pa.slug
+		JOIN authors a ON a.id = pa.author_id
+		WHERE p.state = ?
+		GROUP BY a.id ORDER BY COUNT(*) DESC, a.name`, statePublished)
+	if err != nil {
+		return nil, fmt.Errorf("querying authors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	authors := []AuthorCount{}
+	for rows.Next() {
+		var ac AuthorCount
+		a := &ac.Author
+		if err := rows.Scan(&a.ID, &a.Name, &a.Avatar, &a.Bio, &a.URL, &ac.Count); err != nil {
+			return nil, fmt.Errorf("reading authors: %w", err)
+		}
+		authors = append(authors, ac)
+	}
+	return authors, rows.Err()
+}
+
+// GetAuthor returns the author defined in config.yaml with the given id.
+func (s *SQLiteStore) GetAuthor(ctx context.Context, id string) (Author, error) {
+	a := Author{ID: id}
+	err := s.db.QueryRowContext(ctx, `SELECT name, avatar, bio, url FROM authors WHERE id = ?`, id).
+		Scan(&a.Name, &a.Avatar, &a.Bio, &a.URL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Author{}, ErrAuthorNotFound
+	}
+	if err != nil {
+		return Author{}, fmt.Errorf("querying author: %w", err)
+	}
+	return a, nil
+}
+
+// GetSeriesByName returns the named series with its published posts in
+// reading order.
+func (s *SQLiteStore) GetSeriesByName(ctx context.Context, name string) (*Series, error) {
+	posts, err := queryPosts(ctx, s.db, `SELECT `+postColumns+` FROM posts p
+		WHERE p.state = ? AND p.series = ?
+		ORDER BY p.series_order <= 0, p.series_order, p.publish_date, p.slug`, statePublished, name)
+	if err != nil {
+		return nil, fmt.Errorf("querying series: %w", err)
+	}
+	if len(posts) == 0 {
+		return nil, ErrSeriesNotFound
+	}
+
+	series := &Series{Name: name, Posts: posts}
+	err = s.db.QueryRowContext(ctx, `SELECT title, description, cover FROM series_info WHERE name = ?`, name).
+		Scan(&series.Info.Title, &series.Info.Description, &series.Info.Cover)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("querying series info: %w", err)
+	}
+	if series.Info.Title == "" {
+		series.Info.Title = name
+	}
+	return series, nil
+}
+
+// GetPostImage returns the resized variants of an image in a post's bundle
+// directory. Returns an error if the post isn't published or the file has
+// no variants.
+func (s *SQLiteStore) GetPostImage(ctx context.Context, slug, filename string) (*images.Image, error) {
+	if _, err := s.published(ctx, slug); err != nil {
+		return nil, err
+	}
+
+	var img images.Image
+	err := s.db.QueryRowContext(ctx, `SELECT width, height FROM post_images WHERE slug = ? AND name = ?`,
+		slug, filename).Scan(&img.Width, &img.Height)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("image not found: %s", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying image: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT width, height, format, data FROM image_variants
+		WHERE slug = ? AND name = ? ORDER BY rowid`, slug, filename)
+	if err != nil {
+		return nil, fmt.Errorf("querying image variants: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var v images.Variant
+		if err := rows.Scan(&v.Width, &v.Height, &v.Format, &v.Data); err != nil {
+			return nil, fmt.Errorf("reading image variants: %w", err)
+		}
+		img.Variants = append(img.Variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading image variants: %w", err)
+	}
+	return &img, nil
+}
+
+// GetPostAsset retrieves an asset file from a post's bundle directory.
+// Returns the file contents and an error if not found or not a bundle.
+func (s *SQLiteStore) GetPostAsset(ctx context.Context, slug, filename string) ([]byte, error) {
+	bundleDir, err := s.published(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if bundleDir == "" {
+		return nil, fmt.Errorf("post %q is not a bundle", slug)
+	}
+
+	// Prevent directory traversal
+	if strings.Contains(filename, "..") || strings.HasPrefix(filename, "/") {
+		return nil, fmt.Errorf("invalid filename")
+	}
+
+	var data []byte
+	err = s.db.QueryRowContext(ctx, `SELECT data FROM post_assets WHERE slug = ? AND name = ?`,
+		slug, filepath.ToSlash(filepath.Clean(filename))).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("asset not found: %s", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying asset: %w", err)
+	}
+	return data, nil
+}
+
+// Version returns the version of the published content as a whole, which
+// every listing is derived from.
+func (s *SQLiteStore) Version(ctx context.Context) (Version, error) {
+	return s.version(ctx, "")
+}
+
+// PostVersion returns the version of a published post's content, including
+// its related posts and series.
+func (s *SQLiteStore) PostVersion(ctx context.Context, slug string) (Version, error) {
+	if slug == "" {
+		return Version{}, ErrPostNotFound
+	}
+	return s.version(ctx, slug)
+}
+
+func (s *SQLiteStore) version(ctx context.Context, slug string) (Version, error) {
+	var v Version
+	var modified int64
+	err := s.db.QueryRowContext(ctx, `SELECT etag, modified FROM versions WHERE slug = ?`, slug).
+		Scan(&v.ETag, &modified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Version{}, ErrPostNotFound
+	}
+	if err != nil {
+		return Version{}, fmt.Errorf("querying version: %w", err)
+	}
+	v.Modified = time.Unix(modified, 0)
+	return v, nil
+}
+
+// Stats returns the current counts of posts by state, tags, series and
+// authors. Failures are logged and reported as zero counts.
+func (s *SQLiteStore) Stats() Stats {
+	var st Stats
+	err := s.db.QueryRow(`SELECT
+		(SELECT COUNT(*) FROM posts WHERE state = ?1),
+		(SELECT COUNT(*) FROM posts WHERE state = ?2),
+		(SELECT COUNT(*) FROM posts WHERE state = ?3),
+		(SELECT COUNT(DISTINCT t.tag) FROM post_tags t JOIN posts p ON p.slug = t.slug WHERE p.state = ?1),
+		(SELECT COUNT(DISTINCT series) FROM posts WHERE state = ?1 AND series != ''),
+		(SELECT COUNT(DISTINCT a.author_id) FROM post_authors a JOIN posts p ON p.slug = a.slug WHERE p.state = ?1)`,
+		statePublished, stateDraft, stateScheduled).
+		Scan(&st.Posts, &st.Drafts, &st.Scheduled, &st.Tags, &st.Series, &st.Authors)
+	if err != nil {
+		slog.Error("Failed to count content", "error", err)
+		return Stats{}
+	}
+	return st
+}
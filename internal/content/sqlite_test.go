@@ -0,0 +1,138 @@
+package content
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestSQLiteStore_File(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+	write := func(name, body string) {
+		_ = afero.WriteFile(fs, name, []byte("---\ntitle: "+name+"\npublishDate: "+past+
+			"\ntags: [philosophy]\n---\n"+body), 0644)
+	}
+	write("first.md", "Original.")
+	write("second.md", "Unchanged.")
+	_ = afero.WriteFile(fs, "bundle/index.md", []byte("---\ntitle: Bundle\npublishDate: "+past+"\n---\nSee the notes."), 0644)
+	_ = afero.WriteFile(fs, "bundle/notes/reading.txt", []byte("Aristotle, Plato."), 0644)
+
+	path := filepath.Join(t.TempDir(), "content.db")
+	store, err := NewSQLiteStore(path, fs, &mockRenderer{})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	// The database can be queried by other tools while the store is open
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	count := func() int {
+		t.Helper()
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM post_tags WHERE tag = 'philosophy'`).Scan(&n); err != nil {
+			t.Fatalf("querying database: %v", err)
+		}
+		return n
+	}
+	if n := count(); n != 2 {
+		t.Errorf("philosophy posts in database = %d, want 2", n)
+	}
+
+	if data, err := store.GetPostAsset(ctx, "bundle", "notes/reading.txt"); err != nil || string(data) != "Aristotle, Plato." {
+		t.Errorf("GetPostAsset(notes/reading.txt) = %q, %v", data, err)
+	}
+	if _, err := store.GetPostAsset(ctx, "bundle", "../first.md"); err == nil {
+		t.Error("GetPostAsset(../first.md) error = nil, want error")
+	}
+
+	// Reloads are written through, and only change the versions they touch
+	before, _ := store.Version(ctx)
+	secondBefore, _ := store.PostVersion(ctx, "second")
+	write("first.md", "Revised.")
+	_ = fs.Remove("second.md")
+	write("third.md", "New.")
+	if err := store.Reload("first.md", "second.md", "third.md"); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if n := count(); n != 2 {
+		t.Errorf("philosophy posts in database after reload = %d, want 2", n)
+	}
+	post, err := store.GetPost(ctx, "first")
+	if err != nil || post.RawContent != "Revised." {
+		t.Errorf("GetPost(first) = %+v, %v; want the revised post", post, err)
+	}
+	if after, _ := store.Version(ctx); after.ETag == before.ETag {
+		t.Error("Version() ETag unchanged after reload")
+	}
+	if secondBefore.ETag == "" {
+		t.Error("PostVersion(second) ETag empty before deletion")
+	}
+	if _, err := store.PostVersion(ctx, "second"); err == nil {
+		t.Error("PostVersion(second) error = nil after deletion")
+	}
+
+	// Opening the file again starts from the markdown source
+	_ = fs.Remove("third.md")
+	again, err := NewSQLiteStore(path, fs, &mockRenderer{})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() again error = %v", err)
+	}
+	defer func() { _ = again.Close() }()
+	if stats := again.Stats(); stats.Posts != 2 {
+		t.Errorf("Stats().Posts after reopening = %d, want 2", stats.Posts)
+	}
+}
+
+func TestSQLiteStore_SeriesRecency(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	// Recent when the store opens, but not for long
+	date := time.Now().AddDate(0, 0, -7).Add(500 * time.Millisecond).Format(time.RFC3339Nano)
+	_ = afero.WriteFile(fs, "post.md", []byte("---\ntitle: Post\npublishDate: "+date+
+		"\nseries: Series A\n---\nContent."), 0644)
+
+	store, err := NewSQLiteStore("", fs, &mockRenderer{})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	recent := func() bool {
+		t.Helper()
+		series, err := store.GetSeries(ctx)
+		if err != nil || len(series) != 1 {
+			t.Fatalf("GetSeries() = %+v, %v, want Series A", series, err)
+		}
+		return series[0].HasRecentPosts
+	}
+	if !recent() {
+		t.Fatal("HasRecentPosts = false, want true")
+	}
+	before, _ := store.Version(ctx)
+
+	// The flag holds until the indexes are rebuilt, so it never changes
+	// under an unchanged version
+	time.Sleep(time.Second)
+	if !recent() {
+		t.Error("HasRecentPosts changed without an index rebuild")
+	}
+
+	store.source.publishDue(time.Now())
+	if recent() {
+		t.Error("HasRecentPosts = true after the rebuild, want false")
+	}
+	if after, _ := store.Version(ctx); after.ETag == before.ETag {
+		t.Error("Version() unchanged after HasRecentPosts changed")
+	}
+}
@@ -4,17 +4,28 @@ import (
 	"context"
 	"errors"
 
+	"therefore/internal/images"
 	"therefore/internal/renderer"
 )
 
 var (
 	// ErrPostNotFound is returned when a post cannot be found.
 	ErrPostNotFound = errors.New("post not found")
+
+	// ErrSeriesNotFound is returned when a series has no published posts.
+	ErrSeriesNotFound = errors.New("series not found")
+
+	// ErrAuthorNotFound is returned when no author has the given id.
+	ErrAuthorNotFound = errors.New("author not found")
+
+	// ErrInvalidCursor is returned for a cursor that can't be decoded or
+	// that comes from a listing sorted another way.
+	ErrInvalidCursor = errors.New("invalid cursor")
 )
 
-// Renderer converts raw markdown content to HTML.
+// Renderer converts raw markdown content to HTML and a table of contents.
 type Renderer interface {
-	Render(raw string, ctx *renderer.RenderContext) (string, error)
+	RenderDocument(raw string, ctx *renderer.RenderContext) (renderer.Document, error)
 }
 
 // ContentStore provides access to blog posts.
@@ -24,6 +35,14 @@ type ContentStore interface {
 	// GetPost retrieves a single post by slug.
 	GetPost(ctx context.Context, slug string) (*Post, error)
 
+	// GetPreview retrieves a post that isn't public yet (a draft or a
+	// scheduled post) by slug. Callers must check the reader is authorized.
+	GetPreview(ctx context.Context, slug string) (*Post, error)
+
+	// GetRelated returns the published posts most similar to a published
+	// post, best first, based on shared tags, series and content.
+	GetRelated(ctx context.Context, slug string) ([]*Post, error)
+
 	// ListPosts returns posts matching the given options.
 	// Posts are returned sorted by publish date, newest first.
 	// Returns the posts, total count (before pagination), and any error.
@@ -35,6 +54,43 @@ type ContentStore interface {
 	// GetSeries returns all series with their post counts.
 	GetSeries(ctx context.Context) ([]SeriesCount, error)
 
+	// GetAuthors returns the authors with published posts and their counts.
+	GetAuthors(ctx context.Context) ([]AuthorCount, error)
+
+	// GetAuthor returns an author profile by id. The author's posts are
+	// listed with ListPosts and ListOptions.Author.
+	GetAuthor(ctx context.Context, id string) (Author, error)
+
+	// GetArchive returns the years and months with published posts, latest
+	// first, with their post counts. Their posts are listed with ListPosts
+	// and ListOptions.Year and Month.
+	GetArchive(ctx context.Context) ([]ArchiveYear, error)
+
+	// GetSeriesByName returns a series with its posts in reading order:
+	// by seriesOrder, then by publish date for posts without one.
+	GetSeriesByName(ctx context.Context, name string) (*Series, error)
+
+	// Search returns posts matching a full-text query, ranked by relevance.
+	// Tag, Series, Limit and Offset from opts apply; sort options are ignored.
+	// Returns the results, total count (before pagination), and any error.
+	Search(ctx context.Context, query string, opts ListOptions) ([]SearchResult, int, error)
+
 	// GetPostAsset retrieves an asset from a post's bundle directory.
 	GetPostAsset(ctx context.Context, slug, filename string) ([]byte, error)
+
+	// GetPostImage returns the resized variants of an image in a post's
+	// bundle directory.
+	GetPostImage(ctx context.Context, slug, filename string) (*images.Image, error)
+
+	// Version returns the version of the published content as a whole.
+	// Every listing, feed and search result is derived from it.
+	Version(ctx context.Context) (Version, error)
+
+	// PostVersion returns the version of a published post's content,
+	// which also covers its related posts and series.
+	PostVersion(ctx context.Context, slug string) (Version, error)
+
+	// GetHistory returns the revisions of a published post, newest first.
+	// Stores that don't track history return none.
+	GetHistory(ctx context.Context, slug string) ([]Revision, error)
 }
@@ -0,0 +1,104 @@
+package content
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Version identifies a state of the store's content, for HTTP validators.
+type Version struct {
+	ETag     string    // Content hash; changes whenever the content does
+	Modified time.Time // When the content last changed, to the second
+}
+
+// digest hashes parts into a short hex string. Parts are separated so
+// that moving bytes between them changes the result.
+func digest(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// hashPost returns a digest of everything a post contributes to a
+// response: its metadata, including resolved authors, and rendered HTML.
+func hashPost(meta PostMeta, html string) string {
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		// PostMeta has no unmarshalable fields, but fall back to the
+		// title and HTML rather than fail to load the post
+		encoded = []byte(meta.Title)
+	}
+	return digest(string(encoded), html)
+}
+
+// buildVersions computes the listing version and the version of each
+// published post from the indexes. Must be called with s.mu held, after
+// the rest of buildIndexes. A version keeps its Modified time while its
+// ETag is unchanged, so Schedule's hourly rebuild doesn't invalidate
+// clients' caches.
+func (s *EmbeddedStore) buildVersions() {
+	now := time.Now().Truncate(time.Second)
+	update := func(old Version, etag string) Version {
+		if old.ETag == etag {
+			return old
+		}
+		return Version{ETag: etag, Modified: now}
+	}
+
+	// Listings depend on every published post plus the series and author
+	// summaries, which carry series.yaml, config.yaml and time-relative
+	// fields such as HasRecentPosts
+	parts := make([]string, 0, len(s.sorted)*2+2)
+	for _, post := range s.sorted {
+		parts = append(parts, post.Meta.Slug, post.Hash)
+	}
+	series, _ := json.Marshal(s.series)
+	authors, _ := json.Marshal(s.authors)
+	parts = append(parts, string(series), string(authors))
+	s.version = update(s.version, digest(parts...))
+
+	// A post's response also includes its related posts and series
+	versions := make(map[string]Version, len(s.posts))
+	for slug, post := range s.posts {
+		parts := []string{post.Hash}
+		for _, related := range s.related[slug] {
+			parts = append(parts, related.Hash)
+		}
+		if series, ok := s.seriesIndex[post.Meta.Series]; ok {
+			info, _ := json.Marshal(series.Info)
+			parts = append(parts, string(info))
+			for _, member := range series.Posts {
+				parts = append(parts, member.Hash)
+			}
+		}
+		versions[slug] = update(s.postVersions[slug], digest(parts...))
+	}
+	s.postVersions = versions
+}
+
+// Version returns the version of the published content as a whole, which
+// every listing is derived from.
+func (s *EmbeddedStore) Version(_ context.Context) (Version, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version, nil
+}
+
+// PostVersion returns the version of a published post's content, including
+// its related posts and series.
+func (s *EmbeddedStore) PostVersion(_ context.Context, slug string) (Version, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	version, ok := s.postVersions[slug]
+	if !ok {
+		return Version{}, ErrPostNotFound
+	}
+	return version, nil
+}
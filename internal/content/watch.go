@@ -0,0 +1,110 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long Watch waits for a burst of filesystem events
+// to settle before reloading. Editors often write a file in several steps.
+const watchDebounce = 100 * time.Millisecond
+
+// Watch monitors dir for changes and reloads the affected posts until ctx
+// is cancelled. dir must be the OS directory backing the store's filesystem,
+// so that event paths can be mapped back to paths within it.
+// Reload failures are logged and the previous content is kept.
+func (s *EmbeddedStore) Watch(ctx context.Context, dir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	// fsnotify doesn't recurse, so every bundle directory needs its own watch
+	if err := addWatchTree(watcher, dir); err != nil {
+		return err
+	}
+
+	slog.Info("Watching content directory", "dir", dir)
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+
+			// Watch directories created after startup (new bundles)
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchTree(watcher, event.Name); err != nil {
+						slog.Warn("Failed to watch new directory", "dir", event.Name, "error", err)
+					}
+				}
+			}
+
+			rel, err := filepath.Rel(dir, event.Name)
+			if err != nil {
+				continue
+			}
+			pending[rel] = true
+			timer.Reset(watchDebounce)
+
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			clear(pending)
+
+			if err := s.Reload(paths...); err != nil {
+				slog.Error("Failed to reload content", "paths", paths, "error", err)
+				continue
+			}
+			slog.Info("Reloaded content", "paths", paths)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("Content watcher error", "error", err)
+		}
+	}
+}
+
+// addWatchTree adds root and all directories beneath it to the watcher.
+func addWatchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil // Removed while walking
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("watching %s: %w", path, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+package content
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestStore_Watch(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		dir := t.TempDir()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+		write := func(name, title string) {
+			t.Helper()
+			data := []byte("---\ntitle: " + title + "\npublishDate: " + past + "\n---\nContent.")
+			if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+				t.Fatalf("writing %s: %v", name, err)
+			}
+		}
+
+		write("post.md", "Before")
+
+		store, err := open(afero.NewBasePathFs(afero.NewOsFs(), dir))
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- store.Watch(ctx, dir) }()
+		defer func() {
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("Watch() error = %v", err)
+			}
+		}()
+
+		// Give the watcher time to register before writing
+		time.Sleep(50 * time.Millisecond)
+		write("post.md", "After")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if post, err := store.GetPost(ctx, "post"); err == nil && post.Meta.Title == "After" {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Error("post was not reloaded after change on disk")
+	})
+}
+
+func TestStore_WatchBundleDirs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open storeOpener) {
+		dir := t.TempDir()
+		past := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+		// Bundles take their slug from the directory name
+		write := func(name string) {
+			t.Helper()
+			data := []byte("---\ntitle: Bundle\npublishDate: " + past + "\n---\nContent.")
+			if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+				t.Fatalf("creating directory for %s: %v", name, err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+				t.Fatalf("writing %s: %v", name, err)
+			}
+		}
+
+		write("old/index.md")
+		write("doomed/index.md")
+
+		store, err := open(afero.NewBasePathFs(afero.NewOsFs(), dir))
+		if err != nil {
+			t.Fatalf("open() error = %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- store.Watch(ctx, dir) }()
+		defer func() {
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("Watch() error = %v", err)
+			}
+		}()
+
+		// waitFor polls until each slug is served or not, as want says
+		waitFor := func(want map[string]bool) {
+			t.Helper()
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) {
+				matched := true
+				for slug, served := range want {
+					if _, err := store.GetPost(ctx, slug); (err == nil) != served {
+						matched = false
+					}
+				}
+				if matched {
+					return
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			t.Errorf("served posts don't match %v after change on disk", want)
+		}
+
+		// Give the watcher time to register before changing anything
+		time.Sleep(50 * time.Millisecond)
+
+		t.Run("rename", func(t *testing.T) {
+			// Only the directory reports events, not the files within it
+			if err := os.Rename(filepath.Join(dir, "old"), filepath.Join(dir, "new")); err != nil {
+				t.Fatalf("renaming bundle: %v", err)
+			}
+			waitFor(map[string]bool{"old": false, "new": true})
+		})
+
+		t.Run("delete", func(t *testing.T) {
+			if err := os.RemoveAll(filepath.Join(dir, "doomed")); err != nil {
+				t.Fatalf("removing bundle: %v", err)
+			}
+			waitFor(map[string]bool{"doomed": false, "new": true})
+		})
+	})
+}
@@ -0,0 +1,338 @@
+// Package feed builds RSS 2.0 and Atom feeds from the content store.
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"therefore/internal/content"
+)
+
+// MaxItems is the number of most recent posts included in a feed.
+const MaxItems = 20
+
+// siteTitle is the feed title for the site-wide feed.
+const siteTitle = "Therefore"
+
+// Format selects the feed encoding.
+type Format string
+
+const (
+	// FormatRSS encodes the feed as RSS 2.0.
+	FormatRSS Format = "rss"
+	// FormatAtom encodes the feed as Atom 1.0.
+	FormatAtom Format = "atom"
+)
+
+// ContentType returns the MIME type for the format.
+func (f Format) ContentType() string {
+	if f == FormatAtom {
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/rss+xml; charset=utf-8"
+}
+
+// Filename returns the file name the format is served under.
+func (f Format) Filename() string {
+	if f == FormatAtom {
+		return "atom.xml"
+	}
+	return "feed.xml"
+}
+
+// Feed is a list of posts with the channel metadata needed to encode it.
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // URL of the HTML page the feed mirrors
+	Path        string // Path prefix the feed files are served under (empty for the site feed)
+	Posts       []*content.Post
+
+	baseURL string
+}
+
+// Builder assembles feeds from a content store.
+type Builder struct {
+	store   content.ContentStore
+	baseURL string
+}
+
+// NewBuilder creates a Builder that links posts relative to baseURL.
+func NewBuilder(store content.ContentStore, baseURL string) *Builder {
+	return &Builder{
+		store:   store,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Site returns the feed of the most recent posts.
+func (b *Builder) Site(ctx context.Context) (*Feed, error) {
+	posts, _, err := b.store.ListPosts(ctx, content.ListOptions{Limit: MaxItems})
+	if err != nil {
+		return nil, fmt.Errorf("listing posts: %w", err)
+	}
+
+	return &Feed{
+		Title:       siteTitle,
+		Description: "A blog exploring ideas at the intersection of philosophy and theology.",
+		Link:        b.baseURL + "/posts",
+		Posts:       posts,
+		baseURL:     b.baseURL,
+	}, nil
+}
+
+// Tag returns the feed of the most recent posts with the given tag.
+// Returns content.ErrPostNotFound if no posts have the tag.
+func (b *Builder) Tag(ctx context.Context, tag string) (*Feed, error) {
+	posts, _, err := b.store.ListPosts(ctx, content.ListOptions{Tag: tag, Limit: MaxItems})
+	if err != nil {
+		return nil, fmt.Errorf("listing posts: %w", err)
+	}
+	if len(posts) == 0 {
+		return nil, content.ErrPostNotFound
+	}
+
+	return &Feed{
+		Title:       fmt.Sprintf("Posts tagged \"%s\" — %s", tag, siteTitle),
+		Description: fmt.Sprintf("All posts tagged \"%s\" on %s.", tag, siteTitle),
+		Link:        b.baseURL + "/tags/" + url.PathEscape(tag),
+		Path:        "/tags/" + url.PathEscape(tag),
+		Posts:       posts,
+		baseURL:     b.baseURL,
+	}, nil
+}
+
+// Series returns the feed of the most recent posts in the given series.
+// Returns content.ErrPostNotFound if the series has no posts.
+func (b *Builder) Series(ctx context.Context, series string) (*Feed, error) {
+	posts, _, err := b.store.ListPosts(ctx, content.ListOptions{Series: series, Limit: MaxItems})
+	if err != nil {
+		return nil, fmt.Errorf("listing posts: %w", err)
+	}
+	if len(posts) == 0 {
+		return nil, content.ErrPostNotFound
+	}
+
+	return &Feed{
+		Title:       fmt.Sprintf("%s — %s", series, siteTitle),
+		Description: fmt.Sprintf("Posts in the \"%s\" series on %s.", series, siteTitle),
+		Link:        b.baseURL + "/series/" + url.PathEscape(series),
+		Path:        "/series/" + url.PathEscape(series),
+		Posts:       posts,
+		baseURL:     b.baseURL,
+	}, nil
+}
+
+// URL returns the absolute URL of the feed in the given format.
+func (f *Feed) URL(format Format) string {
+	return f.baseURL + f.Path + "/" + format.Filename()
+}
+
+// Encode renders the feed as an XML document in the given format.
+func (f *Feed) Encode(format Format) ([]byte, error) {
+	var doc any
+	if format == FormatAtom {
+		doc = f.atom()
+	} else {
+		doc = f.rss()
+	}
+
+	output, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s feed: %w", format, err)
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+// updated returns the most recent publish or revision date in the feed.
+func (f *Feed) updated() time.Time {
+	var latest time.Time
+	for _, post := range f.Posts {
+		if modified := post.Meta.LastModified(); modified.After(latest) {
+			latest = modified
+		}
+	}
+	return latest
+}
+
+func (f *Feed) postURL(post *content.Post) string {
+	return f.baseURL + "/posts/" + post.Meta.Slug
+}
+
+// RSS 2.0
+
+type rssDoc struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	XMLNSAtom string     `xml:"xmlns:atom,attr"`
+	XMLNSDC   string     `xml:"xmlns:dc,attr"`
+	XMLNSCont string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description,omitempty"`
+	Content     string   `xml:"content:encoded"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (f *Feed) rss() rssDoc {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		AtomLink: rssAtomLink{
+			Href: f.URL(FormatRSS),
+			Rel:  "self",
+			Type: "application/rss+xml",
+		},
+	}
+	if updated := f.updated(); !updated.IsZero() {
+		channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+
+	for _, post := range f.Posts {
+		link := f.postURL(post)
+		channel.Items = append(channel.Items, rssItem{
+			Title:       post.Meta.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     post.Meta.PublishDate.Format(time.RFC1123Z),
+			Creator:     authorNames(post.Meta.Authors),
+			Categories:  post.Meta.Tags,
+			Description: post.Meta.Summary,
+			Content:     post.HTMLContent,
+		})
+	}
+
+	return rssDoc{
+		Version:   "2.0",
+		XMLNSAtom: "http://www.w3.org/2005/Atom",
+		XMLNSDC:   "http://purl.org/dc/elements/1.1/",
+		XMLNSCont: "http://purl.org/rss/1.0/modules/content/",
+		Channel:   channel,
+	}
+}
+
+// authorNames joins the names of a post's authors for dc:creator.
+func authorNames(authors []content.Author) string {
+	names := make([]string, len(authors))
+	for i, author := range authors {
+		names[i] = author.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Atom 1.0
+
+type atomDoc struct {
+	XMLName xml.Name    `xml:"feed"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Base  string `xml:"xml:base,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomAuthor   `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    atomText       `xml:"content"`
+}
+
+func (f *Feed) atom() atomDoc {
+	doc := atomDoc{
+		XMLNS:   "http://www.w3.org/2005/Atom",
+		Title:   f.Title,
+		ID:      f.URL(FormatAtom),
+		Updated: f.updated().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.URL(FormatAtom), Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+		// Entries without their own author inherit the feed author
+		Author: atomAuthor{Name: siteTitle},
+	}
+
+	for _, post := range f.Posts {
+		link := f.postURL(post)
+		entry := atomEntry{
+			Title:     post.Meta.Title,
+			ID:        link,
+			Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published: post.Meta.PublishDate.Format(time.RFC3339),
+			Updated:   post.Meta.LastModified().Format(time.RFC3339),
+			// Resolve relative bundle asset paths against the site root
+			Content: atomText{Type: "html", Base: f.baseURL + "/", Value: post.HTMLContent},
+		}
+		for _, author := range post.Meta.Authors {
+			entry.Authors = append(entry.Authors, atomAuthor{Name: author.Name})
+		}
+		for _, tag := range post.Meta.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		if post.Meta.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: post.Meta.Summary}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return doc
+}
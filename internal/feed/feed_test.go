@@ -0,0 +1,163 @@
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"therefore/internal/content"
+	"therefore/internal/renderer"
+
+	"github.com/spf13/afero"
+)
+
+// mockRenderer implements content.Renderer for testing.
+type mockRenderer struct{}
+
+func (m *mockRenderer) RenderDocument(raw string, _ *renderer.RenderContext) (renderer.Document, error) {
+	return renderer.Document{HTML: "<p>" + raw + "</p>"}, nil
+}
+
+func newTestStore(t *testing.T) content.ContentStore {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "first.md", []byte(`---
+title: First Post
+slug: first
+summary: The first one
+publishDate: 2024-01-15T00:00:00Z
+tags: [philosophy]
+series: Ethics
+author:
+  name: Jane Doe
+---
+First content.`), 0644)
+	_ = afero.WriteFile(fs, "second.md", []byte(`---
+title: Second & Last
+slug: second
+publishDate: 2024-02-20T00:00:00Z
+tags: [theology]
+---
+Second content.`), 0644)
+
+	store, err := content.NewEmbeddedStore(fs, &mockRenderer{})
+	if err != nil {
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+	return store
+}
+
+func TestFeed_RSS(t *testing.T) {
+	b := NewBuilder(newTestStore(t), "https://example.com/")
+
+	f, err := b.Site(context.Background())
+	if err != nil {
+		t.Fatalf("Site() error = %v", err)
+	}
+
+	output, err := f.Encode(FormatRSS)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var doc rssDoc
+	if err := xml.Unmarshal(output, &doc); err != nil {
+		t.Fatalf("RSS is not valid XML: %v\n%s", err, output)
+	}
+
+	if len(doc.Channel.Items) != 2 {
+		t.Fatalf("len(Items) = %d, want 2", len(doc.Channel.Items))
+	}
+
+	// Newest first
+	item := doc.Channel.Items[0]
+	if item.Title != "Second & Last" {
+		t.Errorf("Items[0].Title = %q, want %q", item.Title, "Second & Last")
+	}
+	if item.Link != "https://example.com/posts/second" {
+		t.Errorf("Items[0].Link = %q", item.Link)
+	}
+	if item.PubDate != "Tue, 20 Feb 2024 00:00:00 +0000" {
+		t.Errorf("Items[0].PubDate = %q", item.PubDate)
+	}
+
+	body := string(output)
+	for _, want := range []string{
+		"<dc:creator>Jane Doe</dc:creator>",
+		"<content:encoded>&lt;p&gt;First content.&lt;/p&gt;</content:encoded>",
+		`<atom:link href="https://example.com/feed.xml" rel="self"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("RSS missing %q\n%s", want, body)
+		}
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	b := NewBuilder(newTestStore(t), "https://example.com")
+
+	f, err := b.Site(context.Background())
+	if err != nil {
+		t.Fatalf("Site() error = %v", err)
+	}
+
+	output, err := f.Encode(FormatAtom)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var doc atomDoc
+	if err := xml.Unmarshal(output, &doc); err != nil {
+		t.Fatalf("Atom is not valid XML: %v\n%s", err, output)
+	}
+
+	if doc.Updated != time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC).Format(time.RFC3339) {
+		t.Errorf("Updated = %q, want newest publish date", doc.Updated)
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("len(Entries) = %d, want 2", len(doc.Entries))
+	}
+
+	entry := doc.Entries[1]
+	if entry.ID != "https://example.com/posts/first" {
+		t.Errorf("Entries[1].ID = %q", entry.ID)
+	}
+	if len(entry.Authors) != 1 || entry.Authors[0].Name != "Jane Doe" {
+		t.Errorf("Entries[1].Authors = %+v, want Jane Doe", entry.Authors)
+	}
+	if entry.Content.Value != "<p>First content.</p>" {
+		t.Errorf("Entries[1].Content = %q", entry.Content.Value)
+	}
+}
+
+func TestBuilder_TagAndSeries(t *testing.T) {
+	b := NewBuilder(newTestStore(t), "https://example.com")
+	ctx := context.Background()
+
+	f, err := b.Tag(ctx, "theology")
+	if err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+	if len(f.Posts) != 1 || f.Posts[0].Meta.Slug != "second" {
+		t.Errorf("Tag(theology) posts = %v, want [second]", f.Posts)
+	}
+	if got := f.URL(FormatRSS); got != "https://example.com/tags/theology/feed.xml" {
+		t.Errorf("URL() = %q", got)
+	}
+
+	f, err = b.Series(ctx, "Ethics")
+	if err != nil {
+		t.Fatalf("Series() error = %v", err)
+	}
+	if len(f.Posts) != 1 || f.Posts[0].Meta.Slug != "first" {
+		t.Errorf("Series(Ethics) posts = %v, want [first]", f.Posts)
+	}
+
+	if _, err := b.Tag(ctx, "nonexistent"); !errors.Is(err, content.ErrPostNotFound) {
+		t.Errorf("Tag(nonexistent) error = %v, want ErrPostNotFound", err)
+	}
+}
@@ -2,21 +2,31 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"therefore/internal/content"
+	"therefore/internal/preview"
+	"therefore/internal/renderer"
 	"therefore/internal/views"
 
 	"github.com/labstack/echo/v5"
 )
 
+// maxPageSize caps the posts or results in one listing response, and is
+// the page size when the request doesn't set a limit.
+const maxPageSize = 100
+
 // APIHandler handles JSON API requests.
 type APIHandler struct {
-	store content.ContentStore
+	store    content.ContentStore
+	previews *preview.Signer
 }
 
 // NewAPIHandler creates a new APIHandler.
@@ -24,31 +34,121 @@ func NewAPIHandler(store content.ContentStore) *APIHandler {
 	return &APIHandler{store: store}
 }
 
+// EnablePreviews lets GetPost serve drafts and scheduled posts to requests
+// carrying a valid ?preview= token signed by signer.
+func (h *APIHandler) EnablePreviews(signer *preview.Signer) {
+	h.previews = signer
+}
+
 // AuthorResponse is the JSON representation of an author.
 type AuthorResponse struct {
+	ID     string `json:"id,omitempty"` // Empty for inline authors, which have no page
 	Name   string `json:"name,omitempty"`
 	Avatar string `json:"avatar,omitempty"`
 	Bio    string `json:"bio,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// AuthorCountResponse is the JSON representation of an author with their
+// post count.
+type AuthorCountResponse struct {
+	AuthorResponse
+	Count int `json:"count"`
+}
+
+// AuthorDetailResponse is the JSON response for a single author.
+type AuthorDetailResponse struct {
+	AuthorResponse
+	Posts      []PostResponse `json:"posts"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"nextCursor,omitempty"` // Fetches the following page
 }
 
 // PostResponse is the JSON representation of a post.
 type PostResponse struct {
-	Slug          string          `json:"slug"`
-	Title         string          `json:"title"`
-	Summary       string          `json:"summary,omitempty"`
-	PublishDate   string          `json:"publishDate"`
-	Tags          []string        `json:"tags,omitempty"`
-	Series        string          `json:"series,omitempty"`
-	ReadingTime   int             `json:"readingTime"` // minutes
-	SearchContent string          `json:"searchContent,omitempty"`
-	HTMLContent   string          `json:"htmlContent,omitempty"`
-	Author        *AuthorResponse `json:"author,omitempty"`
-}
-
-// ListPostsResponse is the JSON response for listing posts.
+	Slug          string           `json:"slug"`
+	Title         string           `json:"title"`
+	Summary       string           `json:"summary,omitempty"`
+	PublishDate   string           `json:"publishDate"`
+	UpdatedDate   string           `json:"updatedDate,omitempty"` // Set only for revised posts
+	Tags          []string         `json:"tags,omitempty"`
+	Series        string           `json:"series,omitempty"`
+	ReadingTime   int              `json:"readingTime"` // minutes
+	SearchContent string           `json:"searchContent,omitempty"`
+	HTMLContent   string           `json:"htmlContent,omitempty"`
+	Author        *AuthorResponse  `json:"author,omitempty"` // Primary author
+	Authors       []AuthorResponse `json:"authors,omitempty"`
+	TOC           []TOCEntry       `json:"toc,omitempty"`
+	Changelog     []ChangelogEntry `json:"changelog,omitempty"`
+	Related       []PostResponse   `json:"related,omitempty"`
+	SeriesNav     *SeriesNav       `json:"seriesNav,omitempty"`
+	Draft         bool             `json:"draft,omitempty"`
+}
+
+// ChangelogEntry is a dated note describing a revision to a post.
+type ChangelogEntry struct {
+	Date string `json:"date"`
+	Note string `json:"note"`
+}
+
+// TOCEntry is a heading in a post's table of contents.
+type TOCEntry struct {
+	Level    int        `json:"level"`
+	ID       string     `json:"id"`
+	Text     string     `json:"text"`
+	Children []TOCEntry `json:"children,omitempty"`
+}
+
+// SeriesNav locates a post within its series.
+type SeriesNav struct {
+	Title string    `json:"title"` // Display title of the series
+	Part  int       `json:"part"`
+	Total int       `json:"total"`
+	Prev  *PostLink `json:"prev,omitempty"`
+	Next  *PostLink `json:"next,omitempty"`
+}
+
+// PostLink is the minimum needed to link to a post.
+type PostLink struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+}
+
+// RevisionResponse is the JSON representation of a recorded change to a post.
+type RevisionResponse struct {
+	ID      string `json:"id"`
+	Date    string `json:"date"` // RFC 3339, since a post may change several times a day
+	Author  string `json:"author,omitempty"`
+	Message string `json:"message"`
+}
+
+// HistoryResponse is the JSON response for a post's revision history.
+type HistoryResponse struct {
+	Slug      string             `json:"slug"`
+	Revisions []RevisionResponse `json:"revisions"` // Newest first; empty if the store keeps no history
+}
+
+// ListPostsResponse is the JSON response for listing posts. The cursors
+// fetch the following and preceding pages, when there are any.
 type ListPostsResponse struct {
-	Posts []PostResponse `json:"posts"`
-	Total int            `json:"total"`
+	Posts      []PostResponse `json:"posts"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+	PrevCursor string         `json:"prevCursor,omitempty"`
+}
+
+// SearchResultResponse is the JSON representation of a search hit.
+type SearchResultResponse struct {
+	PostResponse
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet,omitempty"` // HTML with matches wrapped in <mark>
+}
+
+// SearchResponse is the JSON response for a search query.
+type SearchResponse struct {
+	Query   string                 `json:"query"`
+	Results []SearchResultResponse `json:"results"`
+	Total   int                    `json:"total"`
 }
 
 // TagResponse is the JSON representation of a tag.
@@ -57,74 +157,393 @@ type TagResponse struct {
 	Count int    `json:"count"`
 }
 
+// ArchiveYearResponse is the JSON representation of a year in the archive.
+type ArchiveYearResponse struct {
+	Year   int                    `json:"year"`
+	Count  int                    `json:"count"`
+	Months []ArchiveMonthResponse `json:"months"` // Latest first
+}
+
+// ArchiveMonthResponse is the JSON representation of a month in the archive.
+type ArchiveMonthResponse struct {
+	Month int `json:"month"` // 1 for January
+	Count int `json:"count"`
+}
+
+// ArchivePeriodResponse is the JSON response for a year or month of the
+// archive.
+type ArchivePeriodResponse struct {
+	Year       int            `json:"year"`
+	Month      int            `json:"month,omitempty"` // Absent for a whole year
+	Posts      []PostResponse `json:"posts"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"nextCursor,omitempty"` // Fetches the following page
+}
+
 // SeriesResponse is the JSON representation of a series.
 type SeriesResponse struct {
 	Series         string   `json:"series"`
 	Count          int      `json:"count"`
 	TopTags        []string `json:"topTags,omitempty"`
 	HasRecentPosts bool     `json:"hasRecentPosts"`
+	Title          string   `json:"title"`
+	Description    string   `json:"description,omitempty"`
+	Cover          string   `json:"cover,omitempty"`
+}
+
+// SeriesPostResponse is a post in a series with its part number.
+type SeriesPostResponse struct {
+	PostResponse
+	Part int `json:"part"`
+}
+
+// SeriesDetailResponse is the JSON response for a single series.
+type SeriesDetailResponse struct {
+	Series      string               `json:"series"`
+	Title       string               `json:"title"`
+	Description string               `json:"description,omitempty"`
+	Cover       string               `json:"cover,omitempty"`
+	Posts       []SeriesPostResponse `json:"posts"`
 }
 
 // ListPosts returns a JSON list of posts.
 func (h *APIHandler) ListPosts(c *echo.Context) error {
-	opts := content.ListOptions{}
+	opts, err := parseListOptions(c)
+	if err != nil {
+		return err
+	}
+	if err := parseCursor(c, &opts); err != nil {
+		return err
+	}
+	if done, err := revalidateListing(c, h.store); done {
+		return err
+	}
 
-	if tag := c.QueryParam("tag"); tag != "" {
-		opts.Tag = tag
+	page, err := h.listPage(c.Request().Context(), opts)
+	if err != nil {
+		return err
 	}
-	if series := c.QueryParam("series"); series != "" {
-		opts.Series = series
+
+	resp := ListPostsResponse{
+		Posts:      make([]PostResponse, 0, len(page.posts)),
+		Total:      page.total,
+		NextCursor: page.next,
+		PrevCursor: page.prev,
 	}
-	if limitStr := c.QueryParam("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil {
-			opts.Limit = limit
-		}
+
+	for _, post := range page.posts {
+		resp.Posts = append(resp.Posts, postToResponse(post, false, true))
 	}
-	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
-		if offset, err := strconv.Atoi(offsetStr); err == nil {
-			opts.Offset = offset
-		}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// postPage is a page of a post listing, with cursors for the pages either
+// side of it when there are any.
+type postPage struct {
+	posts      []*content.Post
+	total      int
+	next, prev string
+}
+
+// listPage lists a page of opts.Limit posts. Errors are HTTP errors, ready
+// to return from a handler.
+func (h *APIHandler) listPage(ctx context.Context, opts content.ListOptions) (postPage, error) {
+	// Ask for one post more than the page to learn whether another page
+	// follows in the direction of travel
+	limit := opts.Limit
+	opts.Limit++
+	posts, total, err := h.store.ListPosts(ctx, opts)
+	if errors.Is(err, content.ErrInvalidCursor) {
+		return postPage{}, echo.NewHTTPError(http.StatusBadRequest, "cursor is for a different sortBy or sortOrder")
 	}
-	if sortBy := c.QueryParam("sortBy"); sortBy != "" {
-		switch sortBy {
-		case "date":
-			opts.SortBy = content.SortByDate
-		case "title":
-			opts.SortBy = content.SortByTitle
-		case "readingTime":
-			opts.SortBy = content.SortByReadingTime
+	if err != nil {
+		return postPage{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to list posts")
+	}
+
+	backward := opts.Cursor != nil && opts.Cursor.Before()
+	more := len(posts) > limit
+	if more && backward {
+		posts = posts[1:]
+	} else if more {
+		posts = posts[:limit]
+	}
+
+	page := postPage{posts: posts, total: total}
+	// Going one way, there are posts back the way we came, unless this
+	// is the first page
+	if len(posts) > 0 {
+		if backward && more || !backward && (opts.Cursor != nil || opts.Offset > 0) {
+			page.prev = content.CursorBefore(posts[0], opts).String()
 		}
+		if !backward && more || backward {
+			page.next = content.CursorAfter(posts[len(posts)-1], opts).String()
+		}
+	}
+	return page, nil
+}
+
+// parseCursor sets opts.Cursor from the cursor query parameter, if there
+// is one.
+func parseCursor(c *echo.Context, opts *content.ListOptions) error {
+	token := c.QueryParam("cursor")
+	if token == "" {
+		return nil
+	}
+	if opts.Offset > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "cursor and offset can't be combined")
+	}
+	cursor, err := content.ParseCursor(token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor")
+	}
+	opts.Cursor = &cursor
+	return nil
+}
+
+// parseListOptions reads filtering, sorting and pagination options from
+// the query string. Invalid values are a 400 error naming the parameter.
+// The limit defaults to, and is capped at, maxPageSize.
+func parseListOptions(c *echo.Context) (content.ListOptions, error) {
+	opts := content.ListOptions{
+		Series: c.QueryParam("series"),
+		Author: c.QueryParam("author"),
+	}
+
+	// A single tag uses the tag index; several are matched by tagMode
+	switch tags := c.QueryParams()["tag"]; len(tags) {
+	case 0:
+	case 1:
+		opts.Tag = tags[0]
+	default:
+		opts.Tags = tags
 	}
-	if sortOrder := c.QueryParam("sortOrder"); sortOrder != "" {
-		switch sortOrder {
-		case "asc":
-			opts.SortOrder = content.SortAsc
-		case "desc":
-			opts.SortOrder = content.SortDesc
+	switch mode := c.QueryParam("tagMode"); mode {
+	case "", "all":
+	case "any":
+		opts.TagMode = content.TagMatchAny
+	default:
+		return opts, badParam("tagMode", mode, "must be all or any")
+	}
+	opts.ExcludeTags = c.QueryParams()["excludeTag"]
+
+	var err error
+	if opts.From, err = queryDate(c, "from", false); err != nil {
+		return opts, err
+	}
+	if opts.To, err = queryDate(c, "to", true); err != nil {
+		return opts, err
+	}
+	if !opts.From.IsZero() && !opts.To.IsZero() && opts.From.After(opts.To) {
+		return opts, echo.NewHTTPError(http.StatusBadRequest, "from must not be after to")
+	}
+
+	if opts.MinReadingTime, err = queryCount(c, "minReadingTime"); err != nil {
+		return opts, err
+	}
+	if opts.MaxReadingTime, err = queryCount(c, "maxReadingTime"); err != nil {
+		return opts, err
+	}
+	if opts.MaxReadingTime > 0 && opts.MinReadingTime > opts.MaxReadingTime {
+		return opts, echo.NewHTTPError(http.StatusBadRequest, "minReadingTime must not be more than maxReadingTime")
+	}
+
+	if opts.Limit, err = queryCount(c, "limit"); err != nil {
+		return opts, err
+	}
+	if opts.Limit == 0 || opts.Limit > maxPageSize {
+		opts.Limit = maxPageSize
+	}
+	if opts.Offset, err = queryCount(c, "offset"); err != nil {
+		return opts, err
+	}
+
+	switch sortBy := c.QueryParam("sortBy"); sortBy {
+	case "":
+	case "date":
+		opts.SortBy = content.SortByDate
+	case "title":
+		opts.SortBy = content.SortByTitle
+	case "readingTime":
+		opts.SortBy = content.SortByReadingTime
+	default:
+		return opts, badParam("sortBy", sortBy, "must be date, title or readingTime")
+	}
+	switch sortOrder := c.QueryParam("sortOrder"); sortOrder {
+	case "":
+	case "asc":
+		opts.SortOrder = content.SortAsc
+	case "desc":
+		opts.SortOrder = content.SortDesc
+	default:
+		return opts, badParam("sortOrder", sortOrder, "must be asc or desc")
+	}
+
+	return opts, nil
+}
+
+// queryCount reads a non-negative integer query parameter, or 0 if unset.
+func queryCount(c *echo.Context, name string) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, badParam(name, value, "must be a non-negative integer")
+	}
+	return n, nil
+}
+
+// queryDate reads a YYYY-MM-DD or RFC 3339 query parameter, or the zero
+// time if unset. A bare date means the start of that day in UTC, or the
+// end of it if endOfDay is set, so ranges include their last day.
+func queryDate(c *echo.Context, name string, endOfDay bool) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
 		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, badParam(name, value, "must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
 	}
+	return t, nil
+}
+
+// badParam returns a 400 error for an invalid query parameter.
+func badParam(name, value, reason string) error {
+	return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid %s %q: %s", name, value, reason))
+}
 
-	posts, total, err := h.store.ListPosts(c.Request().Context(), opts)
+// Search returns posts matching a full-text query, ranked by relevance.
+func (h *APIHandler) Search(c *echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing search query")
+	}
+	opts, err := parseListOptions(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list posts")
+		return err
+	}
+	if done, err := revalidateListing(c, h.store); done {
+		return err
 	}
 
-	resp := ListPostsResponse{
-		Posts: make([]PostResponse, 0, len(posts)),
-		Total: total,
+	results, total, err := h.store.Search(c.Request().Context(), query, opts)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to search posts")
 	}
 
-	for _, post := range posts {
-		resp.Posts = append(resp.Posts, postToResponse(post, false, true))
+	resp := SearchResponse{
+		Query:   query,
+		Results: make([]SearchResultResponse, 0, len(results)),
+		Total:   total,
+	}
+
+	for _, r := range results {
+		resp.Results = append(resp.Results, SearchResultResponse{
+			PostResponse: postToResponse(r.Post, false, false),
+			Score:        r.Score,
+			Snippet:      r.Snippet,
+		})
 	}
 
 	return c.JSON(http.StatusOK, resp)
 }
 
 // GetPost returns a single post as JSON.
+// Unpublished posts are returned only with a valid preview token.
 func (h *APIHandler) GetPost(c *echo.Context) error {
 	slug := c.Param("slug")
 	post, err := h.store.GetPost(c.Request().Context(), slug)
+	if errors.Is(err, content.ErrPostNotFound) && c.QueryParam("preview") != "" {
+		return h.getPreview(c, slug, c.QueryParam("preview"))
+	}
+	if err != nil {
+		if errors.Is(err, content.ErrPostNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "post not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get post")
+	}
+
+	version, err := h.store.PostVersion(c.Request().Context(), slug)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get post version")
+	}
+	if done, err := revalidate(c, version, cacheContent); done {
+		return err
+	}
+
+	related, err := h.store.GetRelated(c.Request().Context(), slug)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get related posts")
+	}
+
+	resp := postToResponse(post, true, false)
+	for _, r := range related {
+		resp.Related = append(resp.Related, postToResponse(r, false, false))
+	}
+
+	if post.Meta.Series != "" {
+		series, err := h.store.GetSeriesByName(c.Request().Context(), post.Meta.Series)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to get series")
+		}
+		resp.SeriesNav = seriesNav(series, slug)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetPostHistory returns the revisions of a published post, newest first.
+func (h *APIHandler) GetPostHistory(c *echo.Context) error {
+	slug := c.Param("slug")
+	revisions, err := h.store.GetHistory(c.Request().Context(), slug)
+	if err != nil {
+		if errors.Is(err, content.ErrPostNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "post not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get post history")
+	}
+
+	resp := HistoryResponse{Slug: slug, Revisions: make([]RevisionResponse, len(revisions))}
+	for i, r := range revisions {
+		resp.Revisions[i] = RevisionResponse{
+			ID:      r.ID,
+			Date:    r.Date.Format(time.RFC3339),
+			Author:  r.Author,
+			Message: r.Message,
+		}
+	}
+
+	// A commit can touch only a bundle asset, leaving the post's version
+	// as it was, so the history is validated by its own hash
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to encode post history")
+	}
+	if done, err := revalidate(c, content.Version{ETag: hashBytes(body)}, cacheContent); done {
+		return err
+	}
+	return c.JSONBlob(http.StatusOK, body)
+}
+
+// getPreview serves an unpublished post to the holder of a preview token.
+func (h *APIHandler) getPreview(c *echo.Context, slug, token string) error {
+	// Check the token before the store so a bad token can't probe for drafts
+	if err := h.previews.Verify(token, slug); err != nil {
+		if errors.Is(err, preview.ErrExpiredToken) {
+			return echo.NewHTTPError(http.StatusForbidden, "preview link has expired")
+		}
+		return echo.NewHTTPError(http.StatusForbidden, "invalid preview token")
+	}
+
+	post, err := h.store.GetPreview(c.Request().Context(), slug)
 	if err != nil {
 		if errors.Is(err, content.ErrPostNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "post not found")
@@ -132,11 +551,18 @@ func (h *APIHandler) GetPost(c *echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get post")
 	}
 
+	// Previews must never be indexed or cached by shared caches
+	c.Response().Header().Set("X-Robots-Tag", "noindex")
+	c.Response().Header().Set("Cache-Control", "private, no-store")
 	return c.JSON(http.StatusOK, postToResponse(post, true, false))
 }
 
 // ListTags returns a JSON list of tags with counts.
 func (h *APIHandler) ListTags(c *echo.Context) error {
+	if done, err := revalidateListing(c, h.store); done {
+		return err
+	}
+
 	tags, err := h.store.GetTags(c.Request().Context())
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get tags")
@@ -155,6 +581,10 @@ func (h *APIHandler) ListTags(c *echo.Context) error {
 
 // ListSeries returns a JSON list of series with counts.
 func (h *APIHandler) ListSeries(c *echo.Context) error {
+	if done, err := revalidateListing(c, h.store); done {
+		return err
+	}
+
 	series, err := h.store.GetSeries(c.Request().Context())
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get series")
@@ -167,12 +597,215 @@ func (h *APIHandler) ListSeries(c *echo.Context) error {
 			Count:          s.Count,
 			TopTags:        s.TopTags,
 			HasRecentPosts: s.HasRecentPosts,
+			Title:          s.Info.Title,
+			Description:    s.Info.Description,
+			Cover:          s.Info.Cover,
 		})
 	}
 
 	return c.JSON(http.StatusOK, resp)
 }
 
+// GetSeries returns a single series with its posts in reading order.
+func (h *APIHandler) GetSeries(c *echo.Context) error {
+	series, err := h.store.GetSeriesByName(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		if errors.Is(err, content.ErrSeriesNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "series not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get series")
+	}
+	if done, err := revalidateListing(c, h.store); done {
+		return err
+	}
+
+	resp := SeriesDetailResponse{
+		Series:      series.Name,
+		Title:       series.Info.Title,
+		Description: series.Info.Description,
+		Cover:       series.Info.Cover,
+		Posts:       make([]SeriesPostResponse, 0, len(series.Posts)),
+	}
+	for i, post := range series.Posts {
+		resp.Posts = append(resp.Posts, SeriesPostResponse{
+			PostResponse: postToResponse(post, false, false),
+			Part:         i + 1,
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListAuthors returns a JSON list of authors with post counts.
+func (h *APIHandler) ListAuthors(c *echo.Context) error {
+	if done, err := revalidateListing(c, h.store); done {
+		return err
+	}
+
+	authors, err := h.store.GetAuthors(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get authors")
+	}
+
+	resp := make([]AuthorCountResponse, 0, len(authors))
+	for _, a := range authors {
+		resp = append(resp, AuthorCountResponse{
+			AuthorResponse: authorToResponse(a.Author),
+			Count:          a.Count,
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetAuthor returns an author's profile with a page of their posts.
+// Accepts the same pagination, cursor and sort parameters as ListPosts.
+func (h *APIHandler) GetAuthor(c *echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	author, err := h.store.GetAuthor(ctx, id)
+	if err != nil {
+		if errors.Is(err, content.ErrAuthorNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "author not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get author")
+	}
+	opts, err := parseListOptions(c)
+	if err != nil {
+		return err
+	}
+	opts.Author = id
+	if err := parseCursor(c, &opts); err != nil {
+		return err
+	}
+	if done, err := revalidateListing(c, h.store); done {
+		return err
+	}
+
+	page, err := h.listPage(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	resp := AuthorDetailResponse{
+		AuthorResponse: authorToResponse(author),
+		Posts:          make([]PostResponse, 0, len(page.posts)),
+		Total:          page.total,
+		NextCursor:     page.next,
+	}
+	for _, post := range page.posts {
+		resp.Posts = append(resp.Posts, postToResponse(post, false, false))
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListArchive returns the years and months with posts and their counts,
+// latest first.
+func (h *APIHandler) ListArchive(c *echo.Context) error {
+	if done, err := revalidateListing(c, h.store); done {
+		return err
+	}
+
+	archive, err := h.store.GetArchive(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get archive")
+	}
+
+	resp := make([]ArchiveYearResponse, 0, len(archive))
+	for _, y := range archive {
+		year := ArchiveYearResponse{
+			Year:   y.Year,
+			Count:  y.Count,
+			Months: make([]ArchiveMonthResponse, 0, len(y.Months)),
+		}
+		for _, m := range y.Months {
+			year.Months = append(year.Months, ArchiveMonthResponse{Month: int(m.Month), Count: m.Count})
+		}
+		resp = append(resp, year)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetArchivePeriod returns a page of the posts of a year, or of a month if
+// the route has one, newest first. Accepts the same pagination, cursor and
+// sort parameters as ListPosts.
+func (h *APIHandler) GetArchivePeriod(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	yearStr := c.Param("year")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 1 {
+		return badParam("year", yearStr, "must be a positive integer")
+	}
+	var month time.Month
+	if monthStr := c.Param("month"); monthStr != "" {
+		m, err := strconv.Atoi(monthStr)
+		if err != nil || m < 1 || m > 12 {
+			return badParam("month", monthStr, "must be from 1 to 12")
+		}
+		month = time.Month(m)
+	}
+	opts, err := parseListOptions(c)
+	if err != nil {
+		return err
+	}
+	opts.Year, opts.Month = year, month
+	if err := parseCursor(c, &opts); err != nil {
+		return err
+	}
+
+	archive, err := h.store.GetArchive(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get archive")
+	}
+	if !inArchive(archive, year, month) {
+		return echo.NewHTTPError(http.StatusNotFound, "no posts from then")
+	}
+	if done, err := revalidateListing(c, h.store); done {
+		return err
+	}
+
+	page, err := h.listPage(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	resp := ArchivePeriodResponse{
+		Year:       year,
+		Month:      int(month),
+		Posts:      make([]PostResponse, 0, len(page.posts)),
+		Total:      page.total,
+		NextCursor: page.next,
+	}
+	for _, post := range page.posts {
+		resp.Posts = append(resp.Posts, postToResponse(post, false, false))
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// inArchive reports whether the archive has posts from a year, or from a
+// month of it if month is set.
+func inArchive(archive []content.ArchiveYear, year int, month time.Month) bool {
+	for _, y := range archive {
+		if y.Year != year {
+			continue
+		}
+		if month == 0 {
+			return true
+		}
+		for _, m := range y.Months {
+			if m.Month == month {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetPostAsset serves a static asset from a post's bundle directory.
 func (h *APIHandler) GetPostAsset(c *echo.Context) error {
 	slug := c.Param("slug")
@@ -181,9 +814,9 @@ func (h *APIHandler) GetPostAsset(c *echo.Context) error {
 	data, err := h.store.GetPostAsset(c.Request().Context(), slug, filename)
 	if err != nil {
 		if errors.Is(err, content.ErrPostNotFound) {
-			return echo.NewHTTPError(http.StatusNotFound, "post not found")
+			return assetNotFound(assetBundle, "post not found")
 		}
-		return echo.NewHTTPError(http.StatusNotFound, "asset not found")
+		return assetNotFound(assetBundle, "asset not found")
 	}
 
 	// Determine content type from extension
@@ -203,11 +836,44 @@ func (h *APIHandler) GetPostAsset(c *echo.Context) error {
 		contentType = "application/pdf"
 	}
 
-	c.Response().Header().Set("Content-Type", contentType)
-	c.Response().Header().Set("Cache-Control", "public, max-age=31536000") // 1 year
+	if done, err := revalidate(c, content.Version{ETag: hashBytes(data)}, cacheAsset); done {
+		return err
+	}
 	return c.Blob(http.StatusOK, contentType, data)
 }
 
+// GetPostImage serves a resized variant of a bundle image. The format is
+// negotiated from the Accept header: WebP when the client accepts it and a
+// WebP variant exists, the source format otherwise.
+func (h *APIHandler) GetPostImage(c *echo.Context) error {
+	slug := c.Param("slug")
+	filename := c.Param("filename")
+
+	width, err := strconv.Atoi(c.Param("width"))
+	if err != nil {
+		return assetNotFound(assetImage, "image not found")
+	}
+
+	img, err := h.store.GetPostImage(c.Request().Context(), slug, filename)
+	if err != nil {
+		if errors.Is(err, content.ErrPostNotFound) {
+			return assetNotFound(assetImage, "post not found")
+		}
+		return assetNotFound(assetImage, "image not found")
+	}
+
+	variant := img.Variant(width, c.Request().Header.Get("Accept"))
+	if variant == nil {
+		return assetNotFound(assetImage, "image not found")
+	}
+
+	c.Response().Header().Set("Vary", "Accept")
+	if done, err := revalidate(c, content.Version{ETag: hashBytes(variant.Data)}, cacheAsset); done {
+		return err
+	}
+	return c.Blob(http.StatusOK, variant.Format.ContentType(), variant.Data)
+}
+
 func hasExtension(filename string, exts ...string) bool {
 	for _, ext := range exts {
 		if len(filename) > len(ext) && filename[len(filename)-len(ext):] == ext {
@@ -217,28 +883,10 @@ func hasExtension(filename string, exts ...string) bool {
 	return false
 }
 
-var (
-	tagRegex = regexp.MustCompile(`<[^>]*>`)
-	wsRegex  = regexp.MustCompile(`\s+`)
-)
-
 // extractSearchContent extracts plain text from HTML content for search indexing.
 // It strips HTML tags, collapses whitespace, and truncates to maxLen characters.
 func extractSearchContent(htmlContent string, maxLen int) string {
-	// Remove HTML tags
-	text := tagRegex.ReplaceAllString(htmlContent, " ")
-
-	// Decode common HTML entities
-	text = strings.ReplaceAll(text, "&amp;", "&")
-	text = strings.ReplaceAll(text, "&lt;", "<")
-	text = strings.ReplaceAll(text, "&gt;", ">")
-	text = strings.ReplaceAll(text, "&quot;", "\"")
-	text = strings.ReplaceAll(text, "&#39;", "'")
-	text = strings.ReplaceAll(text, "&nbsp;", " ")
-
-	// Collapse whitespace
-	text = wsRegex.ReplaceAllString(text, " ")
-	text = strings.TrimSpace(text)
+	text := content.PlainText(htmlContent)
 
 	// Truncate to maxLen runes (not bytes) to avoid splitting multi-byte
 	// UTF-8 characters (e.g. Greek, Hebrew, Aramaic content).
@@ -263,15 +911,19 @@ func postToResponse(post *content.Post, includeContent bool, includeSearchConten
 		Tags:        post.Meta.Tags,
 		Series:      post.Meta.Series,
 		ReadingTime: post.Meta.ReadingTime(),
+		Draft:       post.Meta.Draft,
+	}
+	if post.Meta.Revised() {
+		resp.UpdatedDate = post.Meta.LastModified().Format("2006-01-02")
 	}
 
-	// Include author if present
+	// Include authors if present
 	if post.Meta.Author.Name != "" {
-		resp.Author = &AuthorResponse{
-			Name:   post.Meta.Author.Name,
-			Avatar: post.Meta.Author.Avatar,
-			Bio:    post.Meta.Author.Bio,
-		}
+		author := authorToResponse(post.Meta.Author)
+		resp.Author = &author
+	}
+	for _, a := range post.Meta.Authors {
+		resp.Authors = append(resp.Authors, authorToResponse(a))
 	}
 
 	if includeSearchContent {
@@ -282,6 +934,59 @@ func postToResponse(post *content.Post, includeContent bool, includeSearchConten
 	if includeContent {
 		// Wrap the rendered markdown with the Article template
 		resp.HTMLContent = views.RenderToString(views.Article(post, post.HTMLContent))
+		resp.TOC = tocToResponse(post.TOC)
+		for _, entry := range post.Meta.Changelog {
+			resp.Changelog = append(resp.Changelog, ChangelogEntry{
+				Date: entry.Date.Format("2006-01-02"),
+				Note: entry.Note,
+			})
+		}
 	}
 	return resp
 }
+
+func authorToResponse(a content.Author) AuthorResponse {
+	return AuthorResponse{
+		ID:     a.ID,
+		Name:   a.Name,
+		Avatar: a.Avatar,
+		Bio:    a.Bio,
+		URL:    a.URL,
+	}
+}
+
+// seriesNav returns the position of the post with the given slug in series
+// and links to its neighbours, or nil if the post isn't in it.
+func seriesNav(series *content.Series, slug string) *SeriesNav {
+	part := series.Part(slug)
+	if part == 0 {
+		return nil
+	}
+
+	nav := &SeriesNav{Title: series.Info.Title, Part: part, Total: len(series.Posts)}
+	if part > 1 {
+		prev := series.Posts[part-2]
+		nav.Prev = &PostLink{Slug: prev.Meta.Slug, Title: prev.Meta.Title}
+	}
+	if part < len(series.Posts) {
+		next := series.Posts[part]
+		nav.Next = &PostLink{Slug: next.Meta.Slug, Title: next.Meta.Title}
+	}
+	return nav
+}
+
+func tocToResponse(headings []renderer.Heading) []TOCEntry {
+	if len(headings) == 0 {
+		return nil
+	}
+	entries := make([]TOCEntry, len(headings))
+	for i, h := range headings {
+		entries[i] = TOCEntry{
+			Level:    h.Level,
+			ID:       h.ID,
+			Text:     h.Text,
+			Children: tocToResponse(h.Children),
+		}
+	}
+	return entries
+}
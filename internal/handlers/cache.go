@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"therefore/internal/content"
+
+	"github.com/labstack/echo/v5"
+)
+
+// Cache-Control values for the kinds of response the server sends.
+const (
+	// cacheContent lets API responses, feeds and the sitemap be reused for
+	// a minute before they're revalidated against their ETag.
+	cacheContent = "public, max-age=60, must-revalidate"
+	// cachePage makes browsers revalidate HTML on every navigation, so a
+	// new deploy's asset hashes are picked up at once.
+	cachePage = "public, no-cache"
+	// cacheAsset is for files whose URL stays the same when they change,
+	// such as bundle images and root static files.
+	cacheAsset = "public, max-age=86400"
+	// cacheImmutable is for files with a content hash in their name.
+	cacheImmutable = "public, max-age=31536000, immutable"
+)
+
+// etag formats a content hash as a weak entity tag. Tags are weak because
+// the compression middleware serves several encodings of each response.
+func etag(hash string) string {
+	return `W/"` + hash + `"`
+}
+
+// hashBytes returns a content hash of data for use with etag.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// revalidate sets the ETag, Last-Modified and Cache-Control headers for a
+// response at the given version, and answers 304 Not Modified if the
+// request's conditional headers show the client's copy is current.
+// Reports whether the response has been written.
+func revalidate(c *echo.Context, version content.Version, cacheControl string) (bool, error) {
+	header := c.Response().Header()
+	header.Set("Cache-Control", cacheControl)
+	if version.ETag != "" {
+		header.Set("ETag", etag(version.ETag))
+	}
+	if !version.Modified.IsZero() {
+		header.Set("Last-Modified", version.Modified.UTC().Format(http.TimeFormat))
+	}
+
+	if !notModified(c.Request(), version) {
+		return false, nil
+	}
+	return true, c.NoContent(http.StatusNotModified)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when there is
+// no If-None-Match, against version.
+func notModified(req *http.Request, version content.Version) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		if version.ETag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == `"`+version.ETag+`"` {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" && !version.Modified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !version.Modified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// revalidateListing calls revalidate with the version of the content as a
+// whole, for responses derived from more than one post.
+func revalidateListing(c *echo.Context, store content.ContentStore) (bool, error) {
+	version, err := store.Version(c.Request().Context())
+	if err != nil {
+		return true, echo.NewHTTPError(http.StatusInternalServerError, "failed to get content version")
+	}
+	return revalidate(c, version, cacheContent)
+}
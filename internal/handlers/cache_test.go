@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"therefore/internal/content"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	version := content.Version{ETag: "abc", Modified: modified}
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    bool
+	}{
+		{"no conditions", http.MethodGet, nil, false},
+		{"matching weak etag", http.MethodGet, map[string]string{"If-None-Match": `W/"abc"`}, true},
+		{"matching strong etag", http.MethodGet, map[string]string{"If-None-Match": `"abc"`}, true},
+		{"etag in list", http.MethodGet, map[string]string{"If-None-Match": `"x", W/"abc"`}, true},
+		{"wildcard", http.MethodHead, map[string]string{"If-None-Match": "*"}, true},
+		{"stale etag", http.MethodGet, map[string]string{"If-None-Match": `W/"old"`}, false},
+		{"not modified since", http.MethodGet, map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, true},
+		{"modified since", http.MethodGet, map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, false},
+		{"bad date", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, false},
+		{
+			"etag takes precedence",
+			http.MethodGet,
+			map[string]string{"If-None-Match": `W/"old"`, "If-Modified-Since": modified.Format(http.TimeFormat)},
+			false,
+		},
+		{"unsafe method", http.MethodPost, map[string]string{"If-None-Match": `W/"abc"`}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if got := notModified(req, version); got != tt.want {
+				t.Errorf("notModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_ConditionalRequests(t *testing.T) {
+	store := newMockStore()
+	store.version = content.Version{ETag: "v1", Modified: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)}
+	store.posts["test-post"] = &content.Post{
+		Meta: content.PostMeta{Title: "Test Post", Slug: "test-post"},
+		Hash: "p1",
+	}
+	handler := NewAPIHandler(store)
+	e := echo.New()
+
+	tests := []struct {
+		name        string
+		path        string
+		handler     echo.HandlerFunc
+		ifNoneMatch string
+		wantStatus  int
+		wantETag    string
+	}{
+		{"list fresh", "/api/posts", handler.ListPosts, "", http.StatusOK, `W/"v1"`},
+		{"list cached", "/api/posts", handler.ListPosts, `W/"v1"`, http.StatusNotModified, `W/"v1"`},
+		{"list stale", "/api/posts", handler.ListPosts, `W/"v0"`, http.StatusOK, `W/"v1"`},
+		{"tags cached", "/api/tags", handler.ListTags, `W/"v1"`, http.StatusNotModified, `W/"v1"`},
+		{"post fresh", "/api/posts/test-post", handler.GetPost, "", http.StatusOK, `W/"p1"`},
+		{"post cached", "/api/posts/test-post", handler.GetPost, `W/"p1"`, http.StatusNotModified, `W/"p1"`},
+		{"post with listing etag", "/api/posts/test-post", handler.GetPost, `W/"v1"`, http.StatusOK, `W/"p1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPathValues(echo.PathValues{{Name: "slug", Value: "test-post"}})
+
+			if err := tt.handler(c); err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != cacheContent {
+				t.Errorf("Cache-Control = %q, want %q", got, cacheContent)
+			}
+			if tt.wantStatus == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 response has a body: %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSPAHandler_Caching(t *testing.T) {
+	distFS := fstest.MapFS{
+		"index.html":         {Data: []byte("<html>shell</html>")},
+		"posts/hello.html":   {Data: []byte("<html>hello</html>")},
+		"assets/app-1a2b.js": {Data: []byte("console.log(1)")},
+		"favicon.svg":        {Data: []byte("<svg></svg>")},
+	}
+	h, err := NewSPAHandler(distFS)
+	if err != nil {
+		t.Fatalf("NewSPAHandler() error = %v", err)
+	}
+	e := echo.New()
+
+	serve := func(handler echo.HandlerFunc, path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		if err := handler(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+		return rec
+	}
+
+	tests := []struct {
+		name         string
+		handler      echo.HandlerFunc
+		path         string
+		cacheControl string
+	}{
+		{"ssg page", h.Handler(), "/posts/hello", cachePage},
+		{"spa fallback", h.Handler(), "/posts/unknown/deep", cachePage},
+		{"root static file", h.Handler(), "/favicon.svg", cacheAsset},
+		{"hashed asset", h.ServeAssets(), "/assets/app-1a2b.js", cacheImmutable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.handler, tt.path, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.cacheControl)
+			}
+			tag := rec.Header().Get("ETag")
+			if tag == "" {
+				t.Fatal("missing ETag")
+			}
+
+			rec = serve(tt.handler, tt.path, tag)
+			if rec.Code != http.StatusNotModified {
+				t.Errorf("revalidation status = %d, want 304", rec.Code)
+			}
+		})
+	}
+
+	t.Run("preview", func(t *testing.T) {
+		for _, path := range []string{"/posts/hello", "/posts/draft"} {
+			tag := serve(h.Handler(), path, "").Header().Get("ETag")
+			rec := serve(h.Handler(), path+"?preview=token", tag)
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s: status = %d, want 200 without revalidation", path, rec.Code)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != "private, no-store" {
+				t.Errorf("%s: Cache-Control = %q, want private, no-store", path, got)
+			}
+			if got := rec.Header().Get("ETag"); got != "" {
+				t.Errorf("%s: ETag = %q, want none", path, got)
+			}
+			if got := rec.Header().Get("X-Robots-Tag"); got != "noindex" {
+				t.Errorf("%s: X-Robots-Tag = %q, want noindex", path, got)
+			}
+		}
+	})
+
+	t.Run("missing asset", func(t *testing.T) {
+		rec := serve(h.ServeAssets(), "/assets/missing.js", "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != "" {
+			t.Errorf("Cache-Control = %q, want none on 404", got)
+		}
+	})
+}
+
+func TestSPAHandler_RenderPages(t *testing.T) {
+	distFS := fstest.MapFS{
+		"index.html":        {Data: []byte(`<html><script type="module" src="/assets/app-1a2b.js"></script>shell</html>`)},
+		"posts/hello.html":  {Data: []byte("<html>stale ssg</html>")},
+		"archive/2020.html": {Data: []byte("<html>ssg archive</html>")},
+	}
+	h, err := NewSPAHandler(distFS)
+	if err != nil {
+		t.Fatalf("NewSPAHandler() error = %v", err)
+	}
+
+	store := newMockStore()
+	store.version = content.Version{ETag: "v1"}
+	store.posts["hello"] = &content.Post{
+		Meta:        content.PostMeta{Title: "Hello World", Slug: "hello", Tags: []string{"greetings"}, PublishDate: time.Now()},
+		HTMLContent: "<p>Hi there</p>",
+	}
+	h.RenderPages(store, "https://example.com")
+	e := echo.New()
+
+	serve := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		if err := h.Handler()(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+		return rec
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"post over stale ssg file", "/posts/hello", "Hello World"},
+		{"tag", "/tags/greetings", "Posts tagged"},
+		{"listing", "/posts", "Hello World"},
+		{"about", "/about", "About"},
+		{"archive", "/archive", "Archive"},
+		{"archive year", "/archive/" + strconv.Itoa(time.Now().Year()), "Hello World"},
+		{"ssg archive year", "/archive/2020", "ssg archive"},
+		{"unknown post", "/posts/missing", "shell"},
+		{"unknown tag", "/tags/nope", "shell"},
+		{"unknown route", "/nowhere", "shell"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.path, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.want)
+			}
+		})
+	}
+
+	rec := serve("/posts/hello", "")
+	if !strings.Contains(rec.Body.String(), `src="/assets/app-1a2b.js"`) {
+		t.Error("rendered page doesn't load the app entry from index.html")
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != cachePage {
+		t.Errorf("Cache-Control = %q, want %q", cc, cachePage)
+	}
+	tag := rec.Header().Get("ETag")
+	if rec := serve("/posts/hello", tag); rec.Code != http.StatusNotModified {
+		t.Errorf("revalidation status = %d, want 304", rec.Code)
+	}
+	if rec := serve("/posts/hello?preview=token", tag); rec.Code != http.StatusOK ||
+		rec.Header().Get("Cache-Control") != "private, no-store" {
+		t.Errorf("preview: status = %d, Cache-Control = %q, want 200 and private, no-store",
+			rec.Code, rec.Header().Get("Cache-Control"))
+	}
+
+	// A new content version re-renders cached pages
+	store.posts["hello"].Meta.Title = "Hello Again"
+	store.version = content.Version{ETag: "v2"}
+	rec = serve("/posts/hello", tag)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Hello Again") {
+		t.Errorf("after content change: status = %d, body missing new title", rec.Code)
+	}
+}
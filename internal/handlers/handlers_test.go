@@ -4,27 +4,42 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"slices"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"therefore/internal/content"
+	"therefore/internal/images"
+	"therefore/internal/preview"
+	"therefore/internal/renderer"
 
 	"github.com/labstack/echo/v5"
+	"github.com/spf13/afero"
 )
 
 // mockStore implements content.ContentStore for testing.
 type mockStore struct {
-	posts  map[string]*content.Post
-	tags   []content.TagCount
-	series []content.SeriesCount
+	posts   map[string]*content.Post
+	drafts  map[string]*content.Post
+	related map[string][]*content.Post
+	authors []content.AuthorCount
+	tags    []content.TagCount
+	series  []content.SeriesCount
+	version content.Version
+	history map[string][]content.Revision
 }
 
 func newMockStore() *mockStore {
 	return &mockStore{
-		posts: make(map[string]*content.Post),
+		posts:   make(map[string]*content.Post),
+		drafts:  make(map[string]*content.Post),
+		related: make(map[string][]*content.Post),
 	}
 }
 
@@ -36,6 +51,21 @@ func (m *mockStore) GetPost(_ context.Context, slug string) (*content.Post, erro
 	return post, nil
 }
 
+func (m *mockStore) GetPreview(_ context.Context, slug string) (*content.Post, error) {
+	post, ok := m.drafts[slug]
+	if !ok {
+		return nil, content.ErrPostNotFound
+	}
+	return post, nil
+}
+
+func (m *mockStore) GetRelated(_ context.Context, slug string) ([]*content.Post, error) {
+	if _, ok := m.posts[slug]; !ok {
+		return nil, content.ErrPostNotFound
+	}
+	return m.related[slug], nil
+}
+
 func (m *mockStore) ListPosts(_ context.Context, opts content.ListOptions) ([]*content.Post, int, error) {
 	var posts []*content.Post
 	for _, post := range m.posts {
@@ -51,6 +81,17 @@ func (m *mockStore) ListPosts(_ context.Context, opts content.ListOptions) ([]*c
 				continue
 			}
 		}
+		// Filter by Author
+		if opts.Author != "" && !post.Meta.HasAuthor(opts.Author) {
+			continue
+		}
+		// Filter by archive period
+		if opts.Year != 0 && post.Meta.PublishDate.Year() != opts.Year {
+			continue
+		}
+		if opts.Month != 0 && post.Meta.PublishDate.Month() != opts.Month {
+			continue
+		}
 		posts = append(posts, post)
 	}
 	return posts, len(posts), nil
@@ -64,10 +105,107 @@ func (m *mockStore) GetSeries(_ context.Context) ([]content.SeriesCount, error)
 	return m.series, nil
 }
 
+// GetArchive counts the posts by year and month, latest first.
+func (m *mockStore) GetArchive(_ context.Context) ([]content.ArchiveYear, error) {
+	counts := make(map[int]map[time.Month]int)
+	for _, post := range m.posts {
+		year, month := post.Meta.PublishDate.Year(), post.Meta.PublishDate.Month()
+		if counts[year] == nil {
+			counts[year] = make(map[time.Month]int)
+		}
+		counts[year][month]++
+	}
+	archive := []content.ArchiveYear{}
+	for year, months := range counts {
+		y := content.ArchiveYear{Year: year}
+		for month := time.December; month >= time.January; month-- {
+			if count := months[month]; count > 0 {
+				y.Count += count
+				y.Months = append(y.Months, content.ArchiveMonth{Month: month, Count: count})
+			}
+		}
+		archive = append(archive, y)
+	}
+	sort.Slice(archive, func(i, j int) bool { return archive[i].Year > archive[j].Year })
+	return archive, nil
+}
+
+func (m *mockStore) GetAuthors(_ context.Context) ([]content.AuthorCount, error) {
+	return m.authors, nil
+}
+
+func (m *mockStore) GetAuthor(_ context.Context, id string) (content.Author, error) {
+	for _, a := range m.authors {
+		if a.Author.ID == id {
+			return a.Author, nil
+		}
+	}
+	return content.Author{}, content.ErrAuthorNotFound
+}
+
+// GetSeriesByName orders a series' posts by seriesOrder alone.
+func (m *mockStore) GetSeriesByName(_ context.Context, name string) (*content.Series, error) {
+	series := &content.Series{Name: name, Info: content.SeriesInfo{Title: name}}
+	for _, post := range m.posts {
+		if post.Meta.Series == name {
+			series.Posts = append(series.Posts, post)
+		}
+	}
+	if len(series.Posts) == 0 {
+		return nil, content.ErrSeriesNotFound
+	}
+	sort.Slice(series.Posts, func(i, j int) bool {
+		return series.Posts[i].Meta.SeriesOrder < series.Posts[j].Meta.SeriesOrder
+	})
+	return series, nil
+}
+
+func (m *mockStore) Search(_ context.Context, query string, _ content.ListOptions) ([]content.SearchResult, int, error) {
+	var results []content.SearchResult
+	for _, post := range m.posts {
+		if strings.Contains(strings.ToLower(post.Meta.Title), strings.ToLower(query)) {
+			results = append(results, content.SearchResult{Post: post, Score: 1, Snippet: "<mark>" + query + "</mark>"})
+		}
+	}
+	return results, len(results), nil
+}
+
+func (m *mockStore) GetHistory(_ context.Context, slug string) ([]content.Revision, error) {
+	if _, ok := m.posts[slug]; !ok {
+		return nil, content.ErrPostNotFound
+	}
+	return m.history[slug], nil
+}
+
 func (m *mockStore) GetPostAsset(_ context.Context, _, _ string) ([]byte, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (m *mockStore) GetPostImage(_ context.Context, slug, filename string) (*images.Image, error) {
+	post, ok := m.posts[slug]
+	if !ok {
+		return nil, content.ErrPostNotFound
+	}
+	img, ok := post.Images[filename]
+	if !ok {
+		return nil, errors.New("image not found")
+	}
+	return img, nil
+}
+
+func (m *mockStore) Version(_ context.Context) (content.Version, error) {
+	return m.version, nil
+}
+
+// PostVersion versions each post by its Hash alone.
+func (m *mockStore) PostVersion(_ context.Context, slug string) (content.Version, error) {
+	post, ok := m.posts[slug]
+	if !ok {
+		return content.Version{}, content.ErrPostNotFound
+	}
+	return content.Version{ETag: post.Hash, Modified: m.version.Modified}, nil
+}
+
 func TestAPIHandler_GetPost(t *testing.T) {
 	store := newMockStore()
 	store.posts["test-post"] = &content.Post{
@@ -76,10 +214,27 @@ func TestAPIHandler_GetPost(t *testing.T) {
 			Slug:        "test-post",
 			Summary:     "A test post",
 			PublishDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
-			Tags:        []string{"philosophy"},
+			UpdatedDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			Changelog: []content.ChangelogEntry{
+				{Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Note: "Clarified the argument."},
+			},
+			Tags: []string{"philosophy"},
 		},
 		HTMLContent: "<p>Content</p>",
+		TOC: []renderer.Heading{
+			{Level: 2, ID: "intro", Text: "Intro", Children: []renderer.Heading{
+				{Level: 3, ID: "detail", Text: "Detail"},
+			}},
+		},
 	}
+	store.related["test-post"] = []*content.Post{{
+		Meta: content.PostMeta{
+			Title:       "Related Post",
+			Slug:        "related-post",
+			PublishDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		},
+		HTMLContent: "<p>Related content</p>",
+	}}
 
 	handler := NewAPIHandler(store)
 	e := echo.New()
@@ -111,6 +266,28 @@ func TestAPIHandler_GetPost(t *testing.T) {
 		if resp.HTMLContent == "" {
 			t.Error("HTMLContent should be included for single post")
 		}
+		if len(resp.TOC) != 1 || resp.TOC[0].ID != "intro" ||
+			len(resp.TOC[0].Children) != 1 || resp.TOC[0].Children[0].Text != "Detail" {
+			t.Errorf("TOC = %+v, want intro > detail", resp.TOC)
+		}
+		if len(resp.Related) != 1 || resp.Related[0].Slug != "related-post" {
+			t.Fatalf("Related = %+v, want [related-post]", resp.Related)
+		}
+		if resp.Related[0].HTMLContent != "" {
+			t.Error("HTMLContent should not be included for related posts")
+		}
+		if resp.Related[0].UpdatedDate != "" {
+			t.Errorf("Related[0].UpdatedDate = %q, want empty for unrevised post", resp.Related[0].UpdatedDate)
+		}
+		if resp.UpdatedDate != "2024-02-01" {
+			t.Errorf("UpdatedDate = %q, want %q", resp.UpdatedDate, "2024-02-01")
+		}
+		if len(resp.Changelog) != 1 || resp.Changelog[0].Date != "2024-02-01" || resp.Changelog[0].Note != "Clarified the argument." {
+			t.Errorf("Changelog = %+v, want one dated note", resp.Changelog)
+		}
+		if !strings.Contains(resp.HTMLContent, "Revised") {
+			t.Error("HTMLContent should include the revised notice")
+		}
 	})
 
 	t.Run("not found", func(t *testing.T) {
@@ -133,6 +310,82 @@ func TestAPIHandler_GetPost(t *testing.T) {
 	})
 }
 
+func TestAPIHandler_GetPostHistory(t *testing.T) {
+	store := newMockStore()
+	store.posts["revised"] = &content.Post{Meta: content.PostMeta{Title: "Revised", Slug: "revised"}}
+	store.posts["untracked"] = &content.Post{Meta: content.PostMeta{Title: "Untracked", Slug: "untracked"}}
+	store.history = map[string][]content.Revision{
+		"revised": {
+			{ID: "b2c3d4e", Date: time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC), Author: "Alice", Message: "Clarify the argument"},
+			{ID: "a1b2c3d", Date: time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC), Author: "Alice", Message: "Add post"},
+		},
+	}
+
+	handler := NewAPIHandler(store)
+	e := echo.New()
+
+	tests := []struct {
+		name     string
+		slug     string
+		wantCode int
+		wantIDs  []string
+	}{
+		{"revised", "revised", http.StatusOK, []string{"b2c3d4e", "a1b2c3d"}},
+		{"no history", "untracked", http.StatusOK, []string{}},
+		{"not found", "nonexistent", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/posts/"+tt.slug+"/history", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPathValues(echo.PathValues{{Name: "slug", Value: tt.slug}})
+
+			err := handler.GetPostHistory(c)
+			if tt.wantCode != http.StatusOK {
+				var httpErr *echo.HTTPError
+				if !errors.As(err, &httpErr) || httpErr.Code != tt.wantCode {
+					t.Errorf("GetPostHistory() error = %v, want %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPostHistory() error = %v", err)
+			}
+
+			// Decode loosely so a null revisions list is caught
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(rec.Body.Bytes(), &raw); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if string(raw["revisions"]) == "null" {
+				t.Fatal("revisions = null, want a list")
+			}
+
+			var resp HistoryResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Slug != tt.slug {
+				t.Errorf("Slug = %q, want %q", resp.Slug, tt.slug)
+			}
+			var ids []string
+			for _, r := range resp.Revisions {
+				ids = append(ids, r.ID)
+			}
+			if len(ids) != len(tt.wantIDs) || (len(ids) > 0 && ids[0] != tt.wantIDs[0]) {
+				t.Errorf("revision IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if len(resp.Revisions) > 0 && resp.Revisions[0].Date != "2024-02-01T09:30:00Z" {
+				t.Errorf("Date = %q, want RFC 3339 timestamp", resp.Revisions[0].Date)
+			}
+			if rec.Header().Get("ETag") == "" {
+				t.Error("missing ETag")
+			}
+		})
+	}
+}
+
 func TestAPIHandler_ListPosts(t *testing.T) {
 	store := newMockStore()
 	store.posts["post1"] = &content.Post{
@@ -226,6 +479,261 @@ func TestAPIHandler_ListPosts(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/posts?limit=all", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.ListPosts(c)
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusBadRequest {
+			t.Errorf("ListPosts() error = %v, want 400", err)
+		}
+	})
+}
+
+// plainRenderer implements content.Renderer by wrapping markdown in a
+// paragraph, for tests that need a real store.
+type plainRenderer struct{}
+
+func (plainRenderer) RenderDocument(raw string, _ *renderer.RenderContext) (renderer.Document, error) {
+	return renderer.Document{HTML: "<p>" + raw + "</p>"}, nil
+}
+
+func TestAPIHandler_ListPostsCursor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for i, slug := range []string{"a", "b", "c", "d", "e"} {
+		date := time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+		_ = afero.WriteFile(fs, slug+".md", []byte("---\ntitle: "+slug+"\nslug: "+slug+
+			"\npublishDate: "+date+"\n---\nContent."), 0644)
+	}
+	store, err := content.NewEmbeddedStore(fs, plainRenderer{})
+	if err != nil {
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+
+	handler := NewAPIHandler(store)
+	e := echo.New()
+	list := func(t *testing.T, query string) (ListPostsResponse, error) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/posts?"+query, nil)
+		rec := httptest.NewRecorder()
+		var resp ListPostsResponse
+		if err := handler.ListPosts(e.NewContext(req, rec)); err != nil {
+			return resp, err
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return resp, nil
+	}
+	page := func(t *testing.T, query, want string, wantPrev, wantNext bool) ListPostsResponse {
+		t.Helper()
+		resp, err := list(t, query)
+		if err != nil {
+			t.Fatalf("ListPosts(%s) error = %v", query, err)
+		}
+		var got string
+		for _, post := range resp.Posts {
+			got += post.Slug
+		}
+		if got != want || resp.Total != 5 {
+			t.Errorf("ListPosts(%s) = %s (total %d), want %s (total 5)", query, got, resp.Total, want)
+		}
+		if (resp.PrevCursor != "") != wantPrev || (resp.NextCursor != "") != wantNext {
+			t.Errorf("ListPosts(%s) prevCursor = %q, nextCursor = %q, want prev %v, next %v",
+				query, resp.PrevCursor, resp.NextCursor, wantPrev, wantNext)
+		}
+		return resp
+	}
+
+	t.Run("walk", func(t *testing.T) {
+		first := page(t, "limit=2", "ed", false, true)
+		second := page(t, "limit=2&cursor="+first.NextCursor, "cb", true, true)
+		last := page(t, "limit=2&cursor="+second.NextCursor, "a", true, false)
+		back := page(t, "limit=2&cursor="+last.PrevCursor, "cb", true, true)
+		page(t, "limit=2&cursor="+back.PrevCursor, "ed", false, true)
+	})
+
+	t.Run("offset", func(t *testing.T) {
+		resp := page(t, "limit=2&offset=2", "cb", true, true)
+		page(t, "limit=2&cursor="+resp.PrevCursor, "ed", false, true)
+		page(t, "offset=3", "ba", true, false)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		first := page(t, "limit=2", "ed", false, true)
+		for _, query := range []string{
+			"cursor=garbage",
+			"cursor=" + first.NextCursor + "&offset=2",
+			"cursor=" + first.NextCursor + "&sortBy=title",
+		} {
+			_, err := list(t, query)
+			var httpErr *echo.HTTPError
+			if !errors.As(err, &httpErr) || httpErr.Code != http.StatusBadRequest {
+				t.Errorf("ListPosts(%s) error = %v, want 400", query, err)
+			}
+		}
+	})
+}
+
+func TestAPIHandler_ListingPagesPastMaxPageSize(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "config.yaml", []byte("authors:\n  alice:\n    name: Alice\n"), 0644)
+	const count = maxPageSize + 20
+	for i := range count {
+		slug := fmt.Sprintf("post-%03d", i)
+		date := time.Date(2024, 1, 1, i, 0, 0, 0, time.UTC).Format(time.RFC3339)
+		_ = afero.WriteFile(fs, slug+".md", []byte("---\ntitle: "+slug+"\nslug: "+slug+
+			"\npublishDate: "+date+"\nauthors: [alice]\n---\nContent."), 0644)
+	}
+	store, err := content.NewEmbeddedStore(fs, plainRenderer{})
+	if err != nil {
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+
+	handler := NewAPIHandler(store)
+	e := echo.New()
+
+	// walk follows nextCursor from the first page to the last, returning
+	// the slugs of every page
+	walk := func(t *testing.T, path string, params echo.PathValues, get func(*echo.Context) error) []string {
+		t.Helper()
+		var slugs []string
+		cursor := ""
+		for pages := 0; pages < 10; pages++ {
+			req := httptest.NewRequest(http.MethodGet, path+"?cursor="+cursor, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPathValues(params)
+			if err := get(c); err != nil {
+				t.Fatalf("GET %s error = %v", req.URL, err)
+			}
+			var resp struct {
+				Posts      []PostResponse `json:"posts"`
+				Total      int            `json:"total"`
+				NextCursor string         `json:"nextCursor"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Total != count {
+				t.Errorf("total = %d, want %d", resp.Total, count)
+			}
+			for _, post := range resp.Posts {
+				slugs = append(slugs, post.Slug)
+			}
+			if resp.NextCursor == "" {
+				return slugs
+			}
+			cursor = resp.NextCursor
+		}
+		t.Fatalf("GET %s never ran out of pages", path)
+		return nil
+	}
+	check := func(t *testing.T, slugs []string) {
+		t.Helper()
+		if len(slugs) != count {
+			t.Fatalf("got %d posts, want %d", len(slugs), count)
+		}
+		for i, slug := range slugs {
+			if want := fmt.Sprintf("post-%03d", count-1-i); slug != want {
+				t.Fatalf("posts[%d] = %s, want %s", i, slug, want)
+			}
+		}
+	}
+
+	t.Run("author", func(t *testing.T) {
+		check(t, walk(t, "/api/authors/alice",
+			echo.PathValues{{Name: "id", Value: "alice"}}, handler.GetAuthor))
+	})
+
+	t.Run("archive period", func(t *testing.T) {
+		check(t, walk(t, "/api/archive/2024/1",
+			echo.PathValues{{Name: "year", Value: "2024"}, {Name: "month", Value: "1"}}, handler.GetArchivePeriod))
+	})
+}
+
+func TestParseListOptions(t *testing.T) {
+	e := echo.New()
+	parse := func(query string) (content.ListOptions, error) {
+		req := httptest.NewRequest(http.MethodGet, "/api/posts?"+query, nil)
+		return parseListOptions(e.NewContext(req, httptest.NewRecorder()))
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		opts, err := parse("tag=a&tag=b&tagMode=any&excludeTag=c&excludeTag=d&author=alice" +
+			"&from=2024-01-01&to=2024-06-30&minReadingTime=2&maxReadingTime=10" +
+			"&limit=5&offset=10&sortBy=title&sortOrder=asc")
+		if err != nil {
+			t.Fatalf("parseListOptions() error = %v", err)
+		}
+		want := content.ListOptions{
+			Tags:           []string{"a", "b"},
+			TagMode:        content.TagMatchAny,
+			ExcludeTags:    []string{"c", "d"},
+			Author:         "alice",
+			From:           time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:             time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+			MinReadingTime: 2,
+			MaxReadingTime: 10,
+			Limit:          5,
+			Offset:         10,
+			SortBy:         content.SortByTitle,
+			SortOrder:      content.SortAsc,
+		}
+		if !reflect.DeepEqual(opts, want) {
+			t.Errorf("parseListOptions() = %+v, want %+v", opts, want)
+		}
+
+		opts, err = parse("tag=a&from=2024-03-01T09:30:00%2B01:00")
+		if err != nil {
+			t.Fatalf("parseListOptions() error = %v", err)
+		}
+		if opts.Limit != maxPageSize {
+			t.Errorf("default Limit = %d, want %d", opts.Limit, maxPageSize)
+		}
+		if opts, _ := parse("limit=1000000"); opts.Limit != maxPageSize {
+			t.Errorf("Limit = %d, want it capped at %d", opts.Limit, maxPageSize)
+		}
+		if opts.Tag != "a" || opts.Tags != nil {
+			t.Errorf("single tag: Tag = %q, Tags = %v, want Tag a", opts.Tag, opts.Tags)
+		}
+		if want := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC); !opts.From.Equal(want) {
+			t.Errorf("From = %v, want %v", opts.From, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  string
+		}{
+			{"limit=ten", `invalid limit "ten"`},
+			{"limit=-1", `invalid limit "-1"`},
+			{"offset=x", `invalid offset "x"`},
+			{"tagMode=some", `invalid tagMode "some"`},
+			{"from=yesterday", `invalid from "yesterday"`},
+			{"to=2024-13-01", `invalid to "2024-13-01"`},
+			{"from=2024-06-01&to=2024-01-01", "from must not be after to"},
+			{"minReadingTime=1.5", `invalid minReadingTime "1.5"`},
+			{"minReadingTime=10&maxReadingTime=5", "minReadingTime must not be more than maxReadingTime"},
+			{"sortBy=author", `invalid sortBy "author"`},
+			{"sortOrder=up", `invalid sortOrder "up"`},
+		}
+		for _, tt := range tests {
+			_, err := parse(tt.query)
+			var httpErr *echo.HTTPError
+			if !errors.As(err, &httpErr) || httpErr.Code != http.StatusBadRequest {
+				t.Errorf("parseListOptions(%s) error = %v, want 400", tt.query, err)
+				continue
+			}
+			if !strings.HasPrefix(httpErr.Message, tt.want) {
+				t.Errorf("parseListOptions(%s) message = %q, want prefix %q", tt.query, httpErr.Message, tt.want)
+			}
+		}
+	})
 }
 
 func TestAPIHandler_ListTags(t *testing.T) {
@@ -292,3 +800,464 @@ func TestAPIHandler_ListSeries(t *testing.T) {
 		t.Errorf("resp[0].TopTags = %v, want [philosophy, ethics]", resp[0].TopTags)
 	}
 }
+
+func TestAPIHandler_GetSeries(t *testing.T) {
+	store := newMockStore()
+	for i, slug := range []string{"first", "second", "third"} {
+		store.posts[slug] = &content.Post{
+			Meta: content.PostMeta{
+				Title:       slug,
+				Slug:        slug,
+				PublishDate: time.Now(),
+				Series:      "Foundations",
+				SeriesOrder: i + 1,
+			},
+		}
+	}
+
+	handler := NewAPIHandler(store)
+	e := echo.New()
+
+	t.Run("found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/series/Foundations", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "name", Value: "Foundations"}})
+
+		if err := handler.GetSeries(c); err != nil {
+			t.Fatalf("GetSeries() error = %v", err)
+		}
+
+		var resp SeriesDetailResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if resp.Series != "Foundations" || resp.Title != "Foundations" {
+			t.Errorf("resp = %+v, want series Foundations", resp)
+		}
+		if len(resp.Posts) != 3 {
+			t.Fatalf("len(Posts) = %d, want 3", len(resp.Posts))
+		}
+		for i, want := range []string{"first", "second", "third"} {
+			if resp.Posts[i].Slug != want || resp.Posts[i].Part != i+1 {
+				t.Errorf("Posts[%d] = {%s, part %d}, want {%s, part %d}",
+					i, resp.Posts[i].Slug, resp.Posts[i].Part, want, i+1)
+			}
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/series/Missing", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "name", Value: "Missing"}})
+
+		err := handler.GetSeries(c)
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusNotFound {
+			t.Errorf("GetSeries() error = %v, want 404", err)
+		}
+	})
+
+	t.Run("post navigation", func(t *testing.T) {
+		tests := []struct {
+			slug       string
+			part       int
+			prev, next string
+		}{
+			{slug: "first", part: 1, next: "second"},
+			{slug: "second", part: 2, prev: "first", next: "third"},
+			{slug: "third", part: 3, prev: "second"},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodGet, "/api/posts/"+tt.slug, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPathValues(echo.PathValues{{Name: "slug", Value: tt.slug}})
+
+			if err := handler.GetPost(c); err != nil {
+				t.Fatalf("GetPost() error = %v", err)
+			}
+			var resp PostResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			nav := resp.SeriesNav
+			if nav == nil {
+				t.Fatalf("%s: SeriesNav is nil", tt.slug)
+			}
+			var prev, next string
+			if nav.Prev != nil {
+				prev = nav.Prev.Slug
+			}
+			if nav.Next != nil {
+				next = nav.Next.Slug
+			}
+			if nav.Part != tt.part || nav.Total != 3 || prev != tt.prev || next != tt.next {
+				t.Errorf("%s: SeriesNav = {part %d of %d, prev %q, next %q}, want {part %d of 3, prev %q, next %q}",
+					tt.slug, nav.Part, nav.Total, prev, next, tt.part, tt.prev, tt.next)
+			}
+			if nav.Title != "Foundations" {
+				t.Errorf("%s: SeriesNav.Title = %q, want %q", tt.slug, nav.Title, "Foundations")
+			}
+		}
+	})
+}
+
+func TestAPIHandler_Authors(t *testing.T) {
+	alice := content.Author{ID: "alice", Name: "Alice", Bio: "Editor."}
+	bob := content.Author{ID: "bob", Name: "Bob"}
+
+	store := newMockStore()
+	store.authors = []content.AuthorCount{{Author: alice, Count: 2}, {Author: bob, Count: 1}}
+	store.posts["co-written"] = &content.Post{
+		Meta: content.PostMeta{
+			Title:       "Co-written",
+			Slug:        "co-written",
+			PublishDate: time.Now(),
+			Author:      bob,
+			Authors:     []content.Author{bob, alice},
+		},
+	}
+	store.posts["solo"] = &content.Post{
+		Meta: content.PostMeta{
+			Title:       "Solo",
+			Slug:        "solo",
+			PublishDate: time.Now(),
+			Author:      alice,
+			Authors:     []content.Author{alice},
+		},
+	}
+
+	handler := NewAPIHandler(store)
+	e := echo.New()
+
+	t.Run("list", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/authors", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := handler.ListAuthors(c); err != nil {
+			t.Fatalf("ListAuthors() error = %v", err)
+		}
+		var resp []AuthorCountResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if len(resp) != 2 || resp[0].ID != "alice" || resp[0].Count != 2 || resp[0].Bio != "Editor." {
+			t.Errorf("resp = %+v, want alice (2) then bob (1)", resp)
+		}
+	})
+
+	t.Run("detail", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/authors/bob", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "bob"}})
+
+		if err := handler.GetAuthor(c); err != nil {
+			t.Fatalf("GetAuthor() error = %v", err)
+		}
+		var resp AuthorDetailResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if resp.Name != "Bob" || resp.Total != 1 || len(resp.Posts) != 1 || resp.Posts[0].Slug != "co-written" {
+			t.Errorf("resp = %+v, want Bob with co-written", resp)
+		}
+
+		// Posts list every author, primary first
+		authors := resp.Posts[0].Authors
+		if len(authors) != 2 || authors[0].ID != "bob" || authors[1].ID != "alice" {
+			t.Errorf("Posts[0].Authors = %+v, want bob, alice", authors)
+		}
+		if resp.Posts[0].Author == nil || resp.Posts[0].Author.ID != "bob" {
+			t.Errorf("Posts[0].Author = %+v, want bob", resp.Posts[0].Author)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/authors/carol", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "carol"}})
+
+		err := handler.GetAuthor(c)
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusNotFound {
+			t.Errorf("GetAuthor() error = %v, want 404", err)
+		}
+	})
+}
+
+func TestAPIHandler_Archive(t *testing.T) {
+	store := newMockStore()
+	for slug, date := range map[string]time.Time{
+		"spring":  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"equinox": time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+		"autumn":  time.Date(2023, 11, 5, 0, 0, 0, 0, time.UTC),
+	} {
+		store.posts[slug] = &content.Post{Meta: content.PostMeta{Title: slug, Slug: slug, PublishDate: date}}
+	}
+
+	handler := NewAPIHandler(store)
+	e := echo.New()
+
+	t.Run("list", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/archive", nil)
+		rec := httptest.NewRecorder()
+
+		if err := handler.ListArchive(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("ListArchive() error = %v", err)
+		}
+		var resp []ArchiveYearResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if len(resp) != 2 || resp[0].Year != 2024 || resp[0].Count != 2 || resp[1].Year != 2023 ||
+			len(resp[0].Months) != 1 || resp[0].Months[0] != (ArchiveMonthResponse{Month: 3, Count: 2}) {
+			t.Errorf("resp = %+v, want 2024 (March: 2) then 2023", resp)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		year     string
+		month    string
+		wantCode int
+		want     int // Total
+	}{
+		{"year", "2024", "", http.StatusOK, 2},
+		{"month", "2024", "03", http.StatusOK, 2},
+		{"unpadded month", "2023", "11", http.StatusOK, 1},
+		{"empty month", "2024", "4", http.StatusNotFound, 0},
+		{"empty year", "2022", "", http.StatusNotFound, 0},
+		{"bad year", "latest", "", http.StatusBadRequest, 0},
+		{"bad month", "2024", "13", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/archive/"+tt.year, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			values := echo.PathValues{{Name: "year", Value: tt.year}}
+			if tt.month != "" {
+				values = append(values, echo.PathValue{Name: "month", Value: tt.month})
+			}
+			c.SetPathValues(values)
+
+			err := handler.GetArchivePeriod(c)
+			if tt.wantCode != http.StatusOK {
+				var httpErr *echo.HTTPError
+				if !errors.As(err, &httpErr) || httpErr.Code != tt.wantCode {
+					t.Errorf("GetArchivePeriod() error = %v, want %d", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetArchivePeriod() error = %v", err)
+			}
+			var resp ArchivePeriodResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if resp.Total != tt.want || len(resp.Posts) != tt.want {
+				t.Errorf("resp = %+v, want %d posts", resp, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_Search(t *testing.T) {
+	store := newMockStore()
+	store.posts["virtue"] = &content.Post{
+		Meta: content.PostMeta{
+			Title:       "Virtue Ethics",
+			Slug:        "virtue",
+			PublishDate: time.Now(),
+		},
+	}
+
+	handler := NewAPIHandler(store)
+	e := echo.New()
+
+	t.Run("matches", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/search?q=virtue", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := handler.Search(c); err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		var resp SearchResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if resp.Total != 1 || len(resp.Results) != 1 {
+			t.Fatalf("Total = %d, len(Results) = %d, want 1", resp.Total, len(resp.Results))
+		}
+		if resp.Results[0].Slug != "virtue" {
+			t.Errorf("Results[0].Slug = %q, want virtue", resp.Results[0].Slug)
+		}
+		if resp.Results[0].Snippet == "" {
+			t.Error("Results[0].Snippet should be set")
+		}
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/search?q=", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Search(c)
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusBadRequest {
+			t.Errorf("Search() error = %v, want 400", err)
+		}
+	})
+}
+
+func TestAPIHandler_GetPostPreview(t *testing.T) {
+	store := newMockStore()
+	store.drafts["draft-post"] = &content.Post{
+		Meta: content.PostMeta{
+			Title:       "Draft Post",
+			Slug:        "draft-post",
+			PublishDate: time.Now(),
+			Draft:       true,
+		},
+		HTMLContent: "<p>Work in progress</p>",
+	}
+
+	signer := preview.NewSigner("test-secret")
+	handler := NewAPIHandler(store)
+	handler.EnablePreviews(signer)
+	e := echo.New()
+
+	get := func(target string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "slug", Value: "draft-post"}})
+		return rec, handler.GetPost(c)
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		rec, err := get("/api/posts/draft-post?preview=" + signer.Sign("draft-post", time.Hour))
+		if err != nil {
+			t.Fatalf("GetPost() error = %v", err)
+		}
+		if got := rec.Header().Get("X-Robots-Tag"); got != "noindex" {
+			t.Errorf("X-Robots-Tag = %q, want noindex", got)
+		}
+
+		var resp PostResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if !resp.Draft {
+			t.Error("Draft should be true for a draft preview")
+		}
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		_, err := get("/api/posts/draft-post")
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusNotFound {
+			t.Errorf("GetPost() error = %v, want 404", err)
+		}
+	})
+
+	t.Run("token for another slug", func(t *testing.T) {
+		_, err := get("/api/posts/draft-post?preview=" + signer.Sign("other", time.Hour))
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusForbidden {
+			t.Errorf("GetPost() error = %v, want 403", err)
+		}
+	})
+
+	t.Run("previews disabled", func(t *testing.T) {
+		disabled := NewAPIHandler(store)
+		req := httptest.NewRequest(http.MethodGet, "/api/posts/draft-post?preview=x", nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetPathValues(echo.PathValues{{Name: "slug", Value: "draft-post"}})
+
+		err := disabled.GetPost(c)
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusForbidden {
+			t.Errorf("GetPost() error = %v, want 403", err)
+		}
+	})
+}
+
+func TestAPIHandler_GetPostImage(t *testing.T) {
+	store := newMockStore()
+	store.posts["essay"] = &content.Post{
+		Meta: content.PostMeta{Title: "Essay", Slug: "essay"},
+		Images: map[string]*images.Image{
+			"chart.png": {
+				Width:  1000,
+				Height: 500,
+				Variants: []images.Variant{
+					{Width: 480, Height: 240, Format: images.FormatPNG, Data: []byte("png")},
+					{Width: 480, Height: 240, Format: images.FormatWebP, Data: []byte("webp")},
+				},
+			},
+		},
+	}
+
+	handler := NewAPIHandler(store)
+	e := echo.New()
+
+	tests := []struct {
+		name        string
+		width       string
+		filename    string
+		accept      string
+		wantStatus  int
+		wantType    string
+		wantContent string
+	}{
+		{"webp accepted", "480", "chart.png", "image/avif,image/webp,*/*", http.StatusOK, "image/webp", "webp"},
+		{"fallback", "480", "chart.png", "image/png,*/*", http.StatusOK, "image/png", "png"},
+		{"unknown width", "960", "chart.png", "image/webp", http.StatusNotFound, "", ""},
+		{"invalid width", "wide", "chart.png", "image/webp", http.StatusNotFound, "", ""},
+		{"unknown image", "480", "photo.jpg", "image/webp", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/posts/essay/_img/"+tt.width+"/"+tt.filename, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPathValues(echo.PathValues{
+				{Name: "slug", Value: "essay"},
+				{Name: "width", Value: tt.width},
+				{Name: "filename", Value: tt.filename},
+			})
+
+			err := handler.GetPostImage(c)
+			if tt.wantStatus != http.StatusOK {
+				var httpErr *echo.HTTPError
+				if !errors.As(err, &httpErr) || httpErr.Code != tt.wantStatus {
+					t.Errorf("GetPostImage() error = %v, want %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPostImage() error = %v", err)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", ct, tt.wantType)
+			}
+			if vary := rec.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("Vary = %q, want Accept", vary)
+			}
+			if rec.Body.String() != tt.wantContent {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantContent)
+			}
+		})
+	}
+}
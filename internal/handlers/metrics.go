@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"therefore/internal/metrics"
+
+	"github.com/labstack/echo/v5"
+)
+
+// Kinds of asset counted by assetsNotFound.
+const (
+	assetBundle = "bundle" // Files in a post's page bundle
+	assetImage  = "image"  // Resized variants of bundle images
+	assetStatic = "static" // Frontend build output under /assets
+)
+
+var assetsNotFound = metrics.NewCounterVec("therefore_asset_not_found_total",
+	"Requests for assets that don't exist, by kind of asset.", "kind")
+
+// assetNotFound counts a missing asset of the given kind and returns the
+// 404 error for it.
+func assetNotFound(kind, message string) error {
+	assetsNotFound.Inc(kind)
+	return echo.NewHTTPError(http.StatusNotFound, message)
+}
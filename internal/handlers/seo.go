@@ -2,12 +2,16 @@ package handlers
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"therefore/internal/content"
+	"therefore/internal/feed"
+	"therefore/internal/views"
 
 	"github.com/labstack/echo/v5"
 )
@@ -26,6 +30,10 @@ func SitemapHandler(store content.ContentStore, baseURL string) echo.HandlerFunc
 	base := strings.TrimRight(baseURL, "/")
 
 	return func(c *echo.Context) error {
+		if done, err := revalidateListing(c, store); done {
+			return err
+		}
+
 		ctx := c.Request().Context()
 
 		urlset := urlSet{
@@ -33,7 +41,7 @@ func SitemapHandler(store content.ContentStore, baseURL string) echo.HandlerFunc
 		}
 
 		// Static pages
-		staticPages := []string{"/", "/posts", "/tags", "/series", "/about"}
+		staticPages := []string{"/", "/posts", "/tags", "/series", "/authors", "/archive", "/about"}
 		for _, path := range staticPages {
 			urlset.URLs = append(urlset.URLs, sitemapURL{
 				Loc: base + path,
@@ -48,7 +56,7 @@ func SitemapHandler(store content.ContentStore, baseURL string) echo.HandlerFunc
 		for _, post := range posts {
 			urlset.URLs = append(urlset.URLs, sitemapURL{
 				Loc:     base + "/posts/" + post.Meta.Slug,
-				LastMod: post.Meta.PublishDate.Format(time.DateOnly),
+				LastMod: post.Meta.LastModified().Format(time.DateOnly),
 			})
 		}
 
@@ -70,10 +78,37 @@ func SitemapHandler(store content.ContentStore, baseURL string) echo.HandlerFunc
 		}
 		for _, s := range seriesList {
 			urlset.URLs = append(urlset.URLs, sitemapURL{
-				Loc: base + "/series?open=" + s.Series,
+				Loc: base + "/series/" + url.PathEscape(s.Series),
+			})
+		}
+
+		// Authors
+		authors, err := store.GetAuthors(ctx)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to list authors")
+		}
+		for _, a := range authors {
+			urlset.URLs = append(urlset.URLs, sitemapURL{
+				Loc: base + "/authors/" + url.PathEscape(a.Author.ID),
 			})
 		}
 
+		// Archive years and months
+		archive, err := store.GetArchive(ctx)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to get archive")
+		}
+		for _, y := range archive {
+			urlset.URLs = append(urlset.URLs, sitemapURL{
+				Loc: base + views.ArchiveURL(y.Year, 0),
+			})
+			for _, m := range y.Months {
+				urlset.URLs = append(urlset.URLs, sitemapURL{
+					Loc: base + views.ArchiveURL(y.Year, m.Month),
+				})
+			}
+		}
+
 		output, err := xml.MarshalIndent(urlset, "", "  ")
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate sitemap")
@@ -84,6 +119,44 @@ func SitemapHandler(store content.ContentStore, baseURL string) echo.HandlerFunc
 	}
 }
 
+// FeedHandler returns a handler that serves a feed in the given format.
+// The feed covers the whole site unless the route has a :tag or :series
+// parameter, in which case it covers only that tag or series.
+func FeedHandler(store content.ContentStore, baseURL string, format feed.Format) echo.HandlerFunc {
+	builder := feed.NewBuilder(store, baseURL)
+
+	return func(c *echo.Context) error {
+		ctx := c.Request().Context()
+
+		var f *feed.Feed
+		var err error
+		switch {
+		case c.Param("tag") != "":
+			f, err = builder.Tag(ctx, c.Param("tag"))
+		case c.Param("series") != "":
+			f, err = builder.Series(ctx, c.Param("series"))
+		default:
+			f, err = builder.Site(ctx)
+		}
+		if err != nil {
+			if errors.Is(err, content.ErrPostNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "feed not found")
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to list posts")
+		}
+		if done, err := revalidateListing(c, store); done {
+			return err
+		}
+
+		output, err := f.Encode(format)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate feed")
+		}
+
+		return c.Blob(http.StatusOK, format.ContentType(), output)
+	}
+}
+
 type urlSet struct {
 	XMLName xml.Name     `xml:"urlset"`
 	XMLNS   string       `xml:"xmlns,attr"`
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"therefore/internal/content"
+	"therefore/internal/feed"
 
 	"github.com/labstack/echo/v5"
 )
@@ -64,6 +66,7 @@ func TestSitemapHandler(t *testing.T) {
 			Title:       "Test Post",
 			Slug:        "test-post",
 			PublishDate: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+			UpdatedDate: time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC),
 			Tags:        []string{"philosophy"},
 		},
 	}
@@ -73,6 +76,9 @@ func TestSitemapHandler(t *testing.T) {
 	store.series = []content.SeriesCount{
 		{Series: "Ethics", Count: 2},
 	}
+	store.authors = []content.AuthorCount{
+		{Author: content.Author{ID: "alice", Name: "Alice"}, Count: 1},
+	}
 
 	handler := SitemapHandler(store, "https://example.com")
 
@@ -94,7 +100,7 @@ func TestSitemapHandler(t *testing.T) {
 	}
 
 	// Check static pages
-	for _, path := range []string{"/", "/posts", "/tags", "/series", "/about"} {
+	for _, path := range []string{"/", "/posts", "/tags", "/series", "/authors", "/archive", "/about"} {
 		if !strings.Contains(body, "https://example.com"+path) {
 			t.Errorf("missing static page URL: %s", path)
 		}
@@ -104,8 +110,8 @@ func TestSitemapHandler(t *testing.T) {
 	if !strings.Contains(body, "https://example.com/posts/test-post") {
 		t.Error("missing post URL")
 	}
-	if !strings.Contains(body, "2024-06-15") {
-		t.Error("missing post lastmod date")
+	if !strings.Contains(body, "<lastmod>2024-07-02</lastmod>") {
+		t.Error("post lastmod should be the updated date")
 	}
 
 	// Check tag URL
@@ -114,10 +120,22 @@ func TestSitemapHandler(t *testing.T) {
 	}
 
 	// Check series URL
-	if !strings.Contains(body, "https://example.com/series?open=Ethics") {
+	if !strings.Contains(body, "https://example.com/series/Ethics") {
 		t.Error("missing series URL")
 	}
 
+	// Check author URL
+	if !strings.Contains(body, "https://example.com/authors/alice") {
+		t.Error("missing author URL")
+	}
+
+	// Check archive URLs
+	for _, path := range []string{"/archive/2024", "/archive/2024/06"} {
+		if !strings.Contains(body, "https://example.com"+path+"<") {
+			t.Errorf("missing archive URL: %s", path)
+		}
+	}
+
 	// Check content type
 	ct := rec.Header().Get("Content-Type")
 	if !strings.Contains(ct, "application/xml") {
@@ -146,3 +164,70 @@ func TestSitemapHandler_Empty(t *testing.T) {
 		t.Error("missing static pages in empty sitemap")
 	}
 }
+
+func TestFeedHandler(t *testing.T) {
+	store := newMockStore()
+	store.posts["test-post"] = &content.Post{
+		Meta: content.PostMeta{
+			Title:       "Test Post",
+			Slug:        "test-post",
+			PublishDate: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+			Tags:        []string{"philosophy"},
+		},
+		HTMLContent: "<p>Full content</p>",
+	}
+
+	e := echo.New()
+
+	t.Run("site rss", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/feed.xml", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := FeedHandler(store, "https://example.com", feed.FormatRSS)(c); err != nil {
+			t.Fatalf("FeedHandler() error = %v", err)
+		}
+
+		if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, "application/rss+xml") {
+			t.Errorf("Content-Type = %q, want application/rss+xml", ct)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, "https://example.com/posts/test-post") {
+			t.Error("missing post link")
+		}
+		if !strings.Contains(body, "&lt;p&gt;Full content&lt;/p&gt;") {
+			t.Error("missing full HTML content")
+		}
+	})
+
+	t.Run("tag atom", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tags/philosophy/atom.xml", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "tag", Value: "philosophy"}})
+
+		if err := FeedHandler(store, "https://example.com", feed.FormatAtom)(c); err != nil {
+			t.Fatalf("FeedHandler() error = %v", err)
+		}
+
+		if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, "application/atom+xml") {
+			t.Errorf("Content-Type = %q, want application/atom+xml", ct)
+		}
+		if !strings.Contains(rec.Body.String(), "https://example.com/tags/philosophy/atom.xml") {
+			t.Error("missing self link for tag feed")
+		}
+	})
+
+	t.Run("unknown series", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/series/Nope/feed.xml", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "series", Value: "Nope"}})
+
+		err := FeedHandler(store, "https://example.com", feed.FormatRSS)(c)
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code != http.StatusNotFound {
+			t.Errorf("FeedHandler() error = %v, want 404", err)
+		}
+	})
+}
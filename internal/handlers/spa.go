@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"io"
 	"io/fs"
+	"log/slog"
 	"net/http"
 	"path"
 	"strings"
+	"time"
+
+	"therefore/internal/content"
+	"therefore/internal/ssg"
 
 	"github.com/labstack/echo/v5"
 )
@@ -16,6 +22,9 @@ import (
 type SPAHandler struct {
 	distFS    fs.FS
 	indexHTML []byte
+	etags     map[string]string // file path -> content hash
+	loaded    time.Time         // Last-Modified for SSG pages, which are fixed at build time
+	pages     *ssg.Pages        // renders pages on demand; nil to serve only SSG files
 }
 
 // NewSPAHandler creates a new SPAHandler from the given filesystem.
@@ -33,15 +42,47 @@ func NewSPAHandler(distFS fs.FS) (*SPAHandler, error) {
 		return nil, err
 	}
 
+	// Hash every file once, since the embedded files never change
+	etags := make(map[string]string)
+	err = fs.WalkDir(distFS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(distFS, name)
+		if err != nil {
+			return err
+		}
+		etags[name] = hashBytes(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &SPAHandler{
 		distFS:    distFS,
 		indexHTML: indexHTML,
+		etags:     etags,
+		loaded:    time.Now().Truncate(time.Second),
 	}, nil
 }
 
+// RenderPages makes the handler render the HTML for every route the store
+// knows about on demand, in preference to SSG files built into the
+// filesystem, so new content gets full HTML without a rebuild.
+func (h *SPAHandler) RenderPages(store content.ContentStore, baseURL string) {
+	h.pages = ssg.NewPages(store, baseURL, h.indexHTML)
+}
+
+// version returns the validators for a page in the filesystem.
+func (h *SPAHandler) version(name string) content.Version {
+	return content.Version{ETag: h.etags[name], Modified: h.loaded}
+}
+
 // Handler returns an Echo handler that serves the SPA.
-// It checks for pre-rendered SSG files first, then static assets,
-// and falls back to index.html for client-side routing.
+// It renders known routes on demand if RenderPages was called, then checks
+// for pre-rendered SSG files, then static assets, and falls back to
+// index.html for client-side routing.
 func (h *SPAHandler) Handler() echo.HandlerFunc {
 	fileServer := http.FileServer(http.FS(h.distFS))
 
@@ -51,11 +92,28 @@ func (h *SPAHandler) Handler() echo.HandlerFunc {
 		// Clean and normalize path
 		reqPath = path.Clean(reqPath)
 
-		// Try pre-rendered SSG file first (for SEO)
+		// Draft previews render client-side and must stay out of search results
+		if c.QueryParam("preview") != "" {
+			c.Response().Header().Set("X-Robots-Tag", "noindex")
+		}
+
+		// Render the page from the current content if we know the route
+		if h.pages != nil {
+			page, err := h.pages.Render(c.Request().Context(), reqPath)
+			switch {
+			case err == nil:
+				return servePage(c, page.Version, page.HTML)
+			case !errors.Is(err, ssg.ErrNotFound):
+				// The SPA can still render the route client-side
+				slog.Error("Rendering page", "path", reqPath, "error", err)
+			}
+		}
+
+		// Then try a pre-rendered SSG file (for SEO)
 		// We serve these directly via Blob to avoid FileServer redirect issues
 		if ssgPath := h.ssgFilePath(reqPath); ssgPath != "" {
-			if content, err := h.readFile(ssgPath); err == nil {
-				return c.Blob(http.StatusOK, "text/html; charset=utf-8", content)
+			if page, err := h.readFile(ssgPath); err == nil {
+				return servePage(c, h.version(ssgPath), page)
 			}
 		}
 
@@ -71,17 +129,30 @@ func (h *SPAHandler) Handler() echo.HandlerFunc {
 		f, err := h.distFS.Open(fsPath)
 		if err == nil {
 			_ = f.Close()
-			// File exists, serve it
+			// File exists, serve it. FileServer answers If-None-Match
+			// itself once the ETag is set.
+			h.setAssetHeaders(c, fsPath, cacheAsset)
 			fileServer.ServeHTTP(c.Response(), c.Request())
 			return nil
 		}
 
 		// File doesn't exist, serve index.html for client-side routing
-		c.Response().Header().Set("Content-Type", "text/html; charset=utf-8")
-		return c.Blob(http.StatusOK, "text/html; charset=utf-8", h.indexHTML)
+		return servePage(c, h.version("index.html"), h.indexHTML)
 	}
 }
 
+// servePage sends an HTML page, or 304 Not Modified if the client has this
+// version of it. Draft previews are never cached or revalidated, as in
+// GetPost.
+func servePage(c *echo.Context, version content.Version, page []byte) error {
+	if c.QueryParam("preview") != "" {
+		c.Response().Header().Set("Cache-Control", "private, no-store")
+	} else if done, err := revalidate(c, version, cachePage); done {
+		return err
+	}
+	return c.Blob(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
 // readFile reads a file from the embedded filesystem
 func (h *SPAHandler) readFile(path string) ([]byte, error) {
 	f, err := h.distFS.Open(path)
@@ -129,6 +200,36 @@ func (h *SPAHandler) ssgFilePath(reqPath string) string {
 		// Series listing: /series
 		return "series/index.html"
 
+	case strings.HasPrefix(reqPath, "series/"):
+		// Series page: /series/:name
+		name := strings.TrimPrefix(reqPath, "series/")
+		if name != "" && !strings.Contains(name, "/") {
+			return "series/" + name + ".html"
+		}
+
+	case reqPath == "authors":
+		// Authors listing: /authors
+		return "authors/index.html"
+
+	case strings.HasPrefix(reqPath, "authors/"):
+		// Author page: /authors/:id
+		id := strings.TrimPrefix(reqPath, "authors/")
+		if id != "" && !strings.Contains(id, "/") {
+			return "authors/" + id + ".html"
+		}
+
+	case reqPath == "archive":
+		// Archive: /archive
+		return "archive/index.html"
+
+	case strings.HasPrefix(reqPath, "archive/"):
+		// Archive year or month: /archive/:year or /archive/:year/:month
+		period := strings.TrimPrefix(reqPath, "archive/")
+		year, month, hasMonth := strings.Cut(period, "/")
+		if isDigits(year) && (!hasMonth || len(month) == 2 && isDigits(month)) {
+			return "archive/" + period + ".html"
+		}
+
 	case reqPath == "about":
 		// About page: /about
 		return "about/index.html"
@@ -137,13 +238,37 @@ func (h *SPAHandler) ssgFilePath(reqPath string) string {
 	return ""
 }
 
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
 // ServeAssets returns a handler that serves static assets from /assets/*
 func (h *SPAHandler) ServeAssets() echo.HandlerFunc {
 	fileServer := http.FileServer(http.FS(h.distFS))
 
 	return func(c *echo.Context) error {
+		// Vite puts a content hash in every asset's name, so an asset at a
+		// given URL never changes
+		name := strings.TrimPrefix(path.Clean(c.Request().URL.Path), "/")
+		if _, ok := h.etags[name]; !ok {
+			assetsNotFound.Inc(assetStatic)
+		}
+		h.setAssetHeaders(c, name, cacheImmutable)
+
 		// Serve directly - files are in assets/ subdirectory of distFS
 		fileServer.ServeHTTP(c.Response(), c.Request())
 		return nil
 	}
 }
+
+// setAssetHeaders sets the ETag and Cache-Control headers for a file that
+// exists, leaving 404s uncached.
+func (h *SPAHandler) setAssetHeaders(c *echo.Context, name, cacheControl string) {
+	hash, ok := h.etags[name]
+	if !ok {
+		return
+	}
+	c.Response().Header().Set("Cache-Control", cacheControl)
+	c.Response().Header().Set("ETag", etag(hash))
+}
@@ -0,0 +1,77 @@
+// Package health tracks whether the server is starting, serving or
+// shutting down, and reports it to liveness and readiness probes.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// Probe routes requests during the server's lifecycle. It answers /healthz
+// and /readyz itself and forwards everything else to the app once Ready
+// has been called; until then other requests get 503.
+type Probe struct {
+	app      atomic.Pointer[http.Handler]
+	draining atomic.Bool
+}
+
+// Ready starts routing requests to app and marks the server ready.
+func (p *Probe) Ready(app http.Handler) {
+	p.app.Store(&app)
+}
+
+// Drain marks the server as shutting down, so probes fail and load
+// balancers stop sending it new requests. Requests are still served.
+func (p *Probe) Drain() {
+	p.draining.Store(true)
+}
+
+// Status returns "loading" before Ready is called, "draining" after Drain,
+// and "ready" otherwise.
+func (p *Probe) Status() string {
+	switch {
+	case p.draining.Load():
+		return "draining"
+	case p.app.Load() == nil:
+		return "loading"
+	}
+	return "ready"
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Probe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/healthz":
+		// The process is live while loading; only draining fails it
+		if p.draining.Load() {
+			writeStatus(w, http.StatusServiceUnavailable, "draining")
+			return
+		}
+		writeStatus(w, http.StatusOK, "ok")
+		return
+	case "/readyz":
+		status := p.Status()
+		code := http.StatusOK
+		if status != "ready" {
+			code = http.StatusServiceUnavailable
+		}
+		writeStatus(w, code, status)
+		return
+	}
+
+	app := p.app.Load()
+	if app == nil {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "Server is starting", http.StatusServiceUnavailable)
+		return
+	}
+	(*app).ServeHTTP(w, r)
+}
+
+func writeStatus(w http.ResponseWriter, code int, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	app := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("app"))
+	})
+
+	tests := []struct {
+		name   string
+		setup  func(p *Probe)
+		path   string
+		want   int
+		status string // expected "status" in the JSON body, or body substring for the app
+	}{
+		{"loading healthz", func(*Probe) {}, "/healthz", http.StatusOK, `"ok"`},
+		{"loading readyz", func(*Probe) {}, "/readyz", http.StatusServiceUnavailable, `"loading"`},
+		{"loading app", func(*Probe) {}, "/posts", http.StatusServiceUnavailable, "starting"},
+		{"ready healthz", func(p *Probe) { p.Ready(app) }, "/healthz", http.StatusOK, `"ok"`},
+		{"ready readyz", func(p *Probe) { p.Ready(app) }, "/readyz", http.StatusOK, `"ready"`},
+		{"ready app", func(p *Probe) { p.Ready(app) }, "/posts", http.StatusOK, "app"},
+		{"draining healthz", func(p *Probe) { p.Ready(app); p.Drain() }, "/healthz", http.StatusServiceUnavailable, `"draining"`},
+		{"draining readyz", func(p *Probe) { p.Ready(app); p.Drain() }, "/readyz", http.StatusServiceUnavailable, `"draining"`},
+		{"draining app", func(p *Probe) { p.Ready(app); p.Drain() }, "/posts", http.StatusOK, "app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Probe{}
+			tt.setup(p)
+
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if !strings.Contains(rec.Body.String(), tt.status) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package images generates resized variants of raster images so pages can
+// serve them through srcset instead of the full-size original.
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+)
+
+// ErrUnsupported is returned by Process for images it doesn't resize, such
+// as SVG and GIF.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// Widths are the widths, in pixels, of the variants generated for each
+// image. Widths at or above the image's own width are skipped.
+var Widths = []int{480, 960, 1440, 1920}
+
+// Sizes is the sizes attribute for images in the article column, which is
+// at most 48rem wide.
+const Sizes = "(min-width: 48rem) 48rem, 100vw"
+
+// jpegQuality is the quality JPEG variants are encoded at.
+const jpegQuality = 82
+
+// Format is an image encoding.
+type Format string
+
+const (
+	// FormatJPEG is the fallback format for JPEG sources.
+	FormatJPEG Format = "jpeg"
+	// FormatPNG is the fallback format for PNG sources, preserving transparency.
+	FormatPNG Format = "png"
+	// FormatWebP is served to clients that accept it.
+	FormatWebP Format = "webp"
+)
+
+// ContentType returns the MIME type for the format.
+func (f Format) ContentType() string {
+	return "image/" + string(f)
+}
+
+// Variant is an encoded, resized copy of an image.
+type Variant struct {
+	Width  int
+	Height int
+	Format Format
+	Data   []byte
+}
+
+// Image is a source image with its resized variants.
+type Image struct {
+	Width    int // Intrinsic width of the original
+	Height   int // Intrinsic height of the original
+	Variants []Variant
+}
+
+// Process decodes a JPEG or PNG image and encodes a variant in the source
+// format at each of Widths smaller than the image. PNG sources also get a
+// WebP variant when it comes out smaller. The WebP encoder is lossless, so
+// it is not tried for JPEG sources: for photos it never beats JPEG.
+// Returns ErrUnsupported for other formats.
+func Process(data []byte) (*Image, error) {
+	src, name, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupported
+		}
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	var fallback Format
+	switch name {
+	case "jpeg":
+		fallback = FormatJPEG
+	case "png":
+		fallback = FormatPNG
+	default:
+		return nil, ErrUnsupported
+	}
+
+	bounds := src.Bounds()
+	img := &Image{Width: bounds.Dx(), Height: bounds.Dy()}
+
+	for _, width := range Widths {
+		if width >= img.Width {
+			break
+		}
+		height := max(1, int(math.Round(float64(img.Height)*float64(width)/float64(img.Width))))
+		dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+		encoded, err := encode(dst, fallback)
+		if err != nil {
+			return nil, err
+		}
+		img.Variants = append(img.Variants, Variant{Width: width, Height: height, Format: fallback, Data: encoded})
+
+		if fallback != FormatPNG {
+			continue
+		}
+		webp, err := encode(dst, FormatWebP)
+		if err != nil {
+			return nil, err
+		}
+		if len(webp) < len(encoded) {
+			img.Variants = append(img.Variants, Variant{Width: width, Height: height, Format: FormatWebP, Data: webp})
+		}
+	}
+
+	return img, nil
+}
+
+func encode(img image.Image, format Format) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		err = png.Encode(&buf, img)
+	case FormatWebP:
+		err = nativewebp.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", format, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Variant returns the variant of the given width in the best format the
+// Accept header allows, or nil if there is no variant of that width.
+func (img *Image) Variant(width int, accept string) *Variant {
+	var fallback *Variant
+	for i := range img.Variants {
+		v := &img.Variants[i]
+		if v.Width != width {
+			continue
+		}
+		if v.Format != FormatWebP {
+			fallback = v
+		} else if acceptsWebP(accept) {
+			return v
+		}
+	}
+	return fallback
+}
+
+// Srcset returns the srcset attribute for the image served at src, listing
+// each variant and then the original at its full width.
+func (img *Image) Srcset(src string) string {
+	var candidates []string
+	for _, v := range img.Variants {
+		if v.Format == FormatWebP {
+			continue
+		}
+		candidates = append(candidates, VariantURL(src, v.Width)+" "+strconv.Itoa(v.Width)+"w")
+	}
+	candidates = append(candidates, src+" "+strconv.Itoa(img.Width)+"w")
+	return strings.Join(candidates, ", ")
+}
+
+// VariantURL returns the URL a variant of the image at src is served
+// under: /posts/<slug>/_img/<width>/<filename> for /posts/<slug>/<filename>.
+func VariantURL(src string, width int) string {
+	dir, name := path.Split(src)
+	return dir + "_img/" + strconv.Itoa(width) + "/" + name
+}
+
+// acceptsWebP reports whether an Accept header lists image/webp without
+// refusing it with q=0. Wildcards don't count: browsers that decode WebP
+// name it explicitly.
+func acceptsWebP(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		if strings.TrimSpace(mediaType) != "image/webp" {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && key == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage returns a width x height checkerboard, which compresses well
+// losslessly.
+func testImage(width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			if (x/50+y/50)%2 == 0 {
+				c = color.NRGBA{R: 30, G: 60, B: 200, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestProcess(t *testing.T) {
+	var pngData, jpegData bytes.Buffer
+	_ = png.Encode(&pngData, testImage(1000, 500))
+	_ = jpeg.Encode(&jpegData, testImage(1000, 500), nil)
+
+	t.Run("png", func(t *testing.T) {
+		img, err := Process(pngData.Bytes())
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if img.Width != 1000 || img.Height != 500 {
+			t.Errorf("size = %dx%d, want 1000x500", img.Width, img.Height)
+		}
+
+		// Only widths below the original's are generated
+		want := []struct {
+			width, height int
+			format        Format
+		}{
+			{480, 240, FormatPNG}, {480, 240, FormatWebP},
+			{960, 480, FormatPNG}, {960, 480, FormatWebP},
+		}
+		if len(img.Variants) != len(want) {
+			t.Fatalf("len(Variants) = %d, want %d", len(img.Variants), len(want))
+		}
+		for i, w := range want {
+			v := img.Variants[i]
+			if v.Width != w.width || v.Height != w.height || v.Format != w.format {
+				t.Errorf("Variants[%d] = %dx%d %s, want %dx%d %s", i, v.Width, v.Height, v.Format, w.width, w.height, w.format)
+			}
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(v.Data))
+			if v.Format != FormatWebP && (err != nil || cfg.Width != w.width) {
+				t.Errorf("Variants[%d] decodes to width %d (err %v), want %d", i, cfg.Width, err, w.width)
+			}
+		}
+	})
+
+	t.Run("jpeg", func(t *testing.T) {
+		img, err := Process(jpegData.Bytes())
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		for _, v := range img.Variants {
+			if v.Format != FormatJPEG {
+				t.Errorf("variant format = %s, want only jpeg", v.Format)
+			}
+		}
+		if len(img.Variants) != 2 {
+			t.Errorf("len(Variants) = %d, want 2", len(img.Variants))
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := Process([]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`))
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Process(svg) error = %v, want ErrUnsupported", err)
+		}
+	})
+}
+
+func TestImage_Variant(t *testing.T) {
+	img := &Image{
+		Width: 1000,
+		Variants: []Variant{
+			{Width: 480, Format: FormatPNG},
+			{Width: 480, Format: FormatWebP},
+			{Width: 960, Format: FormatPNG},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		width  int
+		accept string
+		want   Format
+	}{
+		{"webp accepted", 480, "image/avif,image/webp,*/*", FormatWebP},
+		{"webp refused", 480, "image/webp;q=0, image/png", FormatPNG},
+		{"wildcard only", 480, "*/*", FormatPNG},
+		{"no webp variant", 960, "image/webp", FormatPNG},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := img.Variant(tt.width, tt.accept)
+			if v == nil || v.Format != tt.want {
+				t.Errorf("Variant(%d, %q) = %+v, want %s", tt.width, tt.accept, v, tt.want)
+			}
+		})
+	}
+
+	if v := img.Variant(1440, "image/webp"); v != nil {
+		t.Errorf("Variant(1440) = %+v, want nil", v)
+	}
+}
+
+func TestImage_Srcset(t *testing.T) {
+	img := &Image{
+		Width: 1000,
+		Variants: []Variant{
+			{Width: 480, Format: FormatPNG},
+			{Width: 480, Format: FormatWebP},
+			{Width: 960, Format: FormatPNG},
+		},
+	}
+
+	got := img.Srcset("/posts/essay/chart.png")
+	want := "/posts/essay/_img/480/chart.png 480w, /posts/essay/_img/960/chart.png 960w, /posts/essay/chart.png 1000w"
+	if got != want {
+		t.Errorf("Srcset() = %q, want %q", got, want)
+	}
+}
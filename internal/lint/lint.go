@@ -0,0 +1,312 @@
+// Package lint checks post content for problems that would otherwise only
+// show up at runtime, such as unknown shortcodes or missing bundle images.
+package lint
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"therefore/internal/content"
+	"therefore/internal/renderer"
+
+	"github.com/spf13/afero"
+)
+
+// Rule names identify the kind of problem an Issue reports.
+const (
+	RuleFrontmatter       = "frontmatter"
+	RuleEmptySummary      = "empty-summary"
+	RuleUnknownShortcode  = string(renderer.DiagUnknownShortcode)
+	RuleUnclosedShortcode = string(renderer.DiagUnclosedShortcode)
+	RuleUnmatchedClose    = string(renderer.DiagUnmatchedClose)
+	RuleBadAttribute      = string(renderer.DiagBadAttribute)
+	RuleUnterminatedTag   = string(renderer.DiagUnterminatedTag)
+	RuleMissingAttribute  = "missing-attribute"
+	RuleUnresolvedCite    = "unresolved-citation"
+	RuleDuplicateSidenote = "duplicate-sidenote"
+	RuleMissingAsset      = "missing-asset"
+	RuleLoad              = "load"
+)
+
+// Issue is a single problem found in the content.
+type Issue struct {
+	Path    string // Source file relative to the content root, or empty for the whole site
+	Line    int    // 1-based line, or 0 when the issue applies to the whole file
+	Column  int    // 1-based column in runes
+	Rule    string
+	Message string
+}
+
+// String formats the issue as "path:line:col: message [rule]".
+func (i Issue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%s [%s]", i.Message, i.Rule)
+	}
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s [%s]", i.Path, i.Message, i.Rule)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s [%s]", i.Path, i.Line, i.Column, i.Message, i.Rule)
+}
+
+// Match markdown image references: ![alt](path)
+var imageRegex = regexp.MustCompile(`!\[[^\]]*\]\(([^)\s]+)`)
+
+// Linter checks the posts in a content filesystem.
+type Linter struct {
+	fs       afero.Fs
+	specs    map[string]renderer.ShortcodeSpec
+	renderer *renderer.Renderer
+}
+
+// New creates a Linter for the posts in fs. The shortcodes and specs maps
+// are the same registry the renderer uses; any other shortcode name is
+// reported as unknown.
+func New(fs afero.Fs, shortcodes map[string]renderer.ShortcodeRenderer, specs map[string]renderer.ShortcodeSpec) *Linter {
+	r := renderer.New(shortcodes)
+	r.SetSpecs(specs)
+	return &Linter{
+		fs:       fs,
+		specs:    specs,
+		renderer: r,
+	}
+}
+
+// Run lints every post and returns the issues found, ordered by file and
+// position. The error is non-nil only if the content couldn't be read.
+func (l *Linter) Run() ([]Issue, error) {
+	sources, err := content.Sources(l.fs)
+	if err != nil {
+		return nil, fmt.Errorf("listing posts: %w", err)
+	}
+
+	var issues []Issue
+	for _, src := range sources {
+		fileIssues, err := l.lintFile(src)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, fileIssues...)
+	}
+
+	// Load the store as the server would, to catch problems that span
+	// files such as duplicate slugs. Skipped when a file failed to parse,
+	// since that error has already been reported.
+	if !hasRule(issues, RuleFrontmatter) {
+		if _, err := content.NewEmbeddedStore(l.fs, l.renderer); err != nil {
+			issues = append(issues, Issue{Rule: RuleLoad, Message: err.Error()})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return issues, nil
+}
+
+// fileLinter accumulates the issues for a single post.
+type fileLinter struct {
+	*Linter
+	src    content.Source
+	data   string
+	meta   content.PostMeta
+	slug   string
+	lines  []int // byte offset of the start of each line
+	issues []Issue
+}
+
+func (l *Linter) lintFile(src content.Source) ([]Issue, error) {
+	f, err := l.fs.Open(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", src.Path, err)
+	}
+	data, err := io.ReadAll(f)
+	_ = f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", src.Path, err)
+	}
+
+	fl := &fileLinter{Linter: l, src: src, data: string(data)}
+	fl.lines = lineStarts(fl.data)
+
+	meta, body, err := content.ParseFrontmatter(data)
+	if err != nil {
+		fl.report(0, RuleFrontmatter, "%v", err)
+		return fl.issues, nil
+	}
+	fl.meta = meta
+	fl.slug = postSlug(src, meta)
+
+	if strings.TrimSpace(meta.Summary) == "" {
+		offset := strings.Index(fl.data, "\nsummary:")
+		if offset >= 0 {
+			offset++
+		}
+		fl.report(offset, RuleEmptySummary, "summary is empty; it is used for listings, feeds and meta descriptions")
+	}
+
+	// The body is the trimmed tail of the file
+	bodyOffset := strings.LastIndex(fl.data, body)
+	fl.lintShortcodes(body, bodyOffset)
+	fl.lintImages(body, bodyOffset)
+
+	return fl.issues, nil
+}
+
+// lintShortcodes reports the shortcode diagnostics a strict renderer
+// fails on, and checks each shortcode against its spec.
+func (fl *fileLinter) lintShortcodes(body string, bodyOffset int) {
+	shortcodes, diags := fl.renderer.Diagnose(body)
+	for _, d := range diags {
+		fl.report(bodyOffset+d.Offset, string(d.Kind), "%s", d.Message)
+	}
+
+	// Check in order of appearance, so a duplicate is reported after the
+	// first use
+	var all []renderer.Shortcode
+	var flatten func([]renderer.Shortcode)
+	flatten = func(scs []renderer.Shortcode) {
+		for _, sc := range scs {
+			all = append(all, sc)
+			flatten(sc.Children)
+		}
+	}
+	flatten(shortcodes)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Offset < all[j].Offset })
+
+	sidenotes := make(map[string]int) // id -> offset of first use
+	for _, sc := range all {
+		fl.checkAttrs(sc, bodyOffset+sc.Offset, sidenotes)
+	}
+}
+
+func (fl *fileLinter) checkAttrs(sc renderer.Shortcode, offset int, sidenotes map[string]int) {
+	for _, attr := range fl.specs[sc.Name].Required {
+		if sc.Attrs[attr] == "" {
+			fl.report(offset, RuleMissingAttribute, "%s is missing required attribute %q", sc.Name, attr)
+		}
+	}
+
+	switch sc.Name {
+	case "cite":
+		alias := sc.Attrs["alias"]
+		switch {
+		case alias != "":
+			if _, ok := fl.meta.Citations[alias]; !ok {
+				fl.report(offset, RuleUnresolvedCite, "citation alias %q is not defined in frontmatter", alias)
+			}
+		case sc.Attrs["text"] == "":
+			fl.report(offset, RuleMissingAttribute, "cite needs either text or alias")
+		}
+	case "sidenote":
+		id := sc.Attrs["id"]
+		if id == "" {
+			return
+		}
+		if first, ok := sidenotes[id]; ok {
+			line, _ := fl.position(first)
+			fl.report(offset, RuleDuplicateSidenote, "duplicate sidenote id %q (first used on line %d)", id, line)
+			return
+		}
+		sidenotes[id] = offset
+	case "figure":
+		fl.checkAsset(sc.Attrs["src"], offset)
+	}
+}
+
+// lintImages checks that markdown images referring to bundle files exist.
+func (fl *fileLinter) lintImages(body string, bodyOffset int) {
+	for _, m := range imageRegex.FindAllStringSubmatchIndex(body, -1) {
+		fl.checkAsset(body[m[2]:m[3]], bodyOffset+m[0])
+	}
+}
+
+// checkAsset reports ref if it points into the post's bundle at a file
+// that doesn't exist. References to other sites and to site-wide absolute
+// paths are not checked.
+func (fl *fileLinter) checkAsset(ref string, offset int) {
+	if ref == "" || strings.Contains(ref, "://") || strings.HasPrefix(ref, "//") ||
+		strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+		return
+	}
+
+	name, ok := strings.CutPrefix(ref, "/posts/"+fl.slug+"/")
+	if !ok {
+		if strings.HasPrefix(ref, "/") {
+			return
+		}
+		name = strings.TrimPrefix(ref, "./")
+	}
+
+	if fl.src.BundleDir == "" {
+		fl.report(offset, RuleMissingAsset, "%q is a relative path but the post is not a page bundle", ref)
+		return
+	}
+	if exists, _ := afero.Exists(fl.fs, filepath.Join(fl.src.BundleDir, name)); !exists {
+		fl.report(offset, RuleMissingAsset, "%q not found in bundle %s", ref, fl.src.BundleDir)
+	}
+}
+
+// report records an issue at a byte offset in the file. A negative offset
+// marks an issue with the whole file.
+func (fl *fileLinter) report(offset int, rule, format string, args ...any) {
+	issue := Issue{
+		Path:    fl.src.Path,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if offset >= 0 {
+		issue.Line, issue.Column = fl.position(offset)
+	}
+	fl.issues = append(fl.issues, issue)
+}
+
+// position converts a byte offset in the file to a 1-based line and column.
+func (fl *fileLinter) position(offset int) (line, col int) {
+	i := sort.Search(len(fl.lines), func(i int) bool { return fl.lines[i] > offset }) - 1
+	return i + 1, utf8.RuneCountInString(fl.data[fl.lines[i]:offset]) + 1
+}
+
+// lineStarts returns the byte offset at which each line of s begins.
+func lineStarts(s string) []int {
+	starts := []int{0}
+	for i := range len(s) {
+		if s[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// postSlug returns the slug the store will give the post, which bundle
+// assets are served under.
+func postSlug(src content.Source, meta content.PostMeta) string {
+	switch {
+	case meta.Slug != "":
+		return meta.Slug
+	case src.BundleDir != "":
+		return filepath.Base(src.BundleDir)
+	default:
+		return strings.TrimSuffix(filepath.Base(src.Path), ".md")
+	}
+}
+
+func hasRule(issues []Issue, rule string) bool {
+	for _, issue := range issues {
+		if issue.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
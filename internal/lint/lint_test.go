@@ -0,0 +1,150 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"therefore/internal/renderer"
+	"therefore/internal/views"
+
+	"github.com/spf13/afero"
+)
+
+func newTestLinter(fs afero.Fs) *Linter {
+	return New(fs, views.ShortcodeRenderers(), views.ShortcodeSpecs())
+}
+
+func TestLinter_Run(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "clean.md", []byte(`---
+title: Clean
+summary: Nothing wrong here
+citations:
+  smith:
+    text: Smith (2020)
+---
+A claim.{{cite alias="smith"}}
+
+{{quote author="Plato"}}Text{{/quote}}`), 0644)
+
+	_ = afero.WriteFile(fs, "broken.md", []byte(`---
+title: Broken
+summary: ""
+---
+Intro {{unknown}}
+{{figure src="https://example.com/a.jpg"}}
+{{cite alias="missing"}}
+{{sidenote id="a"}}One{{/sidenote}} {{sidenote id="a"}}Two{{/sidenote}}
+{{term word="Logos"}}Never closed
+{{/parallel}}
+![local](diagram.png)`), 0644)
+
+	_ = afero.WriteFile(fs, "bundle/index.md", []byte(`---
+title: Bundle
+summary: Has assets
+---
+![ok](./diagram.svg)
+![gone](missing.svg)
+{{figure src="/posts/bundle/gone.png" alt="Gone"}}`), 0644)
+	_ = afero.WriteFile(fs, "bundle/diagram.svg", []byte("<svg/>"), 0644)
+
+	issues, err := newTestLinter(fs).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []string{
+		`broken.md:3:1: summary is empty; it is used for listings, feeds and meta descriptions [empty-summary]`,
+		`broken.md:5:7: unknown shortcode "unknown" [unknown-shortcode]`,
+		`broken.md:6:1: figure is missing required attribute "alt" [missing-attribute]`,
+		`broken.md:7:1: citation alias "missing" is not defined in frontmatter [unresolved-citation]`,
+		`broken.md:8:37: duplicate sidenote id "a" (first used on line 8) [duplicate-sidenote]`,
+		`broken.md:9:1: term is missing its closing tag {{/term}} [unclosed-shortcode]`,
+		`broken.md:10:1: closing tag {{/parallel}} has no opening tag [unmatched-close]`,
+		`broken.md:11:1: "diagram.png" is a relative path but the post is not a page bundle [missing-asset]`,
+		`bundle/index.md:6:1: "missing.svg" not found in bundle bundle [missing-asset]`,
+		`bundle/index.md:7:1: "/posts/bundle/gone.png" not found in bundle bundle [missing-asset]`,
+	}
+
+	if len(issues) != len(want) {
+		for _, issue := range issues {
+			t.Log(issue)
+		}
+		t.Fatalf("len(issues) = %d, want %d", len(issues), len(want))
+	}
+	for i, w := range want {
+		if got := issues[i].String(); got != w {
+			t.Errorf("issues[%d] = %s\n want %s", i, got, w)
+		}
+	}
+}
+
+func TestLinter_FrontmatterAndLoad(t *testing.T) {
+	t.Run("unclosed frontmatter", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		_ = afero.WriteFile(fs, "bad.md", []byte("---\ntitle: Bad\n"), 0644)
+
+		issues, err := newTestLinter(fs).Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(issues) != 1 || issues[0].Rule != RuleFrontmatter {
+			t.Errorf("issues = %v, want a single frontmatter issue", issues)
+		}
+	})
+
+	t.Run("duplicate slug", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		for _, name := range []string{"a.md", "b.md"} {
+			_ = afero.WriteFile(fs, name, []byte("---\ntitle: Same\nslug: same\nsummary: S\n---\nBody"), 0644)
+		}
+
+		issues, err := newTestLinter(fs).Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(issues) != 1 || issues[0].Rule != RuleLoad {
+			t.Errorf("issues = %v, want a single load issue", issues)
+		}
+	})
+}
+
+func TestLinter_MatchesStrictRenderer(t *testing.T) {
+	const frontmatter = "---\ntitle: Nested\nsummary: S\n---\n"
+	const body = `{{quote author="A"}}
+Outer {{quote author="B"}}inner{{/quote}} {{nope}}
+{{/quote}}
+{{term word="Logos"}}Never closed
+{{/nope}}`
+
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "nested.md", []byte(frontmatter+body), 0644)
+	issues, err := newTestLinter(fs).Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	r := renderer.New(views.ShortcodeRenderers())
+	r.SetSpecs(views.ShortcodeSpecs())
+	r.SetStrict(true)
+	_, err = r.Render(body, nil)
+	var diagErr *renderer.DiagnosticError
+	if !errors.As(err, &diagErr) {
+		t.Fatalf("Render() error = %v, want a *DiagnosticError", err)
+	}
+
+	// The body starts on line 5 of the file
+	var want []string
+	for _, d := range diagErr.Diagnostics {
+		want = append(want, fmt.Sprintf("nested.md:%d:%d: %s [%s]", d.Line+4, d.Column, d.Message, d.Kind))
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("issues = %q\n want %q", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+// Package logging configures the structured logger shared by the CLI
+// commands and logs HTTP requests through it.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/labstack/echo/v5/middleware"
+)
+
+// Format selects how log records are encoded.
+type Format string
+
+const (
+	// FormatText writes key=value records, for terminals.
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per record, for log collectors.
+	FormatJSON Format = "json"
+)
+
+// New returns a logger writing to w at the given level ("debug", "info",
+// "warn" or "error") in the given format ("text" or "json").
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: want debug, info, warn or error", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch Format(strings.ToLower(format)) {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q: want text or json", format)
+}
+
+// RequestLogger returns an Echo middleware that logs each request to
+// logger once it completes. Server errors are logged at error level, and
+// requests whose User-Agent is quietAgent (such as the server's own
+// cache warm-up) at debug level.
+func RequestLogger(logger *slog.Logger, quietAgent string) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogLatency:       true,
+		LogRemoteIP:      true,
+		LogMethod:        true,
+		LogURIPath:       true,
+		LogRoutePath:     true,
+		LogRequestID:     true,
+		LogUserAgent:     true,
+		LogStatus:        true,
+		LogContentLength: true,
+		LogResponseSize:  true,
+		// Let the error handler write the response first, so the status
+		// logged is the one the client saw
+		HandleError: true,
+		LogValuesFunc: func(_ *echo.Context, v middleware.RequestLoggerValues) error {
+			level := slog.LevelInfo
+			switch {
+			case v.Status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case quietAgent != "" && v.UserAgent == quietAgent:
+				level = slog.LevelDebug
+			}
+
+			attrs := []slog.Attr{
+				slog.String("requestID", v.RequestID),
+				slog.String("method", v.Method),
+				slog.String("path", v.URIPath),
+				slog.String("route", v.RoutePath),
+				slog.Int("status", v.Status),
+				slog.Duration("latency", v.Latency),
+				slog.String("bytesIn", v.ContentLength),
+				slog.Int64("bytesOut", v.ResponseSize),
+				slog.String("remoteIP", v.RemoteIP),
+				slog.String("userAgent", v.UserAgent),
+			}
+			if v.Error != nil {
+				attrs = append(attrs, slog.String("error", v.Error.Error()))
+			}
+			logger.LogAttrs(context.Background(), level, "Request", attrs...)
+			return nil
+		},
+	})
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/labstack/echo/v5/middleware"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		level   string
+		format  string
+		wantErr bool
+	}{
+		{"info", "text", false},
+		{"DEBUG", "JSON", false},
+		{"warn", "json", false},
+		{"verbose", "text", true},
+		{"info", "xml", true},
+	}
+
+	for _, tt := range tests {
+		_, err := New(&bytes.Buffer{}, tt.level, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q, %q) error = %v, wantErr %v", tt.level, tt.format, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", "text")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, "shown") {
+		t.Errorf("output = %q, want only the warning", out)
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	e := echo.New()
+	e.Use(middleware.RequestID())
+	e.Use(RequestLogger(logger, "warmup"))
+	e.GET("/api/posts/:slug", func(c *echo.Context) error {
+		return c.String(http.StatusOK, "hello")
+	})
+	e.GET("/broken", func(*echo.Context) error {
+		return echo.NewHTTPError(http.StatusInternalServerError, "boom")
+	})
+
+	serve := func(path, userAgent string) map[string]any {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("User-Agent", userAgent)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+		if buf.Len() == 0 {
+			return nil
+		}
+		var record map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("decoding log record %q: %v", buf.String(), err)
+		}
+		return record
+	}
+
+	record := serve("/api/posts/hello", "test")
+	want := map[string]any{
+		"level":    "INFO",
+		"method":   "GET",
+		"path":     "/api/posts/hello",
+		"route":    "/api/posts/:slug",
+		"status":   float64(200),
+		"bytesOut": float64(5),
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+	if id, _ := record["requestID"].(string); id == "" {
+		t.Error("missing requestID")
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Error("missing latency")
+	}
+
+	if record := serve("/broken", "test"); record["level"] != "ERROR" || record["error"] == nil {
+		t.Errorf("server error record = %v, want level ERROR with error", record)
+	}
+
+	if record := serve("/api/posts/hello", "warmup"); record != nil {
+		t.Errorf("warm-up request logged at info: %v", record)
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+var (
+	httpRequests = NewCounterVec("therefore_http_requests_total",
+		"HTTP requests by route, method and status code.", "route", "method", "status")
+	httpDuration = NewHistogramVec("therefore_http_request_duration_seconds",
+		"Time to serve HTTP requests, by route and method.", DefaultBuckets, "route", "method")
+	httpResponseSize = NewHistogramVec("therefore_http_response_size_bytes",
+		"Size of HTTP response bodies as sent, after compression, by route and method.", SizeBuckets, "route", "method")
+)
+
+// methods are the request methods recorded under their own name. Others
+// are recorded as OTHER, so clients can't create series at will.
+var methods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// Middleware returns an Echo middleware that records request counts,
+// latencies and response sizes by route pattern, so /posts/:slug is one
+// series however many posts there are. Register it before the compression
+// middleware so sizes are what went over the wire.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			elapsed := time.Since(start)
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			method := c.Request().Method
+			if !methods[method] {
+				method = "OTHER"
+			}
+			resp, status := echo.ResolveResponseStatus(c.Response(), err)
+
+			httpRequests.Inc(route, method, strconv.Itoa(status))
+			httpDuration.Observe(elapsed.Seconds(), route, method)
+			if resp != nil {
+				httpResponseSize.Observe(float64(resp.Size), route, method)
+			}
+			return err
+		}
+	}
+}
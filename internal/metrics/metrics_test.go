@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	latency := r.NewHistogramVec("latency_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	r.NewGaugeFunc("posts", "Published posts.", func() float64 { return 12 })
+
+	requests.Inc("/b", "200")
+	requests.Add(2, "/a", "404")
+	requests.Inc("/a", "404")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.1, "/a")
+	latency.Observe(3, "/a")
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/a",status="404"} 3
+requests_total{route="/b",status="200"} 1
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.1"} 2
+latency_seconds_bucket{route="/a",le="1"} 2
+latency_seconds_bucket{route="/a",le="+Inf"} 3
+latency_seconds_sum{route="/a"} 3.15
+latency_seconds_count{route="/a"} 3
+# HELP posts Published posts.
+# TYPE posts gauge
+posts 12
+`
+	if got := b.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistry_Escaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("escaped_total", "Help with \\ and\nnewline.", "label")
+	c.Inc("quote \" backslash \\ newline \n")
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`# HELP escaped_total Help with \\ and\nnewline.`,
+		`escaped_total{label="quote \" backslash \\ newline \n"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output missing %q\ngot:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestRegistry_Duplicate(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("dup_total", "First.")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate name did not panic")
+		}
+	}()
+	r.NewGaugeFunc("dup_total", "Second.", func() float64 { return 0 })
+}
+
+// scrape returns the samples Default serves, keyed by series.
+func scrape(t *testing.T) map[string]float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Default.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want text/plain; version=0.0.4", ct)
+	}
+
+	samples := make(map[string]float64)
+	for line := range strings.Lines(rec.Body.String()) {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		v, err := strconv.ParseFloat(strings.TrimSpace(line[i+1:]), 64)
+		if err != nil {
+			t.Fatalf("parsing sample %q: %v", line, err)
+		}
+		samples[line[:i]] = v
+	}
+	return samples
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET("/posts/:slug", func(c *echo.Context) error {
+		return c.String(http.StatusOK, "hello")
+	})
+
+	// The HTTP metrics live on Default, so compare against what earlier
+	// runs left there
+	before := scrape(t)
+
+	for _, path := range []string{"/posts/a", "/posts/b", "/missing"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	after := scrape(t)
+	for _, tt := range []struct {
+		series string
+		want   float64
+	}{
+		{`therefore_http_requests_total{route="/posts/:slug",method="GET",status="200"}`, 2},
+		{`therefore_http_requests_total{route="unmatched",method="GET",status="404"}`, 1},
+		{`therefore_http_request_duration_seconds_count{route="/posts/:slug",method="GET"}`, 2},
+		{`therefore_http_request_duration_seconds_bucket{route="/posts/:slug",method="GET",le="+Inf"}`, 2},
+		{`therefore_http_response_size_bytes_sum{route="/posts/:slug",method="GET"}`, 10},
+		{`therefore_http_response_size_bytes_bucket{route="/posts/:slug",method="GET",le="256"}`, 2},
+		{`therefore_http_response_size_bytes_count{route="unmatched",method="GET"}`, 1},
+	} {
+		if got := after[tt.series] - before[tt.series]; got != tt.want {
+			t.Errorf("%s increased by %v, want %v", tt.series, got, tt.want)
+		}
+	}
+}
+
+func TestMiddleware_UnknownMethods(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware())
+	e.Any("/posts/:slug", func(c *echo.Context) error {
+		return c.String(http.StatusOK, "hello")
+	})
+
+	before := httpRequests.Value("/posts/:slug", "OTHER", "200")
+	for _, method := range []string{"FOO1", "FOO2", "FOO3"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, "/posts/a", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, want 200", method, rec.Code)
+		}
+	}
+
+	if got := httpRequests.Value("/posts/:slug", "OTHER", "200") - before; got != 3 {
+		t.Errorf("requests recorded as OTHER = %v, want 3", got)
+	}
+	for series := range scrape(t) {
+		if strings.Contains(series, `method="FOO`) {
+			t.Errorf("series %s has the client's method, want OTHER", series)
+		}
+	}
+}
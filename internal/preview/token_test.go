@@ -0,0 +1,62 @@
+package preview
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	s := NewSigner("secret")
+	s.now = func() time.Time { return now }
+
+	token := s.Sign("my-draft", time.Hour)
+
+	if err := s.Verify(token, "my-draft"); err != nil {
+		t.Errorf("Verify() error = %v, want nil", err)
+	}
+
+	t.Run("wrong slug", func(t *testing.T) {
+		if err := s.Verify(token, "other-post"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("tampered expiry", func(t *testing.T) {
+		tampered := "9999999999" + token[len("1705323600"):]
+		if err := s.Verify(tampered, "my-draft"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("different secret", func(t *testing.T) {
+		other := NewSigner("another secret")
+		if err := other.Verify(token, "my-draft"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		if err := s.Verify("garbage", "my-draft"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		if err := s.Verify(token, "my-draft"); !errors.Is(err, ErrExpiredToken) {
+			t.Errorf("Verify() error = %v, want ErrExpiredToken", err)
+		}
+	})
+}
+
+func TestNewSigner_EmptySecret(t *testing.T) {
+	s := NewSigner("")
+	if s != nil {
+		t.Fatal("NewSigner(\"\") should return nil")
+	}
+	if err := s.Verify("123.abc", "slug"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("nil Signer Verify() error = %v, want ErrInvalidToken", err)
+	}
+}
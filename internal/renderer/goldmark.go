@@ -3,11 +3,16 @@ package renderer
 
 import (
 	"bytes"
+	stdhtml "html"
+	"regexp"
+	"strings"
 
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
 
 	highlighting "github.com/yuin/goldmark-highlighting/v2"
 )
@@ -41,9 +46,78 @@ func NewGoldmarkRenderer() *GoldmarkRenderer {
 
 // Convert converts markdown to HTML without shortcode processing.
 func (g *GoldmarkRenderer) Convert(source []byte) ([]byte, error) {
+	html, _, err := g.ConvertWithHeadings(source)
+	return html, err
+}
+
+// ConvertWithHeadings converts markdown to HTML and also returns every
+// heading in the document, in order and not yet nested.
+func (g *GoldmarkRenderer) ConvertWithHeadings(source []byte) ([]byte, []Heading, error) {
+	ids := headingIDs{parser.NewContext().IDs()}
+	doc := g.md.Parser().Parse(text.NewReader(source),
+		parser.WithContext(parser.NewContext(parser.WithIDs(ids))))
+
+	var headings []Heading
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		headings = append(headings, Heading{
+			Level: heading.Level,
+			ID:    string(idBytes),
+			Text:  headingText(heading, source),
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var buf bytes.Buffer
-	if err := g.md.Convert(source, &buf); err != nil {
-		return nil, err
+	if err := g.md.Renderer().Render(&buf, source, doc); err != nil {
+		return nil, nil, err
 	}
-	return buf.Bytes(), nil
+	return buf.Bytes(), headings, nil
+}
+
+// Match shortcode placeholders
+var placeholderRegex = regexp.MustCompile(`<!--shortcode:[^>]*-->`)
+
+// headingIDs wraps Goldmark's heading ID generator to leave shortcode
+// placeholders out of IDs, since their random UUIDs would give a heading
+// a different anchor on every render.
+type headingIDs struct {
+	parser.IDs
+}
+
+func (ids headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	return ids.IDs.Generate(placeholderRegex.ReplaceAll(value, nil), kind)
+}
+
+// headingText returns the plain text of a heading, leaving out raw HTML
+// such as shortcode placeholders.
+func headingText(n ast.Node, source []byte) string {
+	var sb strings.Builder
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			sb.WriteString(stdhtml.UnescapeString(string(n.Value(source))))
+			if n.SoftLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			// Typographer substitutions are stored as HTML entities
+			sb.WriteString(stdhtml.UnescapeString(string(n.Value)))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
 }
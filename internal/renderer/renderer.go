@@ -1,5 +1,12 @@
 package renderer
 
+import (
+	stdhtml "html"
+	"strings"
+
+	"therefore/internal/images"
+)
+
 // RenderContext provides additional context for shortcode rendering.
 type RenderContext struct {
 	// Citations maps alias names to citation data (text, url).
@@ -7,6 +14,25 @@ type RenderContext struct {
 		Text string
 		URL  string
 	}
+
+	// BundleURL is the URL of the post's page bundle ("/posts/<slug>/"),
+	// which relative asset paths resolve against. Empty for standalone posts.
+	BundleURL string
+
+	// Images maps bundle image URLs to their resized variants.
+	Images map[string]*images.Image
+}
+
+// Asset resolves a relative asset path against the post's bundle and
+// returns its URL with the image's resized variants, if it has any.
+func (c *RenderContext) Asset(src string) (string, *images.Image) {
+	if c == nil {
+		return src, nil
+	}
+	if c.BundleURL != "" && src != "" && !strings.HasPrefix(src, "/") && !strings.Contains(src, "://") {
+		src = c.BundleURL + strings.TrimPrefix(src, "./")
+	}
+	return src, c.Images[src]
 }
 
 // ShortcodeRenderer is a function that renders a shortcode to HTML.
@@ -18,6 +44,7 @@ type Renderer struct {
 	goldmark  *GoldmarkRenderer
 	parser    *ShortcodeParser
 	renderers map[string]ShortcodeRenderer
+	strict    bool
 }
 
 // New creates a new Renderer with the given shortcode renderers.
@@ -29,33 +56,110 @@ func New(shortcodeRenderers map[string]ShortcodeRenderer) *Renderer {
 	}
 }
 
-// Render processes markdown content through the full pipeline:
+// SetSpecs declares which shortcodes are blocks, so that a missing
+// closing tag is reported as a diagnostic.
+func (r *Renderer) SetSpecs(specs map[string]ShortcodeSpec) {
+	r.parser.SetSpecs(specs)
+}
+
+// SetStrict makes Render fail with a *DiagnosticError when the content has
+// malformed, unclosed or unknown shortcodes, instead of rendering around
+// them.
+func (r *Renderer) SetStrict(strict bool) {
+	r.strict = strict
+}
+
+// Document is a rendered post.
+type Document struct {
+	HTML string
+	TOC  []Heading // Heading outline, nested by level
+}
+
+// Render processes markdown content through the full pipeline and returns
+// the HTML. See RenderDocument.
+func (r *Renderer) Render(raw string, ctx *RenderContext) (string, error) {
+	doc, err := r.RenderDocument(raw, ctx)
+	return doc.HTML, err
+}
+
+// RenderDocument processes markdown content through the full pipeline:
 // 1. Parse shortcodes and replace with placeholders
-// 2. Convert markdown to HTML via Goldmark
+// 2. Convert markdown to HTML via Goldmark, collecting the headings
 // 3. Replace placeholders with rendered shortcode HTML
 // The ctx parameter provides post-level context like citations (can be nil).
-func (r *Renderer) Render(raw string, ctx *RenderContext) (string, error) {
+func (r *Renderer) RenderDocument(raw string, ctx *RenderContext) (Document, error) {
 	// Step 1: Extract shortcodes
-	content, shortcodes := r.parser.Parse(raw)
+	content, shortcodes, diags := r.parse(raw)
+	if r.strict && len(diags) > 0 {
+		return Document{}, &DiagnosticError{Diagnostics: diags}
+	}
 
 	// Step 2: Convert markdown to HTML
-	html, err := r.goldmark.Convert([]byte(content))
+	html, headings, err := r.goldmark.ConvertWithHeadings([]byte(content))
 	if err != nil {
-		return "", err
+		return Document{}, err
 	}
 
 	result := string(html)
 
 	// Step 3: Replace placeholders with rendered shortcodes
+	result = r.replaceShortcodes(result, shortcodes, ctx)
+
+	return Document{HTML: result, TOC: NestHeadings(headings)}, nil
+}
+
+// Diagnose parses the shortcodes in raw without rendering it. Returns the
+// top-level shortcodes, as ShortcodeParser.Parse does, and the diagnostics
+// a strict Renderer fails on, unknown shortcodes included.
+func (r *Renderer) Diagnose(raw string) ([]Shortcode, []Diagnostic) {
+	_, shortcodes, diags := r.parse(raw)
+	return shortcodes, diags
+}
+
+// parse is ShortcodeParser.Parse with diagnostics for unknown shortcodes
+// added.
+func (r *Renderer) parse(raw string) (string, []Shortcode, []Diagnostic) {
+	content, shortcodes, diags := r.parser.Parse(raw)
+	if unknown := r.unknown(raw, shortcodes); len(unknown) > 0 {
+		diags = append(diags, unknown...)
+		sortDiagnostics(diags)
+	}
+	return content, shortcodes, diags
+}
+
+// replaceShortcodes renders each shortcode and substitutes it for its
+// placeholder in html. Nested shortcodes are rendered after their parent,
+// so the parent's own processing of its content (inline markdown, verse
+// numbers) only ever sees the placeholder. A parent that escapes its
+// content into an attribute, like sidenote, escapes the placeholder too,
+// so the escaped form is replaced with escaped HTML.
+func (r *Renderer) replaceShortcodes(html string, shortcodes []Shortcode, ctx *RenderContext) string {
 	for _, sc := range shortcodes {
 		renderer, ok := r.renderers[sc.Name]
 		if !ok {
 			// Unknown shortcode, leave placeholder as-is (or could render as error)
 			continue
 		}
-		rendered := renderer(sc, ctx)
-		result = ReplacePlaceholder(result, sc.ID, rendered)
+		rendered := r.replaceShortcodes(renderer(sc, ctx), sc.Children, ctx)
+		if strings.Contains(html, placeholder(sc.ID)) {
+			html = ReplacePlaceholder(html, sc.ID, rendered)
+		} else {
+			html = strings.Replace(html, stdhtml.EscapeString(placeholder(sc.ID)), stdhtml.EscapeString(rendered), 1)
+		}
 	}
+	return html
+}
 
-	return result, nil
+// unknown returns a diagnostic for every shortcode, at any depth, that
+// has no renderer.
+func (r *Renderer) unknown(raw string, shortcodes []Shortcode) []Diagnostic {
+	var diags []Diagnostic
+	for _, sc := range shortcodes {
+		if _, ok := r.renderers[sc.Name]; !ok {
+			diags = append(diags, r.parser.diagnostic(raw, DiagUnknownShortcode, sc.Offset,
+				"unknown shortcode %q", sc.Name))
+		}
+		diags = append(diags, r.unknown(raw, sc.Children)...)
+	}
+	return diags
 }
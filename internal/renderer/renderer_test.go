@@ -1,6 +1,8 @@
 package renderer
 
 import (
+	"errors"
+	"html"
 	"strings"
 	"testing"
 )
@@ -148,7 +150,7 @@ func TestShortcodeParser_SelfClosing(t *testing.T) {
 	p := NewShortcodeParser()
 
 	input := `Before {{figure src="/img/test.jpg" alt="Test"}} after.`
-	content, shortcodes := p.Parse(input)
+	content, shortcodes, _ := p.Parse(input)
 
 	if len(shortcodes) != 1 {
 		t.Fatalf("Parse() returned %d shortcodes, want 1", len(shortcodes))
@@ -184,7 +186,7 @@ func TestShortcodeParser_Block(t *testing.T) {
 The beginning is the most important part of the work.
 {{/quote}}`
 
-	content, shortcodes := p.Parse(input)
+	content, shortcodes, _ := p.Parse(input)
 
 	if len(shortcodes) != 1 {
 		t.Fatalf("Parse() returned %d shortcodes, want 1", len(shortcodes))
@@ -219,7 +221,7 @@ Middle
 {{quote author="Test"}}Inner content{{/quote}}
 End`
 
-	content, shortcodes := p.Parse(input)
+	content, shortcodes, _ := p.Parse(input)
 
 	if len(shortcodes) != 2 {
 		t.Fatalf("Parse() returned %d shortcodes, want 2", len(shortcodes))
@@ -315,4 +317,143 @@ func TestRenderer_UnknownShortcode(t *testing.T) {
 	if !strings.Contains(result, "<!--shortcode:") {
 		t.Error("Unknown shortcode placeholder was removed")
 	}
+	if got := CountPlaceholders(result); got != 1 {
+		t.Errorf("CountPlaceholders() = %d, want 1", got)
+	}
+}
+
+func TestRenderer_Strict(t *testing.T) {
+	r := New(map[string]ShortcodeRenderer{
+		"figure": func(Shortcode, *RenderContext) string { return "<figure></figure>" },
+		"quote":  func(sc Shortcode, _ *RenderContext) string { return "<blockquote>" + sc.Content + "</blockquote>" },
+	})
+	r.SetSpecs(map[string]ShortcodeSpec{"quote": {Block: true}})
+	r.SetStrict(true)
+
+	if _, err := r.Render(`{{figure src="a.jpg"}} {{quote}}Fine{{/quote}}`, nil); err != nil {
+		t.Fatalf("Render() error = %v, want nil for valid shortcodes", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		kind  DiagnosticKind
+	}{
+		{"unknown", `{{unknown}}`, DiagUnknownShortcode},
+		{"unclosed", `{{quote}}Oops`, DiagUnclosedShortcode},
+		{"bad attribute", `{{figure src=a.jpg}}`, DiagBadAttribute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Render(tt.input, nil)
+			var diagErr *DiagnosticError
+			if !errors.As(err, &diagErr) {
+				t.Fatalf("Render() error = %v, want *DiagnosticError", err)
+			}
+			if diagErr.Diagnostics[0].Kind != tt.kind {
+				t.Errorf("Diagnostics[0].Kind = %s, want %s", diagErr.Diagnostics[0].Kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestRenderer_Nested(t *testing.T) {
+	r := New(map[string]ShortcodeRenderer{
+		"quote": func(sc Shortcode, _ *RenderContext) string {
+			return "<blockquote>" + sc.Content + "</blockquote>"
+		},
+		"note": func(sc Shortcode, _ *RenderContext) string {
+			// Escapes its content into an attribute, like sidenote
+			return `<span data-content="` + html.EscapeString(sc.Content) + `"></span>`
+		},
+		"cite": func(sc Shortcode, _ *RenderContext) string {
+			return "<sup>" + sc.Attrs["text"] + "</sup>"
+		},
+	})
+
+	result, err := r.Render(`{{quote}}Claim{{cite text="1"}} {{quote}}Inner{{note}}See{{cite text="2"}}{{/note}}{{/quote}}{{/quote}}`, nil)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"<blockquote>Claim<sup>1</sup> <blockquote>Inner<span",
+		`data-content="See&lt;sup&gt;2&lt;/sup&gt;"`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Render() result missing %q\nGot: %s", want, result)
+		}
+	}
+	if strings.Contains(result, "shortcode:") {
+		t.Errorf("Render() left a placeholder\nGot: %s", result)
+	}
+}
+
+func TestRenderer_TOC(t *testing.T) {
+	r := New(map[string]ShortcodeRenderer{
+		"cite": func(Shortcode, *RenderContext) string { return "<sup>1</sup>" },
+	})
+
+	input := `## Plato's Forms{{cite}}
+
+### The Cave
+
+#### Shadows
+
+### The Line
+
+## Aristotle & ` + "`hylomorphism`" + `
+
+## Aristotle & ` + "`hylomorphism`"
+
+	doc, err := r.RenderDocument(input, nil)
+	if err != nil {
+		t.Fatalf("RenderDocument() error = %v", err)
+	}
+
+	want := []Heading{
+		{Level: 2, ID: "platos-forms", Text: "Plato’s Forms", Children: []Heading{
+			{Level: 3, ID: "the-cave", Text: "The Cave", Children: []Heading{
+				{Level: 4, ID: "shadows", Text: "Shadows"},
+			}},
+			{Level: 3, ID: "the-line", Text: "The Line"},
+		}},
+		{Level: 2, ID: "aristotle--hylomorphism", Text: "Aristotle & hylomorphism"},
+		{Level: 2, ID: "aristotle--hylomorphism-1", Text: "Aristotle & hylomorphism"},
+	}
+
+	var check func(path string, got, want []Heading)
+	check = func(path string, got, want []Heading) {
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d headings %+v, want %d", path, len(got), got, len(want))
+		}
+		for i := range want {
+			g, w := got[i], want[i]
+			if g.Level != w.Level || g.ID != w.ID || g.Text != w.Text {
+				t.Errorf("%s[%d] = {%d %q %q}, want {%d %q %q}", path, i, g.Level, g.ID, g.Text, w.Level, w.ID, w.Text)
+			}
+			check(path+"/"+w.ID, g.Children, w.Children)
+		}
+	}
+	check("TOC", doc.TOC, want)
+
+	if !strings.Contains(doc.HTML, `<h3 id="the-cave">`) {
+		t.Errorf("HTML missing heading IDs\nGot: %s", doc.HTML)
+	}
+}
+
+func TestNestHeadings_SkippedLevel(t *testing.T) {
+	toc := NestHeadings([]Heading{
+		{Level: 3, ID: "a"},
+		{Level: 2, ID: "b"},
+		{Level: 4, ID: "c"},
+	})
+
+	if len(toc) != 2 || toc[0].ID != "a" || toc[1].ID != "b" {
+		t.Fatalf("NestHeadings() = %+v, want [a b]", toc)
+	}
+	if len(toc[1].Children) != 1 || toc[1].Children[0].ID != "c" {
+		t.Errorf("b.Children = %+v, want [c]", toc[1].Children)
+	}
 }
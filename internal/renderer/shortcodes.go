@@ -2,147 +2,404 @@ package renderer
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
 // Shortcode represents a parsed shortcode from markdown content.
 type Shortcode struct {
-	ID      string            // Unique placeholder ID
-	Name    string            // Shortcode name (e.g., "figure", "quote")
-	Attrs   map[string]string // Attributes from the shortcode tag
-	Content string            // Inner content (for block shortcodes)
+	ID       string            // Unique placeholder ID
+	Name     string            // Shortcode name (e.g., "figure", "quote")
+	Attrs    map[string]string // Attributes from the shortcode tag
+	Content  string            // Inner content (for block shortcodes), with placeholders for Children
+	Children []Shortcode       // Shortcodes nested inside a block shortcode
+	Offset   int               // Byte offset of the opening tag in the parsed content
+}
+
+// ShortcodeSpec describes how a shortcode is written.
+type ShortcodeSpec struct {
+	Block    bool     // Takes inner content and requires a closing tag
+	Required []string // Attributes that must be present and non-empty
+}
+
+// DiagnosticKind classifies a Diagnostic.
+type DiagnosticKind string
+
+const (
+	// DiagUnterminatedTag is an opening "{{name" with no closing "}}".
+	DiagUnterminatedTag DiagnosticKind = "unterminated-tag"
+	// DiagBadAttribute is an attribute that isn't written as key="value".
+	DiagBadAttribute DiagnosticKind = "bad-attribute"
+	// DiagUnclosedShortcode is a block shortcode with no closing tag.
+	DiagUnclosedShortcode DiagnosticKind = "unclosed-shortcode"
+	// DiagUnmatchedClose is a closing tag with no opening tag.
+	DiagUnmatchedClose DiagnosticKind = "unmatched-close"
+	// DiagUnknownShortcode is a shortcode with no registered renderer.
+	DiagUnknownShortcode DiagnosticKind = "unknown-shortcode"
+)
+
+// Diagnostic is a problem found while parsing shortcodes.
+type Diagnostic struct {
+	Kind    DiagnosticKind
+	Offset  int // Byte offset in the parsed content
+	Line    int // 1-based line
+	Column  int // 1-based column, in runes
+	Message string
+}
+
+// Error formats the diagnostic as "line:col: message".
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+}
+
+// DiagnosticError is returned by a strict Renderer when content has
+// shortcode diagnostics.
+type DiagnosticError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *DiagnosticError) Error() string {
+	first := e.Diagnostics[0].Error()
+	if n := len(e.Diagnostics) - 1; n > 0 {
+		return fmt.Sprintf("shortcode %s (and %d more)", first, n)
+	}
+	return "shortcode " + first
 }
 
 // ShortcodeParser extracts and replaces shortcodes with placeholders.
 type ShortcodeParser struct {
-	// Opening tag: {{name attr="val"}}
-	openPattern *regexp.Regexp
-	// Closing tag: {{/name}}
-	closePattern *regexp.Regexp
-	// Attribute: key="value" or key='value'
-	attrPattern *regexp.Regexp
+	// specs declares which shortcodes are blocks; may be nil
+	specs map[string]ShortcodeSpec
 }
 
 // NewShortcodeParser creates a new shortcode parser.
 func NewShortcodeParser() *ShortcodeParser {
-	return &ShortcodeParser{
-		openPattern:  regexp.MustCompile(`\{\{([\w-]+)([^}]*)\}\}`),
-		closePattern: regexp.MustCompile(`\{\{/([\w-]+)\}\}`),
-		attrPattern:  regexp.MustCompile(`(\w+)=["']([^"']*)["']`),
-	}
+	return &ShortcodeParser{}
+}
+
+// SetSpecs declares the known shortcodes. A block shortcode without a
+// closing tag is then reported instead of being treated as self-closing.
+func (p *ShortcodeParser) SetSpecs(specs map[string]ShortcodeSpec) {
+	p.specs = specs
 }
 
 // Parse extracts shortcodes from content and replaces them with placeholders.
-// Returns the modified content and a slice of parsed shortcodes.
-func (p *ShortcodeParser) Parse(content string) (string, []Shortcode) {
-	var shortcodes []Shortcode
+// Returns the modified content, the top-level shortcodes (block shortcodes
+// first, then self-closing ones) and any diagnostics. Shortcodes nested
+// inside a block are returned as its Children, and replaced with
+// placeholders in its Content. Malformed tags are left in the content as
+// text.
+func (p *ShortcodeParser) Parse(content string) (string, []Shortcode, []Diagnostic) {
+	tags, diags := p.Tags(content)
 
-	// First, find and process block shortcodes
-	content = p.parseBlockShortcodes(content, &shortcodes)
+	// Build the tree. Every opening tag is pushed as if it were a block;
+	// a closing tag pairs with the nearest open tag of the same name, so
+	// same-name nesting works, and any tags left open above it turn out
+	// to be self-closing.
+	root := &node{}
+	stack := []*node{root}
+	unwind := func(n *node) {
+		if p.specs[n.tag.Name].Block {
+			diags = append(diags, p.diagnostic(content, DiagUnclosedShortcode, n.tag.Start,
+				"%s is missing its closing tag {{/%s}}", n.tag.Name, n.tag.Name))
+		}
+		// Its would-be children are siblings that follow it
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, n.children...)
+		n.children = nil
+	}
 
-	// Then, process remaining self-closing shortcodes
-	content = p.openPattern.ReplaceAllStringFunc(content, func(match string) string {
-		submatches := p.openPattern.FindStringSubmatch(match)
-		if len(submatches) != 3 {
-			return match
+	for _, tag := range tags {
+		if !tag.Closing {
+			n := &node{tag: tag}
+			top := stack[len(stack)-1]
+			top.children = append(top.children, n)
+			stack = append(stack, n)
+			continue
 		}
 
-		sc := Shortcode{
-			ID:    uuid.NewString(),
-			Name:  submatches[1],
-			Attrs: p.parseAttrs(submatches[2]),
+		i := len(stack) - 1
+		for i > 0 && stack[i].tag.Name != tag.Name {
+			i--
 		}
-		shortcodes = append(shortcodes, sc)
-		return fmt.Sprintf("<!--shortcode:%s-->", sc.ID)
-	})
+		if i == 0 {
+			diags = append(diags, p.diagnostic(content, DiagUnmatchedClose, tag.Start,
+				"closing tag {{/%s}} has no opening tag", tag.Name))
+			continue
+		}
+		for len(stack) > i+1 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			unwind(n)
+		}
+		closeTag := tag
+		stack[i].close = &closeTag
+		stack = stack[:i]
+	}
+	for len(stack) > 1 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		unwind(n)
+	}
 
-	return content, shortcodes
+	result, shortcodes := p.replace(content, 0, len(content), root.children)
+
+	// Keep block shortcodes ahead of self-closing ones at the top level
+	var blocks, selfClosing []Shortcode
+	for i, n := range root.children {
+		if n.close != nil {
+			blocks = append(blocks, shortcodes[i])
+		} else {
+			selfClosing = append(selfClosing, shortcodes[i])
+		}
+	}
+
+	sortDiagnostics(diags)
+	return result, append(blocks, selfClosing...), diags
 }
 
-// parseBlockShortcodes finds and extracts block shortcodes (with closing tags).
-func (p *ShortcodeParser) parseBlockShortcodes(content string, shortcodes *[]Shortcode) string {
+// node is a shortcode in the parse tree.
+type node struct {
+	tag      Tag
+	close    *Tag // nil for self-closing shortcodes
+	children []*node
+}
+
+// replace returns content[start:end] with each of nodes replaced by a
+// placeholder, along with the shortcodes for those nodes.
+func (p *ShortcodeParser) replace(content string, start, end int, nodes []*node) (string, []Shortcode) {
 	var sb strings.Builder
 	// Pre-allocate buffer to avoid repeated allocations.
 	// The new content will likely be similar in size or smaller (placeholders are usually smaller than blocks).
-	sb.Grow(len(content))
+	sb.Grow(end - start)
+
+	shortcodes := make([]Shortcode, 0, len(nodes))
+	lastIndex := start
+	for _, n := range nodes {
+		sc := Shortcode{
+			ID:     uuid.NewString(),
+			Name:   n.tag.Name,
+			Attrs:  n.tag.Attrs,
+			Offset: n.tag.Start,
+		}
+		nodeEnd := n.tag.End
+		if n.close != nil {
+			var inner string
+			inner, sc.Children = p.replace(content, n.tag.End, n.close.Start, n.children)
+			sc.Content = strings.TrimSpace(inner)
+			nodeEnd = n.close.End
+		}
+		shortcodes = append(shortcodes, sc)
 
-	offset := 0
-	lastIndex := 0
+		sb.WriteString(content[lastIndex:n.tag.Start])
+		sb.WriteString(placeholder(sc.ID))
+		lastIndex = nodeEnd
+	}
+	sb.WriteString(content[lastIndex:end])
 
-	for {
-		// Find next opening tag from current offset
-		openMatch := p.openPattern.FindStringSubmatchIndex(content[offset:])
-		if openMatch == nil {
+	return sb.String(), shortcodes
+}
+
+// Tag is a shortcode opening or closing tag found in content.
+type Tag struct {
+	Name    string            // Shortcode name
+	Attrs   map[string]string // Attributes (nil for closing tags)
+	Closing bool              // True for {{/name}}
+	Start   int               // Byte offset of the opening "{{"
+	End     int               // Byte offset just past the closing "}}"
+}
+
+// Tags returns every shortcode tag in content in order of appearance,
+// without pairing opening tags with their closing tags, along with
+// diagnostics for malformed tags.
+//
+// Attribute values are quoted with single or double quotes; a backslash
+// escapes the quote character or another backslash. Values may contain
+// "}". Text such as "{{ " that doesn't start with a shortcode name isn't
+// a tag.
+func (p *ShortcodeParser) Tags(content string) ([]Tag, []Diagnostic) {
+	var tags []Tag
+	var diags []Diagnostic
+
+	for offset := 0; ; {
+		i := strings.Index(content[offset:], "{{")
+		if i < 0 {
 			break
 		}
+		start := offset + i
+		offset = start + 2
+
+		s := &tagScanner{content: content, pos: start + 2}
+		closing := s.consume('/')
+		name := s.name()
+		if name == "" {
+			continue
+		}
 
-		// Adjust indices to account for offset
-		for i := range openMatch {
-			openMatch[i] += offset
+		tag := Tag{Name: name, Closing: closing, Start: start}
+		if closing {
+			if !strings.HasPrefix(content[s.pos:], "}}") {
+				continue
+			}
+			tag.End = s.pos + 2
+		} else {
+			tag.Attrs = make(map[string]string)
+			end, ok := s.attrs(tag.Attrs, func(pos int, format string, args ...any) {
+				diags = append(diags, p.diagnostic(content, DiagBadAttribute, pos, format, args...))
+			})
+			if !ok {
+				diags = append(diags, p.diagnostic(content, DiagUnterminatedTag, start,
+					"{{%s is never closed with }}", name))
+				continue
+			}
+			tag.End = end
 		}
 
-		name := content[openMatch[2]:openMatch[3]]
-		attrs := content[openMatch[4]:openMatch[5]]
-		openEnd := openMatch[1]
+		tags = append(tags, tag)
+		offset = tag.End
+	}
+
+	return tags, diags
+}
+
+// tagScanner reads the inside of a single shortcode tag.
+type tagScanner struct {
+	content string
+	pos     int
+}
 
-		// Look for corresponding closing tag
-		closeTag := fmt.Sprintf("{{/%s}}", name)
-		closeStart := strings.Index(content[openEnd:], closeTag)
-		if closeStart == -1 {
-			// No closing tag - this is a self-closing shortcode, skip it
-			offset = openEnd
+func (s *tagScanner) consume(b byte) bool {
+	if s.pos < len(s.content) && s.content[s.pos] == b {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// name reads a run of letters, digits, underscores and hyphens.
+func (s *tagScanner) name() string {
+	start := s.pos
+	for s.pos < len(s.content) && isNameByte(s.content[s.pos]) {
+		s.pos++
+	}
+	return s.content[start:s.pos]
+}
+
+func (s *tagScanner) skipSpace() {
+	for s.pos < len(s.content) && strings.IndexByte(" \t\r\n", s.content[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+// attrs reads attributes up to and including the closing "}}", reporting
+// malformed attributes through report. Returns the offset just past "}}",
+// or false if the content ends first.
+func (s *tagScanner) attrs(attrs map[string]string, report func(pos int, format string, args ...any)) (int, bool) {
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.content) {
+			return 0, false
+		}
+		if strings.HasPrefix(s.content[s.pos:], "}}") {
+			return s.pos + 2, true
+		}
+
+		keyStart := s.pos
+		key := s.name()
+		if key == "" {
+			r, size := utf8.DecodeRuneInString(s.content[s.pos:])
+			report(s.pos, "unexpected %q in shortcode attributes", r)
+			s.pos += size
 			continue
 		}
-		closeStart += openEnd // make absolute
-		closeEnd := closeStart + len(closeTag)
 
-		// Extract the block shortcode
-		innerContent := strings.TrimSpace(content[openEnd:closeStart])
+		s.skipSpace()
+		if !s.consume('=') {
+			report(keyStart, "attribute %q has no value", key)
+			continue
+		}
+		s.skipSpace()
 
-		sc := Shortcode{
-			ID:      uuid.NewString(),
-			Name:    name,
-			Attrs:   p.parseAttrs(attrs),
-			Content: innerContent,
+		if s.pos < len(s.content) && (s.content[s.pos] == '"' || s.content[s.pos] == '\'') {
+			value, ok := s.quoted()
+			if !ok {
+				return 0, false
+			}
+			attrs[key] = value
+			continue
 		}
-		*shortcodes = append(*shortcodes, sc)
 
-		// Append content before the block
-		sb.WriteString(content[lastIndex:openMatch[0]])
+		// Accept an unquoted value up to the next space or "}}", but report it
+		valueStart := s.pos
+		for s.pos < len(s.content) && !strings.HasPrefix(s.content[s.pos:], "}}") &&
+			strings.IndexByte(" \t\r\n", s.content[s.pos]) < 0 {
+			s.pos++
+		}
+		report(keyStart, "value of attribute %q must be quoted", key)
+		attrs[key] = s.content[valueStart:s.pos]
+	}
+}
 
-		// Append the placeholder
-		placeholder := fmt.Sprintf("<!--shortcode:%s-->", sc.ID)
-		sb.WriteString(placeholder)
+// quoted reads a quoted value starting at the opening quote, unescaping
+// \" \' and \\. Returns false if the quote is never closed.
+func (s *tagScanner) quoted() (string, bool) {
+	quote := s.content[s.pos]
+	s.pos++
 
-		// Update indices
-		lastIndex = closeEnd
-		offset = closeEnd
+	var sb strings.Builder
+	for s.pos < len(s.content) {
+		c := s.content[s.pos]
+		switch {
+		case c == quote:
+			s.pos++
+			return sb.String(), true
+		case c == '\\' && s.pos+1 < len(s.content) && strings.IndexByte(`"'\`, s.content[s.pos+1]) >= 0:
+			sb.WriteByte(s.content[s.pos+1])
+			s.pos += 2
+		default:
+			sb.WriteByte(c)
+			s.pos++
+		}
 	}
+	return "", false
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || b == '-' ||
+		('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
 
-	// Append any remaining content
-	if lastIndex < len(content) {
-		sb.WriteString(content[lastIndex:])
+// diagnostic builds a Diagnostic at a byte offset in content.
+func (p *ShortcodeParser) diagnostic(content string, kind DiagnosticKind, offset int, format string, args ...any) Diagnostic {
+	line := strings.Count(content[:offset], "\n") + 1
+	lineStart := strings.LastIndexByte(content[:offset], '\n') + 1
+	return Diagnostic{
+		Kind:    kind,
+		Offset:  offset,
+		Line:    line,
+		Column:  utf8.RuneCountInString(content[lineStart:offset]) + 1,
+		Message: fmt.Sprintf(format, args...),
 	}
+}
 
-	return sb.String()
+func sortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Offset < diags[j].Offset })
 }
 
-func (p *ShortcodeParser) parseAttrs(attrStr string) map[string]string {
-	attrs := make(map[string]string)
-	matches := p.attrPattern.FindAllStringSubmatch(attrStr, -1)
-	for _, m := range matches {
-		if len(m) == 3 {
-			attrs[m[1]] = m[2]
-		}
-	}
-	return attrs
+func placeholder(id string) string {
+	return fmt.Sprintf("<!--shortcode:%s-->", id)
+}
+
+// CountPlaceholders returns the number of shortcode placeholders left in
+// rendered HTML, escaped or not. Any left over belong to unknown shortcodes.
+func CountPlaceholders(html string) int {
+	return strings.Count(html, "<!--shortcode:") + strings.Count(html, "&lt;!--shortcode:")
 }
 
 // ReplacePlaceholder replaces a shortcode placeholder with rendered HTML.
 func ReplacePlaceholder(content, id, html string) string {
-	placeholder := fmt.Sprintf("<!--shortcode:%s-->", id)
-	return strings.Replace(content, placeholder, html, 1)
+	return strings.Replace(content, placeholder(id), html, 1)
 }
@@ -8,7 +8,7 @@ import (
 func TestShortcodeParser_TwoBlocks(t *testing.T) {
 	p := NewShortcodeParser()
 	input := `{{A}}Content A{{/A}} Middle {{B}}Content B{{/B}}`
-	content, shortcodes := p.Parse(input)
+	content, shortcodes, _ := p.Parse(input)
 
 	if len(shortcodes) != 2 {
 		t.Fatalf("Expected 2 shortcodes, got %d", len(shortcodes))
@@ -22,3 +22,170 @@ func TestShortcodeParser_TwoBlocks(t *testing.T) {
 		t.Errorf("Expected ' Middle ' to be preserved, got: %s", content)
 	}
 }
+
+func TestShortcodeParser_Tags(t *testing.T) {
+	p := NewShortcodeParser()
+	input := `Intro {{quote author="Plato"}}Text{{cite alias="rep"}}{{/quote}}`
+	tags, _ := p.Tags(input)
+
+	if len(tags) != 3 {
+		t.Fatalf("Expected 3 tags, got %d", len(tags))
+	}
+
+	want := []struct {
+		name    string
+		closing bool
+		start   int
+	}{
+		{"quote", false, 6},
+		{"cite", false, 34},
+		{"quote", true, 54},
+	}
+	for i, w := range want {
+		tag := tags[i]
+		if tag.Name != w.name || tag.Closing != w.closing || tag.Start != w.start {
+			t.Errorf("tags[%d] = {%s closing=%v start=%d}, want {%s closing=%v start=%d}",
+				i, tag.Name, tag.Closing, tag.Start, w.name, w.closing, w.start)
+		}
+	}
+
+	if tags[0].Attrs["author"] != "Plato" {
+		t.Errorf("tags[0].Attrs[author] = %q, want %q", tags[0].Attrs["author"], "Plato")
+	}
+	if got := input[tags[1].Start:tags[1].End]; got != `{{cite alias="rep"}}` {
+		t.Errorf("tags[1] spans %q", got)
+	}
+}
+
+func TestShortcodeParser_AttributeQuoting(t *testing.T) {
+	p := NewShortcodeParser()
+
+	tests := []struct {
+		name  string
+		input string
+		attr  string
+		want  string
+	}{
+		{"apostrophe in double quotes", `{{figure caption="what is 'redness'?"}}`, "caption", "what is 'redness'?"},
+		{"escaped double quote", `{{figure caption="the \"good\" life"}}`, "caption", `the "good" life`},
+		{"escaped single quote", `{{figure caption='Plato\'s cave'}}`, "caption", "Plato's cave"},
+		{"escaped backslash", `{{figure caption="a\\b"}}`, "caption", `a\b`},
+		{"other backslashes kept", `{{figure caption="C:\path"}}`, "caption", `C:\path`},
+		{"closing brace in value", `{{figure caption="set {x}}"}}`, "caption", "set {x}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, shortcodes, diags := p.Parse(tt.input)
+			if len(diags) != 0 {
+				t.Errorf("Parse() diagnostics = %v, want none", diags)
+			}
+			if len(shortcodes) != 1 {
+				t.Fatalf("Parse() returned %d shortcodes, want 1", len(shortcodes))
+			}
+			if got := shortcodes[0].Attrs[tt.attr]; got != tt.want {
+				t.Errorf("Attrs[%s] = %q, want %q", tt.attr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortcodeParser_Diagnostics(t *testing.T) {
+	p := NewShortcodeParser()
+	p.SetSpecs(map[string]ShortcodeSpec{"quote": {Block: true}})
+
+	tests := []struct {
+		name   string
+		input  string
+		kind   DiagnosticKind
+		line   int
+		column int
+	}{
+		{"unquoted value", "Text\n{{figure src=a.jpg}}", DiagBadAttribute, 2, 10},
+		{"missing value", "{{figure src}}", DiagBadAttribute, 1, 10},
+		{"unterminated tag", "Before {{figure src=\"a.jpg\"", DiagUnterminatedTag, 1, 8},
+		{"unterminated value", "{{figure src=\"a.jpg}}", DiagUnterminatedTag, 1, 1},
+		{"unclosed block", "Intro\n\n  {{quote}}Never closed", DiagUnclosedShortcode, 3, 3},
+		{"unmatched close", "λόγος {{/quote}}", DiagUnmatchedClose, 1, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, diags := p.Parse(tt.input)
+			if len(diags) != 1 {
+				t.Fatalf("Parse() diagnostics = %v, want 1", diags)
+			}
+			d := diags[0]
+			if d.Kind != tt.kind || d.Line != tt.line || d.Column != tt.column {
+				t.Errorf("diagnostic = %s %d:%d, want %s %d:%d", d.Kind, d.Line, d.Column, tt.kind, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestShortcodeParser_NotATag(t *testing.T) {
+	p := NewShortcodeParser()
+
+	input := "Templates use {{ .Title }} and {{/ }} syntax."
+	content, shortcodes, diags := p.Parse(input)
+	if len(shortcodes) != 0 || len(diags) != 0 {
+		t.Errorf("Parse() = %d shortcodes, %v, want none", len(shortcodes), diags)
+	}
+	if content != input {
+		t.Errorf("content = %q, want unchanged", content)
+	}
+}
+
+func TestShortcodeParser_Nested(t *testing.T) {
+	p := NewShortcodeParser()
+	p.SetSpecs(map[string]ShortcodeSpec{"quote": {Block: true}, "sidenote": {Block: true}})
+
+	input := `{{quote author="Outer"}}Before{{cite alias="a"}} {{quote author="Inner"}}Deep{{sidenote id="n"}}Note{{/sidenote}}{{/quote}} after{{/quote}}`
+	content, shortcodes, diags := p.Parse(input)
+
+	if len(diags) != 0 {
+		t.Errorf("Parse() diagnostics = %v, want none", diags)
+	}
+	if len(shortcodes) != 1 || strings.Count(content, "<!--shortcode:") != 1 {
+		t.Fatalf("Parse() = %d top-level shortcodes, content %q, want 1", len(shortcodes), content)
+	}
+
+	outer := shortcodes[0]
+	if outer.Attrs["author"] != "Outer" || len(outer.Children) != 2 {
+		t.Fatalf("outer = %s with %d children, want Outer with 2", outer.Attrs["author"], len(outer.Children))
+	}
+	if strings.Contains(outer.Content, "{{") {
+		t.Errorf("outer.Content = %q, want nested shortcodes replaced", outer.Content)
+	}
+	for _, child := range outer.Children {
+		if !strings.Contains(outer.Content, "<!--shortcode:"+child.ID+"-->") {
+			t.Errorf("outer.Content missing placeholder for %s", child.Name)
+		}
+	}
+
+	cite, inner := outer.Children[0], outer.Children[1]
+	if cite.Name != "cite" || cite.Attrs["alias"] != "a" {
+		t.Errorf("Children[0] = %s %v, want cite alias=a", cite.Name, cite.Attrs)
+	}
+	if inner.Name != "quote" || inner.Attrs["author"] != "Inner" {
+		t.Errorf("Children[1] = %s %v, want quote author=Inner", inner.Name, inner.Attrs)
+	}
+	if len(inner.Children) != 1 || inner.Children[0].Content != "Note" {
+		t.Errorf("inner.Children = %+v, want one sidenote with content Note", inner.Children)
+	}
+}
+
+func TestShortcodeParser_UnclosedInsideBlock(t *testing.T) {
+	p := NewShortcodeParser()
+	p.SetSpecs(map[string]ShortcodeSpec{"quote": {Block: true}, "term": {Block: true}})
+
+	_, shortcodes, diags := p.Parse(`{{quote}}A {{term word="x"}}never closed {{figure src="a"}}{{/quote}}`)
+
+	if len(diags) != 1 || diags[0].Kind != DiagUnclosedShortcode || diags[0].Column != 12 {
+		t.Errorf("Parse() diagnostics = %v, want unclosed term at column 12", diags)
+	}
+	// The unclosed term is self-closing, and the figure after it is its sibling
+	if len(shortcodes) != 1 || len(shortcodes[0].Children) != 2 {
+		t.Fatalf("Parse() = %+v, want quote with 2 children", shortcodes)
+	}
+}
@@ -0,0 +1,28 @@
+package renderer
+
+// Heading is an entry in a post's table of contents.
+type Heading struct {
+	Level    int       // 1-6, from <h1> to <h6>
+	ID       string    // Anchor ID generated by Goldmark
+	Text     string    // Plain text of the heading
+	Children []Heading // Subheadings up to the next heading of this level or higher
+}
+
+// NestHeadings turns a flat list of headings into a tree, where each
+// heading's children are the deeper headings that follow it. A document
+// that skips a level (an h4 straight after an h2) nests the h4 directly
+// under the h2.
+func NestHeadings(flat []Heading) []Heading {
+	var tree []Heading
+	for i := 0; i < len(flat); {
+		h := flat[i]
+		j := i + 1
+		for j < len(flat) && flat[j].Level > h.Level {
+			j++
+		}
+		h.Children = NestHeadings(flat[i+1 : j])
+		tree = append(tree, h)
+		i = j
+	}
+	return tree
+}
@@ -5,13 +5,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"therefore/internal/content"
+	"therefore/internal/feed"
+	"therefore/internal/renderer"
 	"therefore/internal/views"
 )
 
@@ -71,9 +75,19 @@ func (g *Generator) Generate(ctx context.Context) error {
 		return fmt.Errorf("generating tags pages: %w", err)
 	}
 
-	// Generate series page
-	if err := g.generateSeriesPage(ctx); err != nil {
-		return fmt.Errorf("generating series page: %w", err)
+	// Generate series pages
+	if err := g.generateSeriesPages(ctx); err != nil {
+		return fmt.Errorf("generating series pages: %w", err)
+	}
+
+	// Generate author pages
+	if err := g.generateAuthorPages(ctx); err != nil {
+		return fmt.Errorf("generating author pages: %w", err)
+	}
+
+	// Generate archive pages
+	if err := g.generateArchivePages(ctx); err != nil {
+		return fmt.Errorf("generating archive pages: %w", err)
 	}
 
 	// Generate about page
@@ -81,6 +95,11 @@ func (g *Generator) Generate(ctx context.Context) error {
 		return fmt.Errorf("generating about page: %w", err)
 	}
 
+	// Generate RSS and Atom feeds
+	if err := g.generateFeeds(ctx); err != nil {
+		return fmt.Errorf("generating feeds: %w", err)
+	}
+
 	slog.Info("SSG generation complete")
 	return nil
 }
@@ -93,8 +112,13 @@ func (g *Generator) parseViteAssets() error {
 		return fmt.Errorf("reading index.html: %w", err)
 	}
 
-	html := string(data)
+	g.parseAssets(string(data))
+	return nil
+}
 
+// parseAssets extracts the CSS and JS references from an index.html, either
+// Vite's own or one generated from it.
+func (g *Generator) parseAssets(html string) {
 	// Extract CSS links: <link rel="stylesheet" ... href="/assets/...">
 	cssMatches := cssRegex.FindAllStringSubmatch(html, -1)
 	for _, match := range cssMatches {
@@ -126,11 +150,15 @@ func (g *Generator) parseViteAssets() error {
 	}
 
 	slog.Debug("Parsed Vite assets", "css", g.cssLinks, "js", g.jsEntry)
-	return nil
 }
 
 func (g *Generator) generateSplashPage(_ context.Context) error {
-	pageData := views.SSGPageData{
+	// Splash page replaces the default index.html
+	return g.writePage("index.html", g.splashPage())
+}
+
+func (g *Generator) splashPage() views.SSGPageData {
+	return views.SSGPageData{
 		Title:       "Therefore",
 		Description: "A blog exploring ideas at the intersection of philosophy and theology.",
 		URL:         g.baseURL,
@@ -140,18 +168,23 @@ func (g *Generator) generateSplashPage(_ context.Context) error {
 		JSEntry:     g.jsEntry,
 		BaseURL:     g.baseURL,
 	}
-
-	// Splash page replaces the default index.html
-	return g.writePage("index.html", pageData)
 }
 
 func (g *Generator) generateHomePage(ctx context.Context) error {
+	pageData, err := g.homePage(ctx)
+	if err != nil {
+		return err
+	}
+	return g.writePage("posts/index.html", pageData)
+}
+
+func (g *Generator) homePage(ctx context.Context) (views.SSGPageData, error) {
 	posts, _, err := g.store.ListPosts(ctx, content.ListOptions{Limit: 10})
 	if err != nil {
-		return fmt.Errorf("listing posts: %w", err)
+		return views.SSGPageData{}, fmt.Errorf("listing posts: %w", err)
 	}
 
-	pageData := views.SSGPageData{
+	return views.SSGPageData{
 		Title:       "Latest Posts — Therefore",
 		Description: "Browse the latest posts on Therefore.",
 		URL:         g.baseURL + "/posts",
@@ -164,9 +197,7 @@ func (g *Generator) generateHomePage(ctx context.Context) error {
 		CSSLinks: g.cssLinks,
 		JSEntry:  g.jsEntry,
 		BaseURL:  g.baseURL,
-	}
-
-	return g.writePage("posts/index.html", pageData)
+	}, nil
 }
 
 func (g *Generator) generatePostPages(ctx context.Context) error {
@@ -176,7 +207,11 @@ func (g *Generator) generatePostPages(ctx context.Context) error {
 	}
 
 	for _, post := range posts {
-		if err := g.generatePostPage(ctx, post); err != nil {
+		pageData, err := g.postPage(ctx, post)
+		if err != nil {
+			return fmt.Errorf("generating post %s: %w", post.Meta.Slug, err)
+		}
+		if err := g.writePage(fmt.Sprintf("posts/%s.html", post.Meta.Slug), pageData); err != nil {
 			return fmt.Errorf("generating post %s: %w", post.Meta.Slug, err)
 		}
 	}
@@ -185,7 +220,20 @@ func (g *Generator) generatePostPages(ctx context.Context) error {
 	return nil
 }
 
-func (g *Generator) generatePostPage(_ context.Context, post *content.Post) error {
+func (g *Generator) postPage(ctx context.Context, post *content.Post) (views.SSGPageData, error) {
+	related, err := g.store.GetRelated(ctx, post.Meta.Slug)
+	if err != nil {
+		return views.SSGPageData{}, fmt.Errorf("getting related posts: %w", err)
+	}
+
+	var series *content.Series
+	if post.Meta.Series != "" {
+		series, err = g.store.GetSeriesByName(ctx, post.Meta.Series)
+		if err != nil {
+			return views.SSGPageData{}, fmt.Errorf("getting series: %w", err)
+		}
+	}
+
 	// Render article HTML
 	articleHTML := views.RenderToString(views.Article(post, post.HTMLContent))
 
@@ -195,16 +243,19 @@ func (g *Generator) generatePostPage(_ context.Context, post *content.Post) erro
 		URL:         g.baseURL + "/posts/" + post.Meta.Slug,
 		OGType:      "article",
 		PublishedAt: post.Meta.PublishDate.Format("2006-01-02T15:04:05Z07:00"),
-		PageContent: views.SSGLayout(views.SSGPostPage(post, articleHTML)),
+		PageContent: views.SSGLayout(views.SSGPostPage(post, articleHTML, series, related)),
 		SSGData: map[string]any{
-			"post": postToJSON(post),
+			"post": postToJSON(post, series, related),
 		},
 		CSSLinks: g.cssLinks,
 		JSEntry:  g.jsEntry,
 		BaseURL:  g.baseURL,
 	}
+	if post.Meta.Revised() {
+		pageData.ModifiedAt = post.Meta.LastModified().Format("2006-01-02T15:04:05Z07:00")
+	}
 
-	return g.writePage(fmt.Sprintf("posts/%s.html", post.Meta.Slug), pageData)
+	return pageData, nil
 }
 
 func (g *Generator) generateTagsPages(ctx context.Context) error {
@@ -214,24 +265,17 @@ func (g *Generator) generateTagsPages(ctx context.Context) error {
 	}
 
 	// Tags list page
-	pageData := views.SSGPageData{
-		Title:       "Tags — Therefore",
-		Description: "Browse all tags on Therefore.",
-		URL:         g.baseURL + "/tags",
-		OGType:      "website",
-		PageContent: views.SSGLayout(views.SSGTagsPage(tags)),
-		CSSLinks:    g.cssLinks,
-		JSEntry:     g.jsEntry,
-		BaseURL:     g.baseURL,
-	}
-
-	if err := g.writePage("tags/index.html", pageData); err != nil {
+	if err := g.writePage("tags/index.html", g.tagsPage(tags)); err != nil {
 		return err
 	}
 
 	// Individual tag pages
 	for _, tag := range tags {
-		if err := g.generateTagPage(ctx, tag.Tag); err != nil {
+		pageData, err := g.tagPage(ctx, tag.Tag)
+		if err != nil {
+			return fmt.Errorf("generating tag page %s: %w", tag.Tag, err)
+		}
+		if err := g.writePage(fmt.Sprintf("tags/%s.html", tag.Tag), pageData); err != nil {
 			return fmt.Errorf("generating tag page %s: %w", tag.Tag, err)
 		}
 	}
@@ -240,13 +284,31 @@ func (g *Generator) generateTagsPages(ctx context.Context) error {
 	return nil
 }
 
-func (g *Generator) generateTagPage(ctx context.Context, tag string) error {
+func (g *Generator) tagsPage(tags []content.TagCount) views.SSGPageData {
+	return views.SSGPageData{
+		Title:       "Tags — Therefore",
+		Description: "Browse all tags on Therefore.",
+		URL:         g.baseURL + "/tags",
+		OGType:      "website",
+		PageContent: views.SSGLayout(views.SSGTagsPage(tags)),
+		CSSLinks:    g.cssLinks,
+		JSEntry:     g.jsEntry,
+		BaseURL:     g.baseURL,
+	}
+}
+
+// tagPage returns the page for a tag, or ErrNotFound if no published post
+// has it.
+func (g *Generator) tagPage(ctx context.Context, tag string) (views.SSGPageData, error) {
 	posts, total, err := g.store.ListPosts(ctx, content.ListOptions{Tag: tag, Limit: 6})
 	if err != nil {
-		return fmt.Errorf("listing posts for tag: %w", err)
+		return views.SSGPageData{}, fmt.Errorf("listing posts for tag: %w", err)
+	}
+	if total == 0 {
+		return views.SSGPageData{}, ErrNotFound
 	}
 
-	pageData := views.SSGPageData{
+	return views.SSGPageData{
 		Title:       fmt.Sprintf("Posts tagged \"%s\" — Therefore", tag),
 		Description: fmt.Sprintf("All posts tagged \"%s\" on Therefore.", tag),
 		URL:         g.baseURL + "/tags/" + tag,
@@ -260,18 +322,35 @@ func (g *Generator) generateTagPage(ctx context.Context, tag string) error {
 		CSSLinks: g.cssLinks,
 		JSEntry:  g.jsEntry,
 		BaseURL:  g.baseURL,
-	}
-
-	return g.writePage(fmt.Sprintf("tags/%s.html", tag), pageData)
+	}, nil
 }
 
-func (g *Generator) generateSeriesPage(ctx context.Context) error {
+func (g *Generator) generateSeriesPages(ctx context.Context) error {
 	series, err := g.store.GetSeries(ctx)
 	if err != nil {
 		return fmt.Errorf("listing series: %w", err)
 	}
 
-	pageData := views.SSGPageData{
+	if err := g.writePage("series/index.html", g.seriesPage(series)); err != nil {
+		return err
+	}
+
+	for _, s := range series {
+		pageData, err := g.seriesDetailPage(ctx, s.Series)
+		if err != nil {
+			return fmt.Errorf("generating series %s: %w", s.Series, err)
+		}
+		if err := g.writePage(fmt.Sprintf("series/%s.html", s.Series), pageData); err != nil {
+			return fmt.Errorf("generating series %s: %w", s.Series, err)
+		}
+	}
+
+	slog.Info("Generated series pages", "count", len(series)+1)
+	return nil
+}
+
+func (g *Generator) seriesPage(series []content.SeriesCount) views.SSGPageData {
+	return views.SSGPageData{
 		Title:       "Series — Therefore",
 		Description: "Browse all series on Therefore.",
 		URL:         g.baseURL + "/series",
@@ -281,17 +360,193 @@ func (g *Generator) generateSeriesPage(ctx context.Context) error {
 		JSEntry:     g.jsEntry,
 		BaseURL:     g.baseURL,
 	}
+}
+
+func (g *Generator) seriesDetailPage(ctx context.Context, name string) (views.SSGPageData, error) {
+	series, err := g.store.GetSeriesByName(ctx, name)
+	if err != nil {
+		return views.SSGPageData{}, fmt.Errorf("getting series: %w", err)
+	}
+
+	description := series.Info.Description
+	if description == "" {
+		description = fmt.Sprintf("All posts in the \"%s\" series on Therefore.", series.Info.Title)
+	}
+
+	posts := postsToJSON(series.Posts)
+	for i := range posts {
+		posts[i]["part"] = i + 1
+	}
+
+	return views.SSGPageData{
+		Title:       series.Info.Title + " — Therefore",
+		Description: description,
+		URL:         g.baseURL + "/series/" + url.PathEscape(name),
+		OGType:      "website",
+		PageContent: views.SSGLayout(views.SSGSeriesDetailPage(series)),
+		SSGData: map[string]any{
+			"series": seriesToJSON(series, posts),
+		},
+		CSSLinks: g.cssLinks,
+		JSEntry:  g.jsEntry,
+		BaseURL:  g.baseURL,
+	}, nil
+}
+
+func (g *Generator) generateAuthorPages(ctx context.Context) error {
+	authors, err := g.store.GetAuthors(ctx)
+	if err != nil {
+		return fmt.Errorf("listing authors: %w", err)
+	}
 
-	if err := g.writePage("series/index.html", pageData); err != nil {
+	if err := g.writePage("authors/index.html", g.authorsPage(authors)); err != nil {
 		return err
 	}
 
-	slog.Info("Generated series page")
+	for _, a := range authors {
+		pageData, err := g.authorPage(ctx, a.Author)
+		if err != nil {
+			return fmt.Errorf("generating author %s: %w", a.Author.ID, err)
+		}
+		if err := g.writePage(fmt.Sprintf("authors/%s.html", a.Author.ID), pageData); err != nil {
+			return fmt.Errorf("generating author %s: %w", a.Author.ID, err)
+		}
+	}
+
+	slog.Info("Generated author pages", "count", len(authors)+1)
 	return nil
 }
 
+func (g *Generator) authorsPage(authors []content.AuthorCount) views.SSGPageData {
+	return views.SSGPageData{
+		Title:       "Authors — Therefore",
+		Description: "Meet the authors writing on Therefore.",
+		URL:         g.baseURL + "/authors",
+		OGType:      "website",
+		PageContent: views.SSGLayout(views.SSGAuthorsPage(authors)),
+		CSSLinks:    g.cssLinks,
+		JSEntry:     g.jsEntry,
+		BaseURL:     g.baseURL,
+	}
+}
+
+func (g *Generator) authorPage(ctx context.Context, author content.Author) (views.SSGPageData, error) {
+	posts, total, err := g.store.ListPosts(ctx, content.ListOptions{Author: author.ID})
+	if err != nil {
+		return views.SSGPageData{}, fmt.Errorf("listing posts for author: %w", err)
+	}
+
+	description := author.Bio
+	if description == "" {
+		description = fmt.Sprintf("All posts by %s on Therefore.", author.Name)
+	}
+
+	data := authorToJSON(author)
+	data["posts"] = postsToJSON(posts)
+	data["total"] = total
+
+	return views.SSGPageData{
+		Title:       author.Name + " — Therefore",
+		Description: description,
+		URL:         g.baseURL + "/authors/" + author.ID,
+		OGType:      "profile",
+		PageContent: views.SSGLayout(views.SSGAuthorPage(author, posts, total)),
+		SSGData: map[string]any{
+			"author": data,
+		},
+		CSSLinks: g.cssLinks,
+		JSEntry:  g.jsEntry,
+		BaseURL:  g.baseURL,
+	}, nil
+}
+
+func (g *Generator) generateArchivePages(ctx context.Context) error {
+	archive, err := g.store.GetArchive(ctx)
+	if err != nil {
+		return fmt.Errorf("listing archive: %w", err)
+	}
+
+	if err := g.writePage("archive/index.html", g.archivePage(archive)); err != nil {
+		return err
+	}
+
+	count := 1
+	for _, y := range archive {
+		months := []time.Month{0}
+		for _, m := range y.Months {
+			months = append(months, m.Month)
+		}
+		for _, month := range months {
+			pageData, err := g.archivePeriodPage(ctx, y.Year, month)
+			if err != nil {
+				return fmt.Errorf("generating archive %s: %w", views.ArchiveTitle(y.Year, month), err)
+			}
+			if err := g.writePage(strings.TrimPrefix(views.ArchiveURL(y.Year, month), "/")+".html", pageData); err != nil {
+				return fmt.Errorf("generating archive %s: %w", views.ArchiveTitle(y.Year, month), err)
+			}
+			count++
+		}
+	}
+
+	slog.Info("Generated archive pages", "count", count)
+	return nil
+}
+
+func (g *Generator) archivePage(archive []content.ArchiveYear) views.SSGPageData {
+	return views.SSGPageData{
+		Title:       "Archive — Therefore",
+		Description: "Browse every post on Therefore by year and month.",
+		URL:         g.baseURL + "/archive",
+		OGType:      "website",
+		PageContent: views.SSGLayout(views.SSGArchivePage(archive)),
+		CSSLinks:    g.cssLinks,
+		JSEntry:     g.jsEntry,
+		BaseURL:     g.baseURL,
+	}
+}
+
+// archivePeriodPage returns the page for a year, or a month of it if month
+// is set, or ErrNotFound if no published post is from then.
+func (g *Generator) archivePeriodPage(ctx context.Context, year int, month time.Month) (views.SSGPageData, error) {
+	posts, total, err := g.store.ListPosts(ctx, content.ListOptions{Year: year, Month: month})
+	if err != nil {
+		return views.SSGPageData{}, fmt.Errorf("listing posts for archive: %w", err)
+	}
+	if total == 0 {
+		return views.SSGPageData{}, ErrNotFound
+	}
+
+	title := views.ArchiveTitle(year, month)
+	archive := map[string]any{
+		"year":  year,
+		"posts": postsToJSON(posts),
+		"total": total,
+	}
+	if month != 0 {
+		archive["month"] = int(month)
+	}
+
+	return views.SSGPageData{
+		Title:       title + " — Therefore",
+		Description: fmt.Sprintf("All posts from %s on Therefore.", title),
+		URL:         g.baseURL + views.ArchiveURL(year, month),
+		OGType:      "website",
+		PageContent: views.SSGLayout(views.SSGArchivePeriodPage(year, month, posts, total)),
+		SSGData: map[string]any{
+			"archive": archive,
+		},
+		CSSLinks: g.cssLinks,
+		JSEntry:  g.jsEntry,
+		BaseURL:  g.baseURL,
+	}, nil
+}
+
 func (g *Generator) generateAboutPage(_ context.Context) error {
-	pageData := views.SSGPageData{
+	return g.writePage("about/index.html", g.aboutPage())
+}
+
+func (g *Generator) aboutPage() views.SSGPageData {
+	return views.SSGPageData{
 		Title:       "About — Therefore",
 		Description: "About Therefore — a blog exploring philosophy and theology.",
 		URL:         g.baseURL + "/about",
@@ -301,8 +556,60 @@ func (g *Generator) generateAboutPage(_ context.Context) error {
 		JSEntry:     g.jsEntry,
 		BaseURL:     g.baseURL,
 	}
+}
 
-	return g.writePage("about/index.html", pageData)
+func (g *Generator) generateFeeds(ctx context.Context) error {
+	builder := feed.NewBuilder(g.store, g.baseURL)
+
+	site, err := builder.Site(ctx)
+	if err != nil {
+		return err
+	}
+	feeds := []*feed.Feed{site}
+
+	tags, err := g.store.GetTags(ctx)
+	if err != nil {
+		return fmt.Errorf("listing tags: %w", err)
+	}
+	for _, tag := range tags {
+		f, err := builder.Tag(ctx, tag.Tag)
+		if err != nil {
+			return fmt.Errorf("building feed for tag %s: %w", tag.Tag, err)
+		}
+		feeds = append(feeds, f)
+	}
+
+	series, err := g.store.GetSeries(ctx)
+	if err != nil {
+		return fmt.Errorf("listing series: %w", err)
+	}
+	for _, s := range series {
+		f, err := builder.Series(ctx, s.Series)
+		if err != nil {
+			return fmt.Errorf("building feed for series %s: %w", s.Series, err)
+		}
+		feeds = append(feeds, f)
+	}
+
+	for _, f := range feeds {
+		for _, format := range []feed.Format{feed.FormatRSS, feed.FormatAtom} {
+			data, err := f.Encode(format)
+			if err != nil {
+				return err
+			}
+			// Feed paths are URL-escaped; files are written under the decoded name
+			dir, err := url.PathUnescape(strings.TrimPrefix(f.Path, "/"))
+			if err != nil {
+				return fmt.Errorf("decoding feed path %s: %w", f.Path, err)
+			}
+			if err := g.writeFile(filepath.Join(dir, format.Filename()), data); err != nil {
+				return err
+			}
+		}
+	}
+
+	slog.Info("Generated feeds", "count", len(feeds)*2)
+	return nil
 }
 
 func (g *Generator) writePage(relPath string, data views.SSGPageData) error {
@@ -326,10 +633,26 @@ func (g *Generator) writePage(relPath string, data views.SSGPageData) error {
 	return nil
 }
 
+// writeFile writes raw data to relPath under the output directory.
+func (g *Generator) writeFile(relPath string, data []byte) error {
+	fullPath := filepath.Join(g.outDir, relPath)
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+
+	slog.Debug("Generated file", "path", relPath)
+	return nil
+}
+
 // Helper functions
 
 // postToJSON converts a Post to a JSON-serializable map matching the API response.
-func postToJSON(p *content.Post) map[string]any {
+func postToJSON(p *content.Post, series *content.Series, related []*content.Post) map[string]any {
 	m := map[string]any{
 		"slug":        p.Meta.Slug,
 		"title":       p.Meta.Title,
@@ -343,15 +666,104 @@ func postToJSON(p *content.Post) map[string]any {
 		m["series"] = p.Meta.Series
 	}
 	if p.Meta.Author.Name != "" {
-		m["author"] = map[string]string{
-			"name":   p.Meta.Author.Name,
-			"avatar": p.Meta.Author.Avatar,
-			"bio":    p.Meta.Author.Bio,
+		m["author"] = authorToJSON(p.Meta.Author)
+	}
+	if len(p.Meta.Authors) > 0 {
+		authors := make([]map[string]any, len(p.Meta.Authors))
+		for i, a := range p.Meta.Authors {
+			authors[i] = authorToJSON(a)
+		}
+		m["authors"] = authors
+	}
+	if p.Meta.Revised() {
+		m["updatedDate"] = p.Meta.LastModified().Format("2006-01-02T15:04:05Z07:00")
+	}
+	if len(p.Meta.Changelog) > 0 {
+		changelog := make([]map[string]string, len(p.Meta.Changelog))
+		for i, entry := range p.Meta.Changelog {
+			changelog[i] = map[string]string{
+				"date": entry.Date.Format("2006-01-02T15:04:05Z07:00"),
+				"note": entry.Note,
+			}
 		}
+		m["changelog"] = changelog
+	}
+	if len(p.TOC) > 0 {
+		m["toc"] = tocToJSON(p.TOC)
+	}
+	if len(related) > 0 {
+		m["related"] = postsToJSON(related)
+	}
+	if series != nil {
+		if part := series.Part(p.Meta.Slug); part > 0 {
+			nav := map[string]any{"title": series.Info.Title, "part": part, "total": len(series.Posts)}
+			if part > 1 {
+				nav["prev"] = postLinkToJSON(series.Posts[part-2])
+			}
+			if part < len(series.Posts) {
+				nav["next"] = postLinkToJSON(series.Posts[part])
+			}
+			m["seriesNav"] = nav
+		}
+	}
+	return m
+}
+
+// authorToJSON converts an Author to a JSON-serializable map matching the
+// API response, omitting empty fields.
+func authorToJSON(a content.Author) map[string]any {
+	m := map[string]any{"name": a.Name}
+	if a.ID != "" {
+		m["id"] = a.ID
+	}
+	if a.Avatar != "" {
+		m["avatar"] = a.Avatar
+	}
+	if a.Bio != "" {
+		m["bio"] = a.Bio
+	}
+	if a.URL != "" {
+		m["url"] = a.URL
+	}
+	return m
+}
+
+func postLinkToJSON(p *content.Post) map[string]string {
+	return map[string]string{"slug": p.Meta.Slug, "title": p.Meta.Title}
+}
+
+// seriesToJSON converts a Series to a JSON-serializable map matching the API
+// response, with posts already converted.
+func seriesToJSON(s *content.Series, posts []map[string]any) map[string]any {
+	m := map[string]any{
+		"series": s.Name,
+		"title":  s.Info.Title,
+		"posts":  posts,
+	}
+	if s.Info.Description != "" {
+		m["description"] = s.Info.Description
+	}
+	if s.Info.Cover != "" {
+		m["cover"] = s.Info.Cover
 	}
 	return m
 }
 
+func tocToJSON(headings []renderer.Heading) []map[string]any {
+	result := make([]map[string]any, len(headings))
+	for i, h := range headings {
+		result[i] = map[string]any{
+			"level": h.Level,
+			"id":    h.ID,
+			"text":  h.Text,
+		}
+		if len(h.Children) > 0 {
+			result[i]["children"] = tocToJSON(h.Children)
+		}
+	}
+	return result
+}
+
 func postsToJSON(posts []*content.Post) []map[string]any {
 	result := make([]map[string]any, len(posts))
 	for i, p := range posts {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestParseViteAssets(t *testing.T) {
@@ -85,3 +86,29 @@ func BenchmarkParseViteAssets(b *testing.B) {
 		}
 	}
 }
+
+func TestParseArchivePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		year  int
+		month time.Month
+		ok    bool
+	}{
+		{"2024", 2024, 0, true},
+		{"2024/03", 2024, time.March, true},
+		{"2024/12", 2024, time.December, true},
+		{"2024/3", 0, 0, false},
+		{"2024/13", 0, 0, false},
+		{"2024/00", 0, 0, false},
+		{"02024", 0, 0, false},
+		{"2024/03/01", 0, 0, false},
+		{"latest", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		year, month, ok := parseArchivePath(tt.path)
+		if year != tt.year || month != tt.month || ok != tt.ok {
+			t.Errorf("parseArchivePath(%q) = %d, %d, %v, want %d, %d, %v", tt.path, year, month, ok, tt.year, tt.month, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package ssg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"therefore/internal/content"
+	"therefore/internal/views"
+)
+
+// ErrNotFound is returned by Pages.Render for paths that aren't a route,
+// or name a post, tag, series, author or archive period the store doesn't
+// have.
+var ErrNotFound = errors.New("page not found")
+
+// Page is a rendered HTML document.
+type Page struct {
+	HTML    []byte
+	Version content.Version // ETag of the HTML; Modified from the store
+}
+
+// Pages renders the same pages as Generate on demand, so the server can
+// send full HTML for content that was added after the dist directory was
+// built. Rendered pages are cached until the store's version changes.
+type Pages struct {
+	gen   *Generator
+	store content.ContentStore
+
+	mu      sync.Mutex
+	version content.Version // store version the cache was filled at
+	cache   map[string]Page // keyed by route, without slashes at either end
+}
+
+// NewPages creates Pages that link the CSS and JS assets referenced by
+// indexHTML, Vite's built index.html or a page generated from it.
+func NewPages(store content.ContentStore, baseURL string, indexHTML []byte) *Pages {
+	gen := New(store, baseURL, "")
+	gen.parseAssets(string(indexHTML))
+	return &Pages{
+		gen:   gen,
+		store: store,
+		cache: make(map[string]Page),
+	}
+}
+
+// Render returns the page for a request path such as /posts/my-post.
+// Returns ErrNotFound if the path isn't a page.
+func (p *Pages) Render(ctx context.Context, reqPath string) (Page, error) {
+	route := strings.Trim(reqPath, "/")
+
+	version, err := p.store.Version(ctx)
+	if err != nil {
+		return Page{}, fmt.Errorf("getting content version: %w", err)
+	}
+
+	p.mu.Lock()
+	if version.ETag != p.version.ETag {
+		clear(p.cache)
+		p.version = version
+	}
+	page, ok := p.cache[route]
+	p.mu.Unlock()
+	if ok {
+		return page, nil
+	}
+
+	data, err := p.gen.page(ctx, route)
+	if err != nil {
+		return Page{}, err
+	}
+	html := []byte(views.RenderToString(views.SSGPage(data)))
+	sum := sha256.Sum256(html)
+	page = Page{
+		HTML:    html,
+		Version: content.Version{ETag: hex.EncodeToString(sum[:16]), Modified: version.Modified},
+	}
+
+	// Only cache pages rendered from the current content; a reload while
+	// rendering leaves this one to the next request
+	p.mu.Lock()
+	if p.version.ETag == version.ETag {
+		p.cache[route] = page
+	}
+	p.mu.Unlock()
+
+	return page, nil
+}
+
+// page returns the data for a route, mirroring the files Generate writes.
+func (g *Generator) page(ctx context.Context, route string) (views.SSGPageData, error) {
+	section, name, nested := strings.Cut(route, "/")
+	if section == "archive" && nested {
+		year, month, ok := parseArchivePath(name)
+		if !ok {
+			return views.SSGPageData{}, ErrNotFound
+		}
+		return g.archivePeriodPage(ctx, year, month)
+	}
+	if strings.Contains(name, "/") || (nested && name == "") {
+		return views.SSGPageData{}, ErrNotFound
+	}
+
+	switch section {
+	case "":
+		return g.splashPage(), nil
+
+	case "posts":
+		if !nested {
+			return g.homePage(ctx)
+		}
+		post, err := g.store.GetPost(ctx, name)
+		if errors.Is(err, content.ErrPostNotFound) {
+			return views.SSGPageData{}, ErrNotFound
+		} else if err != nil {
+			return views.SSGPageData{}, err
+		}
+		return g.postPage(ctx, post)
+
+	case "tags":
+		if !nested {
+			tags, err := g.store.GetTags(ctx)
+			if err != nil {
+				return views.SSGPageData{}, fmt.Errorf("listing tags: %w", err)
+			}
+			return g.tagsPage(tags), nil
+		}
+		return g.tagPage(ctx, name)
+
+	case "series":
+		if !nested {
+			series, err := g.store.GetSeries(ctx)
+			if err != nil {
+				return views.SSGPageData{}, fmt.Errorf("listing series: %w", err)
+			}
+			return g.seriesPage(series), nil
+		}
+		if _, err := g.store.GetSeriesByName(ctx, name); errors.Is(err, content.ErrSeriesNotFound) {
+			return views.SSGPageData{}, ErrNotFound
+		}
+		return g.seriesDetailPage(ctx, name)
+
+	case "authors":
+		if !nested {
+			authors, err := g.store.GetAuthors(ctx)
+			if err != nil {
+				return views.SSGPageData{}, fmt.Errorf("listing authors: %w", err)
+			}
+			return g.authorsPage(authors), nil
+		}
+		author, err := g.store.GetAuthor(ctx, name)
+		if errors.Is(err, content.ErrAuthorNotFound) {
+			return views.SSGPageData{}, ErrNotFound
+		} else if err != nil {
+			return views.SSGPageData{}, err
+		}
+		return g.authorPage(ctx, author)
+
+	case "archive":
+		archive, err := g.store.GetArchive(ctx)
+		if err != nil {
+			return views.SSGPageData{}, fmt.Errorf("listing archive: %w", err)
+		}
+		return g.archivePage(archive), nil
+
+	case "about":
+		if !nested {
+			return g.aboutPage(), nil
+		}
+	}
+
+	return views.SSGPageData{}, ErrNotFound
+}
+
+// parseArchivePath parses the year, and month if present, from the part of
+// an archive path after /archive/, such as 2024 or 2024/03. Only the form
+// views.ArchiveURL writes is accepted, so each page has one path.
+func parseArchivePath(path string) (year int, month time.Month, ok bool) {
+	yearStr, monthStr, hasMonth := strings.Cut(path, "/")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 1 {
+		return 0, 0, false
+	}
+	if hasMonth {
+		m, err := strconv.Atoi(monthStr)
+		if err != nil || m < 1 || m > 12 {
+			return 0, 0, false
+		}
+		month = time.Month(m)
+	}
+	if views.ArchiveURL(year, month) != "/archive/"+path {
+		return 0, 0, false
+	}
+	return year, month, true
+}
@@ -26,6 +26,22 @@ func ShortcodeRenderers() map[string]renderer.ShortcodeRenderer {
 	}
 }
 
+// ShortcodeSpecs returns the syntax of each shortcode, as documented in
+// SHORTCODES.md.
+func ShortcodeSpecs() map[string]renderer.ShortcodeSpec {
+	return map[string]renderer.ShortcodeSpec{
+		"figure":            {Required: []string{"src", "alt"}},
+		"quote":             {Block: true},
+		"sidenote":          {Block: true, Required: []string{"id"}},
+		"cite":              {}, // text or alias
+		"term":              {Block: true, Required: []string{"word"}},
+		"parallel":          {Block: true, Required: []string{"left", "right"}},
+		"timeline":          {Block: true},
+		"scripture":         {Block: true, Required: []string{"ref"}},
+		"scripture-compare": {Block: true, Required: []string{"ref", "pinned", "alts"}},
+	}
+}
+
 // renderInlineMarkdown converts inline markdown to HTML, stripping the wrapping <p> tags
 func renderInlineMarkdown(content string) string {
 	html, err := inlineRenderer.Convert([]byte(content))
@@ -39,9 +55,10 @@ func renderInlineMarkdown(content string) string {
 	return result
 }
 
-func renderFigure(sc renderer.Shortcode, _ *renderer.RenderContext) string {
+func renderFigure(sc renderer.Shortcode, ctx *renderer.RenderContext) string {
 	var buf bytes.Buffer
-	_ = Figure(sc.Attrs["src"], sc.Attrs["caption"], sc.Attrs["alt"]).Render(context.Background(), &buf)
+	src, img := ctx.Asset(sc.Attrs["src"])
+	_ = Figure(src, sc.Attrs["caption"], sc.Attrs["alt"], img).Render(context.Background(), &buf)
 	return buf.String()
 }
 
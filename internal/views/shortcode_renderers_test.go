@@ -0,0 +1,130 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"therefore/internal/images"
+	"therefore/internal/renderer"
+)
+
+func TestShortcodeRenderers_Nested(t *testing.T) {
+	r := renderer.New(ShortcodeRenderers())
+	r.SetSpecs(ShortcodeSpecs())
+	r.SetStrict(true)
+
+	ctx := &renderer.RenderContext{
+		Citations: map[string]struct {
+			Text string
+			URL  string
+		}{
+			"aristotle": {Text: "Nicomachean Ethics", URL: "https://example.com/ne"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		contains []string
+	}{
+		{
+			name:  "cite inside quote",
+			input: `{{quote author="Aristotle"}}We are what we *repeatedly* do.{{cite alias="aristotle"}}{{/quote}}`,
+			contains: []string{
+				"<em>repeatedly</em>",
+				`data-citation-text="Nicomachean Ethics"`,
+			},
+		},
+		{
+			name: "sidenote inside scripture",
+			input: `{{scripture ref="John 1:1" version="ESV"}}
+1 In the beginning was the Word,{{sidenote id="logos"}}Greek *logos*, 12 senses{{/sidenote}} and the Word was with God.
+{{/scripture}}`,
+			contains: []string{
+				`<sup class="verse-num">1</sup>`,
+				`data-sidenote-content="Greek &lt;em&gt;logos&lt;/em&gt;, 12 senses"`,
+			},
+		},
+		{
+			name:     "quote inside quote",
+			input:    `{{quote author="Outer"}}Said: {{quote author="Inner"}}Nested{{/quote}}{{/quote}}`,
+			contains: []string{"Outer", "Inner", "Nested"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := r.Render(tt.input, ctx)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(result, want) {
+					t.Errorf("Render() result missing %q\nGot: %s", want, result)
+				}
+			}
+			if strings.Contains(result, "shortcode:") || strings.Contains(result, "{{") {
+				t.Errorf("Render() left shortcode syntax in output\nGot: %s", result)
+			}
+		})
+	}
+}
+
+func TestShortcodeRenderers_FigureBundleImage(t *testing.T) {
+	r := renderer.New(ShortcodeRenderers())
+
+	ctx := &renderer.RenderContext{
+		BundleURL: "/posts/essay/",
+		Images: map[string]*images.Image{
+			"/posts/essay/photo.jpg": {
+				Width:    2000,
+				Height:   1000,
+				Variants: []images.Variant{{Width: 480, Height: 240, Format: images.FormatJPEG}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:  "relative bundle image",
+			input: `{{figure src="photo.jpg" alt="A photo"}}`,
+			contains: []string{
+				`src="/posts/essay/photo.jpg"`,
+				`srcset="/posts/essay/_img/480/photo.jpg 480w, /posts/essay/photo.jpg 2000w"`,
+				`sizes="` + images.Sizes + `"`,
+				`width="2000"`,
+				`height="1000"`,
+			},
+		},
+		{
+			name:     "external image",
+			input:    `{{figure src="https://example.com/a.jpg" alt="Remote"}}`,
+			contains: []string{`src="https://example.com/a.jpg"`},
+			excludes: []string{"srcset", "width="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := r.Render(tt.input, ctx)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(html, want) {
+					t.Errorf("output missing %q\n%s", want, html)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(html, unwanted) {
+					t.Errorf("output contains %q\n%s", unwanted, html)
+				}
+			}
+		})
+	}
+}